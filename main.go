@@ -6,6 +6,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"net"
@@ -23,9 +24,14 @@ import (
 	"github.com/AccelByte/accelbyte-go-sdk/services-api/pkg/service/iam"
 	sdkAuth "github.com/AccelByte/accelbyte-go-sdk/services-api/pkg/utils/auth"
 	"github.com/AccelByte/extend-core-matchmaker/pkg/config"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/envelope"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/matchmaker"
 	"github.com/AccelByte/extend-core-matchmaker/pkg/matchmaker/defaultmatchmaker"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/metrics"
 	matchfunctiongrpc "github.com/AccelByte/extend-core-matchmaker/pkg/pb"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/redact"
 	"github.com/AccelByte/extend-core-matchmaker/pkg/server"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/tickhistory"
 	"github.com/caarlos0/env"
 	promgrpc "github.com/grpc-ecosystem/go-grpc-middleware/providers/prometheus"
 	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/logging"
@@ -36,6 +42,7 @@ import (
 	"go.opentelemetry.io/otel/trace"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/health"
 	"google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
@@ -53,7 +60,7 @@ const (
 	id              = int64(1)
 	metricsEndpoint = "/metrics"
 	metricsPort     = 8080
-	grpcPort        = 6565
+	shutdownTimeout = 10 * time.Second
 )
 
 var (
@@ -72,15 +79,39 @@ func main() {
 
 	logrus.Infof("starting app server.")
 
+	cfg := &config.Config{}
+	if err := env.Parse(cfg); err != nil {
+		logrus.Fatal("unable to parse environment variables: ", err)
+	}
+
+	// Configure the logging once, the format and redaction apply to the scope logs and the gRPC interceptor logs
 	logrusLevel, err := logrus.ParseLevel(logLevelStr)
 	if err != nil {
 		logrusLevel = logrus.InfoLevel
 	}
+	if err = redact.SetMode(cfg.LogRedaction); err != nil {
+		logrus.Fatal("unable to configure log redaction: ", err)
+	}
+	redact.SetKey(cfg.LogRedactionKey)
+	if err = envelope.SetLogSampling(cfg.GetLogSampling()); err != nil {
+		logrus.Fatal("unable to configure log sampling: ", err)
+	}
+	if err = common.ConfigureLogger(logrus.StandardLogger(), cfg.LogFormat, logrusLevel); err != nil {
+		logrus.Fatal("unable to configure logger: ", err)
+	}
 	logrusLogger := logrus.New()
-	logrusLogger.SetLevel(logrusLevel)
+	if err = common.ConfigureLogger(logrusLogger, cfg.LogFormat, logrusLevel); err != nil {
+		logrus.Fatal("unable to configure logger: ", err)
+	}
+
+	// Payloads hold full tickets and matches, only log them when asked to
+	logEvents := []logging.LoggableEvent{logging.StartCall, logging.FinishCall}
+	if cfg.LogPayload {
+		logEvents = append(logEvents, logging.PayloadReceived, logging.PayloadSent)
+	}
 
 	loggingOptions := []logging.Option{
-		logging.WithLogOnEvents(logging.StartCall, logging.FinishCall, logging.PayloadReceived, logging.PayloadSent),
+		logging.WithLogOnEvents(logEvents...),
 		logging.WithFieldsFromContext(func(ctx context.Context) logging.Fields {
 			if span := trace.SpanContextFromContext(ctx); span.IsSampled() {
 				return logging.Fields{"traceID", span.TraceID().String()}
@@ -102,6 +133,23 @@ func main() {
 		logging.StreamServerInterceptor(common.InterceptorLogger(logrusLogger), loggingOptions...),
 	}
 
+	// Optional TLS on the gRPC listener, the certificates are reloaded when the files change
+	serverOptions := []grpc.ServerOption{grpc.StatsHandler(otelgrpc.NewServerHandler())}
+	mutualTLS := false
+	if cfg.TLSCertFile != "" {
+		certReloader, err := common.NewCertReloader(cfg.TLSCertFile, cfg.TLSKeyFile, cfg.TLSClientCAFile)
+		if err != nil {
+			logrus.Fatalf("failed to load TLS certificates: %v", err)
+		}
+		if cfg.TLSReloadIntervalSecond > 0 {
+			go certReloader.Watch(ctx, time.Duration(cfg.TLSReloadIntervalSecond)*time.Second)
+		}
+
+		serverOptions = append(serverOptions, grpc.Creds(credentials.NewTLS(certReloader.TLSConfig())))
+		mutualTLS = certReloader.MutualTLS()
+		logrus.Infof("gRPC TLS enabled, mutual TLS: %t", mutualTLS)
+	}
+
 	// Preparing the IAM authorization
 	var tokenRepo repository.TokenRepository = sdkAuth.DefaultTokenRepositoryImpl()
 	var configRepo repository.ConfigRepository = sdkAuth.DefaultConfigRepositoryImpl()
@@ -114,35 +162,64 @@ func main() {
 		ConfigRepository:       configRepo,
 	}
 
-	if strings.ToLower(common.GetEnv("PLUGIN_GRPC_SERVER_AUTH_ENABLED", "true")) == "true" {
+	authEnabled := strings.ToLower(common.GetEnv("PLUGIN_GRPC_SERVER_AUTH_ENABLED", "true")) == "true"
+	if authEnabled && mutualTLS && cfg.MTLSDisableIAMAuth {
+		authEnabled = false
+		logrus.Infof("IAM auth interceptors disabled, clients are verified by mutual TLS")
+	}
+	if authEnabled {
 		refreshInterval := common.GetEnvInt("REFRESH_INTERVAL", 600)
 		common.Validator = common.NewTokenValidator(oauthService, time.Duration(refreshInterval)*time.Second, true)
 		common.Validator.Initialize(ctx)
 
-		unaryServerInterceptors = append(unaryServerInterceptors, common.UnaryAuthServerIntercept)
-		streamServerInterceptors = append(streamServerInterceptors, common.StreamAuthServerIntercept)
-		logrus.Infof("added auth interceptors")
+		// Methods mapped to a permission also require it, the other methods only require a token in the namespace
+		permissions, err := common.ParseMethodPermissions(common.GetEnv("PLUGIN_GRPC_SERVER_METHOD_PERMISSIONS", ""))
+		if err != nil {
+			logrus.Fatalf("failed to parse method permissions: %v", err)
+		}
+		authorizer := common.NewAuthorizer(common.Validator, common.GetEnv("AB_NAMESPACE", ""), permissions)
+
+		unaryServerInterceptors = append(unaryServerInterceptors, authorizer.UnaryServerInterceptor)
+		streamServerInterceptors = append(streamServerInterceptors, authorizer.StreamServerInterceptor)
+		logrus.Infof("added auth interceptors, %d methods require a permission", len(permissions))
+	}
+
+	// Initialize the tracer provider before serving, so the first requests are traced
+	logrus.Infof("starting init provider.")
+
+	// Save Tracer Provider
+	tracerProvider, err := common.NewTracerProvider(serviceName, environment, id)
+	if err != nil {
+		logrus.Fatalf("failed to create tracer provider: %v", err)
+
+		return
 	}
 
+	// Register our TracerProvider as the global so any imported
+	// instrumentation in the future will default to using it.
+	otel.SetTracerProvider(tracerProvider)
+	// Register the B3 propagator globally.
+	p := b3.New(b3.WithInjectEncoding(b3.B3MultipleHeader))
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		p,
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
 	// Create gRPC Server
-	grpcServer := grpc.NewServer(
-		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+	serverOptions = append(serverOptions,
 		grpc.ChainUnaryInterceptor(unaryServerInterceptors...),
 		grpc.ChainStreamInterceptor(streamServerInterceptors...),
 	)
-
-	cfg := &config.Config{}
-	err = env.Parse(cfg)
-	if err != nil {
-		logrus.Fatal("unable to parse environment variables: ", err)
-	}
+	grpcServer := grpc.NewServer(serverOptions...)
 
 	// Enable gRPC Reflection
 	reflection.Register(grpcServer)
 	logrus.Infof("gRPC reflection enabled")
 
 	// Enable gRPC Health Check
-	grpc_health_v1.RegisterHealthServer(grpcServer, health.NewServer())
+	healthServer := health.NewServer()
+	grpc_health_v1.RegisterHealthServer(grpcServer, healthServer)
 
 	// Add go runtime metrics and process collectors.
 	srvMetrics.InitializeMetrics(grpcServer)
@@ -152,21 +229,60 @@ func main() {
 		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
 		srvMetrics,
 	)
+	if err = metrics.Register(promRegistry); err != nil {
+		logrus.Fatalf("failed to register matchmaking metrics: %v", err)
+	}
+
+	rulesCache := server.NewRulesCache(cfg.RulesCacheSize)
+	if rulesCache != nil {
+		promRegistry.MustRegister(rulesCache)
+	}
 
-	matchMaker := defaultmatchmaker.New(cfg)
+	// Register the match logics, the ruleset "match_logic" field or MATCH_LOGIC_POOLS selects one per request
+	if err = defaultmatchmaker.ValidatePartitionPolicy(cfg.PartitionPolicy); err != nil {
+		logrus.Fatalf("invalid PARTITION_POLICY: %v", err)
+	}
+	if err = defaultmatchmaker.ValidatePartyFinderStrategy(cfg.PartyFinderStrategy); err != nil {
+		logrus.Fatalf("invalid PARTY_FINDER_STRATEGY: %v", err)
+	}
+	registry := matchmaker.NewRegistry(cfg)
+	if err = registry.Register(matchmaker.DefaultMatchLogicName, defaultmatchmaker.New); err != nil {
+		logrus.Fatalf("failed to register match logic: %v", err)
+	}
+	if err = registry.Validate(); err != nil {
+		logrus.Fatalf("invalid DEFAULT_MATCH_LOGIC or MATCH_LOGIC_POOLS: %v", err)
+	}
+	logrus.Infof("registered match logics: %v", registry.Names())
+
+	// Keep the recent ticks per pool for the debug endpoints when enabled
+	tickHistory := tickhistory.New(cfg.DebugTickHistorySize)
+	if tickHistory != nil {
+		tickHistory.RegisterHandlers(http.DefaultServeMux)
+		logrus.Infof("tick history served at :%d%s", metricsPort, tickhistory.Endpoint)
+	}
+
+	// the server falls back to the registered default match logic, so both share one state
+	matchMaker, _ := registry.Get(matchmaker.DefaultMatchLogicName)
 	matchfunctiongrpc.RegisterMatchFunctionServer(grpcServer, &server.MatchFunctionServer{
 		UnimplementedMatchFunctionServer: matchfunctiongrpc.UnimplementedMatchFunctionServer{},
 		MM:                               matchMaker,
+		Registry:                         registry,
+		RulesCache:                       rulesCache,
+		TickHistory:                      tickHistory,
+		Limits:                           server.LimitsFromConfig(cfg),
 	})
 
+	http.Handle(metricsEndpoint, promhttp.HandlerFor(promRegistry, promhttp.HandlerOpts{}))
+	metricsServer := &http.Server{Addr: fmt.Sprintf(":%d", metricsPort)}
 	go func() {
-		http.Handle(metricsEndpoint, promhttp.HandlerFor(promRegistry, promhttp.HandlerOpts{}))
-		logrus.Fatal(http.ListenAndServe(fmt.Sprintf(":%d", metricsPort), nil))
+		if err := metricsServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logrus.Fatal(err)
+		}
 	}()
 	logrus.Printf("prometheus metrics served at :8080/metrics")
 
 	logrus.Infof("listening to grpc port.")
-	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", grpcPort))
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.GRPCPort))
 	if err != nil {
 		logrus.Fatalf("failed to listen: %v", err)
 
@@ -184,38 +300,42 @@ func main() {
 		}
 	}()
 
-	logrus.Infof("starting init provider.")
-
-	// Save Tracer Provider
-	tracerProvider, err := common.NewTracerProvider(serviceName, environment, id)
-	if err != nil {
-		logrus.Fatalf("failed to create tracer provider: %v", err)
-
-		return
-	}
-
-	// Register our TracerProvider as the global so any imported
-	// instrumentation in the future will default to using it.
-	otel.SetTracerProvider(tracerProvider)
-	// Register the B3 propagator globally.
-	p := b3.New(b3.WithInjectEncoding(b3.B3MultipleHeader))
-	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
-		p,
-		propagation.TraceContext{},
-		propagation.Baggage{},
-	))
-
-	// Cleanly shutdown and flush telemetry when the application exits.
-	defer func(ctx context.Context) {
-		if err := tracerProvider.Shutdown(ctx); err != nil {
-			logrus.Fatal(err)
-		}
-	}(ctx)
-
 	flag.Parse()
 
 	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
 	defer stop()
 	<-ctx.Done()
 	logrus.Infof("signal received")
+
+	// Report not serving and wait for the load balancers to stop routing new streams here,
+	// then stop receiving new requests, let in-flight matchmaking finish within the grace period
+	healthServer.Shutdown()
+	if drainDelay := time.Duration(cfg.ShutdownDrainDelaySecond) * time.Second; drainDelay > 0 {
+		logrus.Infof("health check reports not serving, waiting %s before stopping", drainDelay)
+		time.Sleep(drainDelay)
+	}
+	gracePeriod := time.Duration(cfg.ShutdownGracePeriodSecond) * time.Second
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		logrus.Infof("gRPC server stopped gracefully")
+	case <-time.After(gracePeriod):
+		logrus.Warnf("gRPC server did not stop within %s, forcing stop", gracePeriod)
+		grpcServer.Stop()
+	}
+
+	// Cleanly shutdown and flush telemetry, the signal context is already done so use a new one
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer shutdownCancel()
+	if err := tracerProvider.Shutdown(shutdownCtx); err != nil {
+		logrus.Errorf("failed to shutdown tracer provider: %v", err)
+	}
+	if err := metricsServer.Shutdown(shutdownCtx); err != nil {
+		logrus.Errorf("failed to shutdown metrics server: %v", err)
+	}
+	logrus.Infof("server stopped")
 }
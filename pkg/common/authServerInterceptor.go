@@ -8,13 +8,15 @@ import (
 	"context"
 	"crypto/rsa"
 	"encoding/base64"
-	"os"
+	"encoding/json"
+	"fmt"
 	"strings"
 	"time"
 
 	"github.com/AccelByte/accelbyte-go-sdk/iam-sdk/pkg/iamclientmodels"
 	"github.com/AccelByte/accelbyte-go-sdk/services-api/pkg/service/iam"
 	"github.com/AccelByte/accelbyte-go-sdk/services-api/pkg/utils/auth/validator"
+	"github.com/sirupsen/logrus"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -24,11 +26,54 @@ import (
 
 var Validator validator.AuthTokenValidator
 
-func UnaryAuthServerIntercept(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
-	if !skipCheckAuthorizationMetadata(info.FullMethod) {
-		err := checkAuthorizationMetadata(ctx)
+// AnyMethod is the Authorizer.Permissions key of the permission required by methods without their own entry.
+const AnyMethod = "*"
+
+// Authorizer checks the authorization token of every gRPC call against the namespace,
+// and against the permission required by the called method.
+// The permission resource can contain the {namespace} placeholder, the validator replaces it with the namespace.
+// An invalid token is Unauthenticated and a missing permission is PermissionDenied naming the method and the permission,
+// the validator errors are only logged.
+type Authorizer struct {
+	Validator   validator.AuthTokenValidator
+	Namespace   string
+	Permissions map[string]*iam.Permission
+	Logger      logrus.FieldLogger // the standard logger when nil
+}
+
+// NewAuthorizer creates an authorizer, permissions can be nil to only check the namespace.
+func NewAuthorizer(validator validator.AuthTokenValidator, namespace string, permissions map[string]*iam.Permission) *Authorizer {
+	return &Authorizer{
+		Validator:   validator,
+		Namespace:   namespace,
+		Permissions: permissions,
+	}
+}
+
+// ParseMethodPermissions parses the gRPC full method to permission mapping json,
+// e.g. {"/matchfunction.MatchFunction/MakeMatches": {"resource": "NAMESPACE:{namespace}:MMV2GRPCSERVICE", "action": 2}}.
+func ParseMethodPermissions(permissionsJSON string) (map[string]*iam.Permission, error) {
+	if strings.TrimSpace(permissionsJSON) == "" {
+		return nil, nil
+	}
+
+	var permissions map[string]*iam.Permission
+	if err := json.Unmarshal([]byte(permissionsJSON), &permissions); err != nil {
+		return nil, fmt.Errorf("invalid method permissions: %w", err)
+	}
+	for method, permission := range permissions {
+		if permission == nil || permission.Resource == "" || permission.Action <= 0 {
+			return nil, fmt.Errorf("invalid method permissions: %s requires a resource and an action", method)
+		}
+	}
+
+	return permissions, nil
+}
 
-		if err != nil {
+// UnaryServerInterceptor checks the authorization of unary calls.
+func (a *Authorizer) UnaryServerInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if !skipCheckAuthorizationMetadata(info.FullMethod) {
+		if err := a.checkAuthorization(ctx, info.FullMethod); err != nil {
 			return nil, err
 		}
 	}
@@ -36,11 +81,10 @@ func UnaryAuthServerIntercept(ctx context.Context, req interface{}, info *grpc.U
 	return handler(ctx, req)
 }
 
-func StreamAuthServerIntercept(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+// StreamServerInterceptor checks the authorization of stream calls.
+func (a *Authorizer) StreamServerInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
 	if !skipCheckAuthorizationMetadata(info.FullMethod) {
-		err := checkAuthorizationMetadata(ss.Context())
-
-		if err != nil {
+		if err := a.checkAuthorization(ss.Context(), info.FullMethod); err != nil {
 			return err
 		}
 	}
@@ -48,6 +92,21 @@ func StreamAuthServerIntercept(srv interface{}, ss grpc.ServerStream, info *grpc
 	return handler(srv, ss)
 }
 
+func (a *Authorizer) logger() logrus.FieldLogger {
+	if a.Logger == nil {
+		return logrus.StandardLogger()
+	}
+	return a.Logger
+}
+
+// permission returns the permission required by the method, or nil if only the namespace is checked
+func (a *Authorizer) permission(fullMethod string) *iam.Permission {
+	if permission, ok := a.Permissions[fullMethod]; ok {
+		return permission
+	}
+	return a.Permissions[AnyMethod]
+}
+
 func skipCheckAuthorizationMetadata(fullMethod string) bool {
 	if strings.HasPrefix(fullMethod, "/grpc.reflection.v1alpha.ServerReflection/") {
 		return true
@@ -60,8 +119,8 @@ func skipCheckAuthorizationMetadata(fullMethod string) bool {
 	return false
 }
 
-func checkAuthorizationMetadata(ctx context.Context) error {
-	if Validator == nil {
+func (a *Authorizer) checkAuthorization(ctx context.Context, fullMethod string) error {
+	if a.Validator == nil {
 		return status.Error(codes.Internal, "authorization token validator is not set")
 	}
 
@@ -81,12 +140,25 @@ func checkAuthorizationMetadata(ctx context.Context) error {
 
 	authorization := meta["authorization"][0]
 	token := strings.TrimPrefix(authorization, "Bearer ")
-	namespace := os.Getenv("AB_NAMESPACE")
+	namespace := a.Namespace
+	permission := a.permission(fullMethod)
+
+	// The token and its namespace are validated first, so an invalid token is not reported as a missing permission
+	if err := a.Validator.Validate(token, nil, &namespace, nil); err != nil {
+		a.logger().WithError(err).WithField("method", fullMethod).WithField("namespace", namespace).Warn("invalid token")
+
+		return status.Error(codes.Unauthenticated, "invalid or expired token")
+	}
+
+	if permission == nil {
+		return nil
+	}
 
-	err := Validator.Validate(token, nil, &namespace, nil)
+	if err := a.Validator.Validate(token, permission, &namespace, nil); err != nil {
+		a.logger().WithError(err).WithField("method", fullMethod).WithField("namespace", namespace).
+			WithField("resource", permission.Resource).WithField("action", permission.Action).Warn("permission denied")
 
-	if err != nil {
-		return status.Error(codes.PermissionDenied, err.Error())
+		return status.Errorf(codes.PermissionDenied, "%s requires permission %s with action %d", fullMethod, permission.Resource, permission.Action)
 	}
 
 	return nil
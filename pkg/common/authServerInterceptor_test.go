@@ -0,0 +1,186 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package common
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/AccelByte/accelbyte-go-sdk/services-api/pkg/service/iam"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	makeMatchesMethod   = "/matchfunction.MatchFunction/MakeMatches"
+	getStatCodesMethod  = "/matchfunction.MatchFunction/GetStatCodes"
+	matchmakingResource = "NAMESPACE:{namespace}:MMV2GRPCSERVICE"
+)
+
+// stubValidator stands in for IAM, a token is valid for its namespace and grants its permissions
+type stubValidator struct {
+	namespace   string
+	permissions map[string]int
+}
+
+func (v *stubValidator) Initialize(...context.Context) error {
+	return nil
+}
+
+func (v *stubValidator) Validate(token string, permission *iam.Permission, namespace *string, _ *string) error {
+	if token != "valid-token" {
+		return errors.New("token is invalid")
+	}
+	if namespace == nil || *namespace != v.namespace {
+		return errors.New("token is not valid for the namespace")
+	}
+	if permission == nil {
+		return nil
+	}
+
+	resource := strings.ReplaceAll(permission.Resource, "{namespace}", *namespace)
+	if v.permissions[resource]&permission.Action != permission.Action {
+		return errors.New("insufficient permission")
+	}
+	return nil
+}
+
+type stubServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s stubServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func withToken(token string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+}
+
+func TestParseMethodPermissions(t *testing.T) {
+	t.Parallel()
+
+	permissions, err := ParseMethodPermissions("")
+	require.NoError(t, err)
+	assert.Nil(t, permissions)
+
+	permissions, err = ParseMethodPermissions(`{"` + makeMatchesMethod + `": {"resource": "` + matchmakingResource + `", "action": 2}}`)
+	require.NoError(t, err)
+	assert.Equal(t, &iam.Permission{Resource: matchmakingResource, Action: 2}, permissions[makeMatchesMethod])
+
+	_, err = ParseMethodPermissions(`{"` + makeMatchesMethod + `": {"resource": "` + matchmakingResource + `"}}`)
+	assert.Error(t, err)
+
+	_, err = ParseMethodPermissions(`not json`)
+	assert.Error(t, err)
+}
+
+func TestAuthorizer(t *testing.T) {
+	t.Parallel()
+
+	validator := &stubValidator{
+		namespace:   "ns",
+		permissions: map[string]int{"NAMESPACE:ns:MMV2GRPCSERVICE": 2},
+	}
+	authorizer := NewAuthorizer(validator, "ns", map[string]*iam.Permission{
+		makeMatchesMethod:  {Resource: matchmakingResource, Action: 2},
+		getStatCodesMethod: {Resource: matchmakingResource, Action: 4},
+	})
+
+	testCases := []struct {
+		name     string
+		ctx      context.Context
+		method   string
+		wantCode codes.Code
+	}{
+		{name: "granted permission", ctx: withToken("valid-token"), method: makeMatchesMethod, wantCode: codes.OK},
+		{name: "missing permission action", ctx: withToken("valid-token"), method: getStatCodesMethod, wantCode: codes.PermissionDenied},
+		{name: "unmapped method only checks namespace", ctx: withToken("valid-token"), method: "/matchfunction.MatchFunction/ValidateTicket", wantCode: codes.OK},
+		{name: "invalid token", ctx: withToken("other-token"), method: makeMatchesMethod, wantCode: codes.Unauthenticated},
+		{name: "invalid token on unmapped method", ctx: withToken("other-token"), method: "/matchfunction.MatchFunction/ValidateTicket", wantCode: codes.Unauthenticated},
+		{name: "missing metadata", ctx: context.Background(), method: makeMatchesMethod, wantCode: codes.Unauthenticated},
+		{name: "health check is skipped", ctx: context.Background(), method: "/grpc.health.v1.Health/Check", wantCode: codes.OK},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			unaryCalled := false
+			_, err := authorizer.UnaryServerInterceptor(testCase.ctx, nil, &grpc.UnaryServerInfo{FullMethod: testCase.method},
+				func(context.Context, interface{}) (interface{}, error) {
+					unaryCalled = true
+					return nil, nil
+				})
+			assert.Equal(t, testCase.wantCode, status.Code(err))
+			assert.Equal(t, testCase.wantCode == codes.OK, unaryCalled)
+
+			streamCalled := false
+			err = authorizer.StreamServerInterceptor(nil, stubServerStream{ctx: testCase.ctx}, &grpc.StreamServerInfo{FullMethod: testCase.method},
+				func(interface{}, grpc.ServerStream) error {
+					streamCalled = true
+					return nil
+				})
+			assert.Equal(t, testCase.wantCode, status.Code(err))
+			assert.Equal(t, testCase.wantCode == codes.OK, streamCalled)
+		})
+	}
+
+	t.Run("denial names the method and the permission", func(t *testing.T) {
+		t.Parallel()
+
+		var out bytes.Buffer
+		logger := logrus.New()
+		logger.SetOutput(&out)
+		loggingAuthorizer := NewAuthorizer(validator, "ns", authorizer.Permissions)
+		loggingAuthorizer.Logger = logger
+
+		_, err := loggingAuthorizer.UnaryServerInterceptor(withToken("valid-token"), nil, &grpc.UnaryServerInfo{FullMethod: getStatCodesMethod},
+			func(context.Context, interface{}) (interface{}, error) { return nil, nil })
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+		assert.Equal(t, getStatCodesMethod+" requires permission "+matchmakingResource+" with action 4", status.Convert(err).Message())
+		assert.NotContains(t, status.Convert(err).Message(), "insufficient permission")
+		assert.Contains(t, out.String(), "insufficient permission")
+		assert.Contains(t, out.String(), "namespace=ns")
+	})
+
+	t.Run("invalid token details are only logged", func(t *testing.T) {
+		t.Parallel()
+
+		var out bytes.Buffer
+		logger := logrus.New()
+		logger.SetOutput(&out)
+		loggingAuthorizer := NewAuthorizer(validator, "ns", authorizer.Permissions)
+		loggingAuthorizer.Logger = logger
+
+		_, err := loggingAuthorizer.UnaryServerInterceptor(withToken("other-token"), nil, &grpc.UnaryServerInfo{FullMethod: makeMatchesMethod},
+			func(context.Context, interface{}) (interface{}, error) { return nil, nil })
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		assert.Equal(t, "invalid or expired token", status.Convert(err).Message())
+		assert.Contains(t, out.String(), "token is invalid")
+		assert.Contains(t, out.String(), makeMatchesMethod)
+	})
+
+	t.Run("any method permission", func(t *testing.T) {
+		t.Parallel()
+
+		anyAuthorizer := NewAuthorizer(validator, "ns", map[string]*iam.Permission{
+			AnyMethod: {Resource: matchmakingResource, Action: 4},
+		})
+		_, err := anyAuthorizer.UnaryServerInterceptor(withToken("valid-token"), nil, &grpc.UnaryServerInfo{FullMethod: makeMatchesMethod},
+			func(context.Context, interface{}) (interface{}, error) { return nil, nil })
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+}
@@ -6,12 +6,95 @@ package common
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strings"
 
+	"github.com/AccelByte/extend-core-matchmaker/pkg/redact"
 	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/logging"
 	"github.com/sirupsen/logrus"
 )
 
+// Log formats selectable with LOG_FORMAT.
+const (
+	LogFormatJSON = "json" // default
+	LogFormatText = "text"
+)
+
+// ConfigureLogger sets the format and level of the logger and adds the redaction hook, call it once on startup.
+func ConfigureLogger(logger *logrus.Logger, format string, level logrus.Level) error {
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case LogFormatJSON, "":
+		logger.SetFormatter(&logrus.JSONFormatter{})
+	case LogFormatText:
+		logger.SetFormatter(&logrus.TextFormatter{FullTimestamp: true})
+	default:
+		return fmt.Errorf("unsupported log format %q", format)
+	}
+	logger.SetLevel(level)
+	logger.AddHook(redactionHook{})
+
+	return nil
+}
+
+// redactionHook redacts the user ID and blocked player fields of every log entry
+type redactionHook struct{}
+
+func (redactionHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (redactionHook) Fire(entry *logrus.Entry) error {
+	if redact.Mode() == redact.ModeNone {
+		return nil
+	}
+	for key, value := range entry.Data {
+		if redact.IsField(key) {
+			entry.Data[key] = redact.Value(value)
+		}
+	}
+	return nil
+}
+
+// jsonLog is marshalled to json only when the log entry is written
+type jsonLog struct {
+	data interface{}
+}
+
+// LogJSON returns the data as a lazily marshalled, redacted json value for the logs.
+// Unlike LogJSONFormatter nothing is marshalled when the entry is not logged.
+func LogJSON(data interface{}) fmt.Stringer {
+	return jsonLog{data: data}
+}
+
+// MarshalJSON logs the value as a json string field with the json formatter.
+func (j jsonLog) MarshalJSON() ([]byte, error) {
+	return json.Marshal(j.String())
+}
+
+func (j jsonLog) String() string {
+	response, err := json.Marshal(j.data)
+	if err != nil {
+		logrus.Errorf("failed to marshal json.")
+
+		return ""
+	}
+
+	if redact.Mode() == redact.ModeNone {
+		return string(response)
+	}
+
+	var decoded interface{}
+	if err = json.Unmarshal(response, &decoded); err != nil {
+		return string(response)
+	}
+	redacted, err := json.Marshal(redact.JSON(decoded))
+	if err != nil {
+		return string(response)
+	}
+	return string(redacted)
+}
+
 // InterceptorLogger adapts logrus logger to interceptor logger.
 // This code is referenced from https://github.com/grpc-ecosystem/go-grpc-middleware/
 func InterceptorLogger(logger logrus.FieldLogger) logging.Logger {
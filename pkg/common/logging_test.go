@@ -0,0 +1,101 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package common
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/AccelByte/extend-core-matchmaker/pkg/redact"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type logTestTicket struct {
+	TicketID  string                 `json:"ticket_id"`
+	Players   []logTestPlayer        `json:"players"`
+	Attribute map[string]interface{} `json:"attributes"`
+}
+
+type logTestPlayer struct {
+	PlayerID string `json:"player_id"`
+}
+
+func newLogTestTicket() logTestTicket {
+	return logTestTicket{
+		TicketID: "ticket-1",
+		Players:  []logTestPlayer{{PlayerID: "user-1"}},
+		Attribute: map[string]interface{}{
+			"blocked_players": []interface{}{"user-2", "user-3"},
+			"mmr":             10,
+		},
+	}
+}
+
+// the log redaction is global, so these tests are not parallel
+func TestLogJSON_Redaction(t *testing.T) {
+	t.Cleanup(func() { require.NoError(t, redact.SetMode(redact.ModeNone)) })
+
+	t.Run("none", func(t *testing.T) {
+		require.NoError(t, redact.SetMode(redact.ModeNone))
+		logged := LogJSONFormatter(newLogTestTicket())
+		assert.Contains(t, logged, "user-1")
+		assert.Contains(t, logged, "user-2")
+	})
+
+	t.Run("hash", func(t *testing.T) {
+		require.NoError(t, redact.SetMode(redact.ModeHash))
+		logged := LogJSON(newLogTestTicket()).String()
+		assert.NotContains(t, logged, "user-1")
+		assert.NotContains(t, logged, "user-2")
+		assert.Contains(t, logged, "ticket-1")
+
+		var decoded logTestTicket
+		require.NoError(t, json.Unmarshal([]byte(logged), &decoded))
+		assert.Equal(t, redact.Value("user-1"), decoded.Players[0].PlayerID)
+		assert.Len(t, decoded.Attribute["blocked_players"], 2)
+		assert.EqualValues(t, 10, decoded.Attribute["mmr"])
+	})
+
+	t.Run("redact", func(t *testing.T) {
+		require.NoError(t, redact.SetMode(redact.ModeRedact))
+		logged := LogJSONFormatter(newLogTestTicket())
+		assert.NotContains(t, logged, "user-1")
+		assert.Contains(t, logged, redact.RedactedValue)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		assert.Error(t, redact.SetMode("scramble"))
+	})
+}
+
+func TestConfigureLogger_RedactsFields(t *testing.T) {
+	t.Cleanup(func() { require.NoError(t, redact.SetMode(redact.ModeNone)) })
+	require.NoError(t, redact.SetMode(redact.ModeHash))
+
+	var out bytes.Buffer
+	logger := logrus.New()
+	logger.SetOutput(&out)
+	require.NoError(t, ConfigureLogger(logger, LogFormatJSON, logrus.InfoLevel))
+
+	logger.WithField("userID", "user-1").
+		WithField("ticket", LogJSON(newLogTestTicket())).
+		Debug("not logged")
+	assert.Empty(t, out.String())
+
+	logger.WithField("userID", "user-1").
+		WithField("ticket", LogJSON(newLogTestTicket())).
+		Info("logged")
+	assert.NotContains(t, out.String(), "user-1")
+	assert.Contains(t, out.String(), "ticket-1")
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &entry))
+	assert.Equal(t, redact.Value("user-1"), entry["userID"])
+
+	assert.Error(t, ConfigureLogger(logrus.New(), "xml", logrus.InfoLevel))
+}
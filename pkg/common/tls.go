@@ -0,0 +1,167 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package common
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// CertReloader serves the server certificate, and the client CA for mutual TLS, from files.
+// The files are polled and reloaded when they change, so certificates can be rotated without a restart.
+type CertReloader struct {
+	certFile     string
+	keyFile      string
+	clientCAFile string
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	modTimes  map[string]time.Time
+}
+
+// NewCertReloader loads the certificate and key, and the client CA when clientCAFile is not empty.
+func NewCertReloader(certFile, keyFile, clientCAFile string) (*CertReloader, error) {
+	if certFile == "" || keyFile == "" {
+		return nil, fmt.Errorf("both the TLS certificate and key files are required")
+	}
+
+	r := &CertReloader{
+		certFile:     certFile,
+		keyFile:      keyFile,
+		clientCAFile: clientCAFile,
+	}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// MutualTLS returns true if client certificates are verified against the client CA.
+func (r *CertReloader) MutualTLS() bool {
+	return r.clientCAFile != ""
+}
+
+// TLSConfig returns the server TLS config, every handshake uses the latest loaded certificates.
+func (r *CertReloader) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			return r.serverConfig(), nil
+		},
+	}
+}
+
+// Watch reloads the files every interval when they changed, until the context is done.
+// A failed reload is logged and the previous certificates are kept.
+func (r *CertReloader) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !r.changed() {
+				continue
+			}
+			if err := r.reload(); err != nil {
+				logrus.WithError(err).Error("failed to reload TLS certificates, keeping the previous ones")
+				continue
+			}
+			logrus.Info("TLS certificates reloaded")
+		}
+	}
+}
+
+// serverConfig returns the config of one handshake. It replaces the config given to credentials.NewTLS,
+// so it must negotiate h2 itself, the gRPC clients that enforce ALPN reject the handshake otherwise.
+func (r *CertReloader) serverConfig() *tls.Config {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	config := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{*r.cert},
+		NextProtos:   []string{"h2"},
+	}
+	if r.clientCAs != nil {
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+		config.ClientCAs = r.clientCAs
+	}
+
+	return config
+}
+
+func (r *CertReloader) files() []string {
+	files := []string{r.certFile, r.keyFile}
+	if r.clientCAFile != "" {
+		files = append(files, r.clientCAFile)
+	}
+	return files
+}
+
+// changed returns true if any of the files was modified since the last reload
+func (r *CertReloader) changed() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, file := range r.files() {
+		info, err := os.Stat(file)
+		if err != nil {
+			continue
+		}
+		if !info.ModTime().Equal(r.modTimes[file]) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *CertReloader) reload() error {
+	modTimes := make(map[string]time.Time)
+	for _, file := range r.files() {
+		info, err := os.Stat(file)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		modTimes[file] = info.ModTime()
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+
+	var clientCAs *x509.CertPool
+	if r.clientCAFile != "" {
+		pem, err := os.ReadFile(r.clientCAFile)
+		if err != nil {
+			return fmt.Errorf("failed to read client CA: %w", err)
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificate found in client CA %s", r.clientCAFile)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cert = &cert
+	r.clientCAs = clientCAs
+	r.modTimes = modTimes
+
+	return nil
+}
@@ -0,0 +1,279 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package common
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/peer"
+)
+
+type testCert struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+	keyPEM  []byte
+}
+
+// newTestCert creates a certificate signed by parent, or a self-signed CA when parent is nil
+func newTestCert(t *testing.T, commonName string, parent *testCert) *testCert {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+
+	signerCert, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+	} else {
+		signerCert, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signerCert, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return &testCert{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+func (c *testCert) keyPair(t *testing.T) tls.Certificate {
+	t.Helper()
+
+	pair, err := tls.X509KeyPair(c.certPEM, c.keyPEM)
+	require.NoError(t, err)
+	return pair
+}
+
+func writeFile(t *testing.T, path string, data []byte, modTime time.Time) {
+	t.Helper()
+
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+}
+
+// handshake connects a TLS client to a TLS server using the config, it returns the client side error
+func handshake(t *testing.T, serverConfig *tls.Config, clientConfig *tls.Config) (*x509.Certificate, error) {
+	t.Helper()
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", serverConfig)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_ = conn.(*tls.Conn).Handshake()
+		// read until the client closes, so the client sees a rejected certificate
+		_, _ = conn.Read(make([]byte, 1))
+	}()
+
+	conn, err := tls.Dial("tcp", listener.Addr().String(), clientConfig)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	// with TLS 1.3 a rejected client certificate is only reported on the first read
+	_ = conn.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+	if _, err = conn.Read(make([]byte, 1)); err != nil {
+		if netErr, ok := err.(net.Error); !ok || !netErr.Timeout() {
+			return nil, err
+		}
+	}
+
+	return conn.ConnectionState().PeerCertificates[0], nil
+}
+
+func TestCertReloader(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "tls.crt")
+	keyFile := filepath.Join(dir, "tls.key")
+	caFile := filepath.Join(dir, "ca.crt")
+
+	ca := newTestCert(t, "test-ca", nil)
+	server := newTestCert(t, "server-1", ca)
+	client := newTestCert(t, "client", ca)
+	modTime := time.Now().Add(-time.Minute)
+	writeFile(t, certFile, server.certPEM, modTime)
+	writeFile(t, keyFile, server.keyPEM, modTime)
+	writeFile(t, caFile, ca.certPEM, modTime)
+
+	reloader, err := NewCertReloader(certFile, keyFile, caFile)
+	require.NoError(t, err)
+	assert.True(t, reloader.MutualTLS())
+	assert.False(t, reloader.changed())
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+
+	t.Run("mutual TLS requires a client certificate", func(t *testing.T) {
+		_, err := handshake(t, reloader.TLSConfig(), &tls.Config{RootCAs: roots, ServerName: "localhost", MinVersion: tls.VersionTLS12})
+		assert.Error(t, err)
+
+		peer, err := handshake(t, reloader.TLSConfig(), &tls.Config{
+			RootCAs:      roots,
+			ServerName:   "localhost",
+			MinVersion:   tls.VersionTLS12,
+			Certificates: []tls.Certificate{client.keyPair(t)},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "server-1", peer.Subject.CommonName)
+	})
+
+	t.Run("reloads changed files", func(t *testing.T) {
+		rotated := newTestCert(t, "server-2", ca)
+		writeFile(t, certFile, rotated.certPEM, modTime.Add(time.Second))
+		writeFile(t, keyFile, rotated.keyPEM, modTime.Add(time.Second))
+		require.True(t, reloader.changed())
+		require.NoError(t, reloader.reload())
+
+		peer, err := handshake(t, reloader.TLSConfig(), &tls.Config{
+			RootCAs:      roots,
+			ServerName:   "localhost",
+			MinVersion:   tls.VersionTLS12,
+			Certificates: []tls.Certificate{client.keyPair(t)},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "server-2", peer.Subject.CommonName)
+	})
+
+	t.Run("keeps the certificate when the reload fails", func(t *testing.T) {
+		writeFile(t, keyFile, []byte("not a key"), modTime.Add(2*time.Second))
+		assert.Error(t, reloader.reload())
+		assert.Equal(t, "server-2", reloader.serverConfig().Certificates[0].Leaf.Subject.CommonName)
+	})
+}
+
+func TestNewCertReloader_Invalid(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewCertReloader("", "", "")
+	assert.Error(t, err)
+
+	_, err = NewCertReloader(filepath.Join(t.TempDir(), "missing.crt"), filepath.Join(t.TempDir(), "missing.key"), "")
+	assert.Error(t, err)
+}
+
+// grpcCall makes a health check call over TLS to a gRPC server using the reloader, it returns the negotiated protocol
+func grpcCall(t *testing.T, reloader *CertReloader, clientConfig *tls.Config) (string, error) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := grpc.NewServer(grpc.Creds(credentials.NewTLS(reloader.TLSConfig())))
+	grpc_health_v1.RegisterHealthServer(server, health.NewServer())
+	go func() { _ = server.Serve(listener) }()
+	defer server.Stop()
+
+	conn, err := grpc.Dial(listener.Addr().String(), grpc.WithTransportCredentials(credentials.NewTLS(clientConfig)))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var p peer.Peer
+	_, err = grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{}, grpc.Peer(&p), grpc.WaitForReady(false))
+	if err != nil {
+		return "", err
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	require.True(t, ok)
+	return tlsInfo.State.NegotiatedProtocol, nil
+}
+
+func TestCertReloader_GRPC(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "tls.crt")
+	keyFile := filepath.Join(dir, "tls.key")
+	caFile := filepath.Join(dir, "ca.crt")
+
+	ca := newTestCert(t, "test-ca", nil)
+	server := newTestCert(t, "server", ca)
+	client := newTestCert(t, "client", ca)
+	modTime := time.Now().Add(-time.Minute)
+	writeFile(t, certFile, server.certPEM, modTime)
+	writeFile(t, keyFile, server.keyPEM, modTime)
+	writeFile(t, caFile, ca.certPEM, modTime)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+
+	t.Run("TLS", func(t *testing.T) {
+		reloader, err := NewCertReloader(certFile, keyFile, "")
+		require.NoError(t, err)
+
+		protocol, err := grpcCall(t, reloader, &tls.Config{RootCAs: roots, ServerName: "localhost", MinVersion: tls.VersionTLS12})
+		require.NoError(t, err)
+		assert.Equal(t, "h2", protocol)
+	})
+
+	t.Run("mutual TLS", func(t *testing.T) {
+		reloader, err := NewCertReloader(certFile, keyFile, caFile)
+		require.NoError(t, err)
+
+		_, err = grpcCall(t, reloader, &tls.Config{RootCAs: roots, ServerName: "localhost", MinVersion: tls.VersionTLS12})
+		assert.Error(t, err)
+
+		protocol, err := grpcCall(t, reloader, &tls.Config{
+			RootCAs:      roots,
+			ServerName:   "localhost",
+			MinVersion:   tls.VersionTLS12,
+			Certificates: []tls.Certificate{client.keyPair(t)},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "h2", protocol)
+	})
+}
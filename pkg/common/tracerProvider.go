@@ -5,9 +5,16 @@
 package common
 
 import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
 	"go.opentelemetry.io/otel/exporters/zipkin"
 	"go.opentelemetry.io/otel/sdk/resource"
 
@@ -15,9 +22,32 @@ import (
 	semanticConventions "go.opentelemetry.io/otel/semconv/v1.12.0"
 )
 
+// Trace exporters selectable with OTEL_TRACES_EXPORTER.
+const (
+	TracesExporterZipkin  = "zipkin" // default
+	TracesExporterOTLP    = "otlp"
+	TracesExporterConsole = "console"
+	TracesExporterStdout  = "stdout"
+	TracesExporterNone    = "none"
+)
+
+// Trace samplers selectable with OTEL_TRACES_SAMPLER, OTEL_TRACES_SAMPLER_ARG holds the ratio of the ratio samplers.
+const (
+	TracesSamplerAlwaysOn                = "always_on"
+	TracesSamplerAlwaysOff               = "always_off"
+	TracesSamplerTraceIDRatio            = "traceidratio"
+	TracesSamplerParentBasedAlwaysOn     = "parentbased_always_on" // default
+	TracesSamplerParentBasedAlwaysOff    = "parentbased_always_off"
+	TracesSamplerParentBasedTraceIDRatio = "parentbased_traceidratio"
+)
+
+// NewTracerProvider creates a tracer provider with the exporter and sampler configured by the OTEL_* environment variables.
+// OTLP exporters also read the standard OTEL_EXPORTER_OTLP_* variables, e.g. OTEL_EXPORTER_OTLP_ENDPOINT.
 func NewTracerProvider(serviceName string, environment string, id int64) (*sdkTrace.TracerProvider, error) {
-	zipkinEndpoint := GetEnv("OTEL_EXPORTER_ZIPKIN_ENDPOINT", "http://localhost:9411/api/v2/spans")
-	exporter, err := zipkin.New(zipkinEndpoint)
+	sampler, err := newTraceSampler(
+		GetEnv("OTEL_TRACES_SAMPLER", TracesSamplerParentBasedAlwaysOn),
+		GetEnv("OTEL_TRACES_SAMPLER_ARG", ""),
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -29,9 +59,72 @@ func NewTracerProvider(serviceName string, environment string, id int64) (*sdkTr
 		attribute.Int64("ID", id),
 	)
 
-	return sdkTrace.NewTracerProvider(
-		sdkTrace.WithBatcher(exporter, sdkTrace.WithBatchTimeout(time.Second*1)),
+	options := []sdkTrace.TracerProviderOption{
 		sdkTrace.WithResource(res),
-		//sdkTrace.WithSampler(sdkTrace.AlwaysSample()), // not sure if need to be enabled
-	), nil
+		sdkTrace.WithSampler(sampler),
+	}
+
+	exporter, err := newTraceExporter(GetEnv("OTEL_TRACES_EXPORTER", TracesExporterZipkin))
+	if err != nil {
+		return nil, err
+	}
+	if exporter != nil {
+		options = append(options, sdkTrace.WithBatcher(exporter, sdkTrace.WithBatchTimeout(time.Second*1)))
+	}
+
+	return sdkTrace.NewTracerProvider(options...), nil
+}
+
+// newTraceExporter creates the span exporter by name, it returns nil when tracing export is disabled.
+func newTraceExporter(name string) (sdkTrace.SpanExporter, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case TracesExporterZipkin, "":
+		zipkinEndpoint := GetEnv("OTEL_EXPORTER_ZIPKIN_ENDPOINT", "http://localhost:9411/api/v2/spans")
+		return zipkin.New(zipkinEndpoint)
+	case TracesExporterOTLP:
+		protocol := GetEnv("OTEL_EXPORTER_OTLP_TRACES_PROTOCOL", GetEnv("OTEL_EXPORTER_OTLP_PROTOCOL", "grpc"))
+		switch protocol {
+		case "grpc":
+			return otlptracegrpc.New(context.Background())
+		case "http/protobuf", "http":
+			return otlptracehttp.New(context.Background())
+		default:
+			return nil, fmt.Errorf("unsupported OTLP protocol %q", protocol)
+		}
+	case TracesExporterConsole, TracesExporterStdout:
+		return stdouttrace.New()
+	case TracesExporterNone:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unsupported traces exporter %q", name)
+	}
+}
+
+// newTraceSampler creates the sampler by name, arg is the sampling ratio for the ratio samplers.
+func newTraceSampler(name string, arg string) (sdkTrace.Sampler, error) {
+	ratio := 1.0
+	if arg != "" {
+		var err error
+		ratio, err = strconv.ParseFloat(arg, 64)
+		if err != nil || ratio < 0 || ratio > 1 {
+			return nil, fmt.Errorf("invalid traces sampler ratio %q, must be between 0 and 1", arg)
+		}
+	}
+
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case TracesSamplerAlwaysOn:
+		return sdkTrace.AlwaysSample(), nil
+	case TracesSamplerAlwaysOff:
+		return sdkTrace.NeverSample(), nil
+	case TracesSamplerTraceIDRatio:
+		return sdkTrace.TraceIDRatioBased(ratio), nil
+	case TracesSamplerParentBasedAlwaysOn, "":
+		return sdkTrace.ParentBased(sdkTrace.AlwaysSample()), nil
+	case TracesSamplerParentBasedAlwaysOff:
+		return sdkTrace.ParentBased(sdkTrace.NeverSample()), nil
+	case TracesSamplerParentBasedTraceIDRatio:
+		return sdkTrace.ParentBased(sdkTrace.TraceIDRatioBased(ratio)), nil
+	default:
+		return nil, fmt.Errorf("unsupported traces sampler %q", name)
+	}
 }
@@ -0,0 +1,54 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTraceSampler(t *testing.T) {
+	testCases := []struct {
+		name        string
+		sampler     string
+		arg         string
+		description string
+		wantErr     bool
+	}{
+		{name: "default", sampler: "", description: "ParentBased{root:AlwaysOnSampler,remoteParentSampled:AlwaysOnSampler,remoteParentNotSampled:AlwaysOffSampler,localParentSampled:AlwaysOnSampler,localParentNotSampled:AlwaysOffSampler}"},
+		{name: "always on", sampler: TracesSamplerAlwaysOn, description: "AlwaysOnSampler"},
+		{name: "always off", sampler: TracesSamplerAlwaysOff, description: "AlwaysOffSampler"},
+		{name: "ratio", sampler: TracesSamplerTraceIDRatio, arg: "0.25", description: "TraceIDRatioBased{0.25}"},
+		{name: "invalid ratio", sampler: TracesSamplerTraceIDRatio, arg: "2", wantErr: true},
+		{name: "unknown sampler", sampler: "sometimes", wantErr: true},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			sampler, err := newTraceSampler(testCase.sampler, testCase.arg)
+			if testCase.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, testCase.description, sampler.Description())
+		})
+	}
+}
+
+func TestNewTraceExporter(t *testing.T) {
+	exporter, err := newTraceExporter(TracesExporterNone)
+	require.NoError(t, err)
+	assert.Nil(t, exporter)
+
+	exporter, err = newTraceExporter(TracesExporterStdout)
+	require.NoError(t, err)
+	assert.NotNil(t, exporter)
+
+	_, err = newTraceExporter("jaeger")
+	assert.Error(t, err)
+}
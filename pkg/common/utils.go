@@ -5,13 +5,10 @@
 package common
 
 import (
-	"encoding/json"
 	"math/rand"
 	"os"
 	"strconv"
 	"time"
-
-	"github.com/sirupsen/logrus"
 )
 
 func GetEnv(key, fallback string) string {
@@ -40,16 +37,7 @@ func GenerateRandomInt() int {
 	return random.Intn(10000)
 }
 
-// LogJSONFormatter is printing the data in log
+// LogJSONFormatter is printing the data in log, user IDs and blocked player lists are redacted
 func LogJSONFormatter(data interface{}) string {
-	response, err := json.Marshal(data)
-	if err != nil {
-		logrus.Errorf("failed to marshal json.")
-
-		return ""
-	} else {
-		logrus.SetFormatter(&logrus.JSONFormatter{PrettyPrint: false})
-
-		return string(response)
-	}
+	return LogJSON(data).String()
 }
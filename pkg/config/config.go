@@ -4,12 +4,85 @@
 
 package config
 
+import (
+	"strconv"
+	"strings"
+)
+
 type Config struct {
 	MatchTimeLimitSecond        int  `env:"MATCH_TIME_LIMIT_SECOND"            envDefault:"0"     envDocs:"configurable match time limit in second (0 means use default from code)"`
 	FindAllyMaxLoop             int  `env:"FIND_ALLY_MAX_LOOP"                 envDefault:"0"     envDocs:"number of max loop in findMatchingAlly (0 means use default from code)"`
 	FindPartyMaxLoop            int  `env:"FIND_PARTY_MAX_LOOP"                envDefault:"0"     envDocs:"number of max loop in FindPartyCombination (0 means use default from code)"`
 	PrioritizeLargerParties     bool `env:"PRIORITIZE_LARGER_PARTIES"          envDefault:"false" envDocs:"prioritize larger parties during find matches"`
 	FlagAnyMatchOptionAllCommon bool `env:"FLAG_ANY_MATCH_OPTION_ALL_COMMON"   envDefault:"true"  envDocs:"Any match option match common value for all tickets, not only by pivot ticket"`
+	LargeLobbyMinPlayers        int  `env:"LARGE_LOBBY_MIN_PLAYERS"            envDefault:"0"     envDocs:"the amount of players of a full match from which the teams are packed with first fit decreasing instead of the findMatchingAlly reorder search (0 means disabled)"`
+	MatchOptimizationBudgetMs   int  `env:"MATCH_OPTIMIZATION_BUDGET_MS"       envDefault:"0"     envDocs:"time budget in milliseconds of the local search across the matches of a chunk, it swaps compatible parties between matches and forms extra matches from the leftovers, capped by the match time limit (0 means disabled)"`
+
+	PartyFinderStrategy string `env:"PARTY_FINDER_STRATEGY" envDefault:"greedy" envDocs:"how FindPartyCombination combines the tickets of a team, greedy appends the tickets that fit in order, exact finds a full team whenever the tickets can make one"`
 
 	TicketChunkSize int `env:"TICKET_CHUNK_SIZE" envDefault:"1000" envDocs:"the amount of tickets to chunk to match at a time"`
+	RulesCacheSize  int `env:"RULES_CACHE_SIZE"  envDefault:"128"  envDocs:"the amount of parsed rulesets to cache (0 means disabled)"`
+
+	PartitionPolicy string `env:"PARTITION_POLICY" envDefault:"isolate" envDocs:"what happens to the tickets of a stream with another namespace or match pool than the first ticket, isolate matches every namespace and match pool separately, reject drops them"`
+
+	DefaultMatchLogic string `env:"DEFAULT_MATCH_LOGIC" envDefault:"default" envDocs:"name of the match logic used when the ruleset and match pool don't select one"`
+	MatchLogicPools   string `env:"MATCH_LOGIC_POOLS"   envDefault:""        envDocs:"comma separated match pool to match logic name mapping, e.g. pool-a:logic-a,pool-b:logic-b"`
+
+	ShutdownDrainDelaySecond  int `env:"SHUTDOWN_DRAIN_DELAY_SECOND"  envDefault:"5"  envDocs:"how long the server keeps accepting requests after the health check reports not serving on SIGTERM, so the load balancers stop routing to it (0 means no delay)"`
+	ShutdownGracePeriodSecond int `env:"SHUTDOWN_GRACE_PERIOD_SECOND" envDefault:"30" envDocs:"how long in-flight requests can run after SIGTERM before the server is forcefully stopped"`
+
+	DebugTickHistorySize int `env:"DEBUG_TICK_HISTORY_SIZE" envDefault:"0" envDocs:"the amount of recent ticks kept per match pool and served at /debug/matchmaker on the metrics port (0 means disabled)"`
+
+	GRPCPort                int    `env:"GRPC_PORT"                  envDefault:"6565"  envDocs:"the port of the gRPC server"`
+	TLSCertFile             string `env:"TLS_CERT_FILE"              envDefault:""      envDocs:"path of the gRPC server TLS certificate, TLS is enabled when set"`
+	TLSKeyFile              string `env:"TLS_KEY_FILE"               envDefault:""      envDocs:"path of the gRPC server TLS private key"`
+	TLSClientCAFile         string `env:"TLS_CLIENT_CA_FILE"         envDefault:""      envDocs:"path of the CA bundle verifying client certificates, mutual TLS is enabled when set"`
+	TLSReloadIntervalSecond int    `env:"TLS_RELOAD_INTERVAL_SECOND" envDefault:"30"    envDocs:"how often the TLS files are checked for changes and reloaded (0 means never)"`
+	MTLSDisableIAMAuth      bool   `env:"MTLS_DISABLE_IAM_AUTH"      envDefault:"false" envDocs:"skip the IAM token interceptors when mutual TLS verifies the clients"`
+
+	MaxTicketsPerStream int `env:"MAX_TICKETS_PER_STREAM" envDefault:"100000" envDocs:"the amount of tickets accepted in one make matches or backfill stream (0 means unlimited)"`
+	MaxPlayersPerTicket int `env:"MAX_PLAYERS_PER_TICKET" envDefault:"100"    envDocs:"the amount of players accepted in one ticket (0 means unlimited)"`
+	MaxTicketAttributes int `env:"MAX_TICKET_ATTRIBUTES"  envDefault:"256"    envDocs:"the amount of attributes accepted in a ticket or one of its players (0 means unlimited)"`
+	MaxBlockedPlayers   int `env:"MAX_BLOCKED_PLAYERS"    envDefault:"1000"   envDocs:"the length of the blocked players list accepted in a ticket (0 means unlimited)"`
+	TicketBufferSize    int `env:"TICKET_BUFFER_SIZE"     envDefault:"1000"   envDocs:"the amount of received tickets buffered before the match logic takes them (0 means unbuffered)"`
+
+	LogFormat    string `env:"LOG_FORMAT"    envDefault:"json"  envDocs:"log format, json or text"`
+	LogPayload   bool   `env:"LOG_PAYLOAD"   envDefault:"false" envDocs:"log the full gRPC request and response payloads"`
+	LogSampling  string `env:"LOG_SAMPLING"  envDefault:""      envDocs:"comma separated level to sampling mapping for the hot-path logs, e.g. debug:100,info:10 logs 1 of every 100 debug and 1 of every 10 info entries"`
+	LogRedaction string `env:"LOG_REDACTION" envDefault:"hash"  envDocs:"how user IDs and blocked player lists are logged, none, hash or redact"`
+
+	LogRedactionKey string `env:"LOG_REDACTION_KEY" envDefault:"" envDocs:"secret key of the hashed user IDs, set the same key on every replica to log the same hashes (a random key per process when empty)"`
+}
+
+// GetMatchLogicPools returns the match pool to match logic name mapping from MatchLogicPools.
+func (c *Config) GetMatchLogicPools() map[string]string {
+	pools := make(map[string]string)
+	for _, pair := range strings.Split(c.MatchLogicPools, ",") {
+		pool, logic, ok := strings.Cut(pair, ":")
+		pool, logic = strings.TrimSpace(pool), strings.TrimSpace(logic)
+		if !ok || pool == "" || logic == "" {
+			continue
+		}
+		pools[pool] = logic
+	}
+	return pools
+}
+
+// GetLogSampling returns the level to sampling mapping from LogSampling,
+// a sampling of N logs 1 of every N hot-path entries of that level.
+func (c *Config) GetLogSampling() map[string]uint64 {
+	sampling := make(map[string]uint64)
+	for _, pair := range strings.Split(c.LogSampling, ",") {
+		level, every, ok := strings.Cut(pair, ":")
+		level, every = strings.TrimSpace(level), strings.TrimSpace(every)
+		if !ok || level == "" {
+			continue
+		}
+		n, err := strconv.ParseUint(every, 10, 64)
+		if err != nil {
+			continue
+		}
+		sampling[level] = n
+	}
+	return sampling
 }
@@ -13,6 +13,7 @@ const (
 const (
 	AttrMMR          = "mmr"
 	DistanceCriteria = "distance"
+	RatingCriteria   = "rating"
 )
 
 const (
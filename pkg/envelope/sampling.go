@@ -0,0 +1,63 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package envelope
+
+import (
+	"fmt"
+	"io"
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+)
+
+const logLevelCount = logrus.TraceLevel + 1
+
+// logSampler logs 1 of every N entries per level, levels without a sampling log every entry
+type logSampler struct {
+	every  [logLevelCount]uint64
+	counts [logLevelCount]atomic.Uint64
+}
+
+var sampler atomic.Pointer[logSampler]
+
+// discardLog is returned for the entries that are sampled out
+var discardLog = func() *logrus.Entry {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	logger.SetLevel(logrus.PanicLevel)
+	return logrus.NewEntry(logger)
+}()
+
+// SetLogSampling sets the sampling of the hot-path logs by level name, a sampling of N logs 1 of every N entries.
+// A sampling of 0 or 1 logs every entry.
+func SetLogSampling(sampling map[string]uint64) error {
+	s := &logSampler{}
+	for name, every := range sampling {
+		level, err := logrus.ParseLevel(name)
+		if err != nil {
+			return fmt.Errorf("invalid log sampling level %q: %w", name, err)
+		}
+		s.every[level] = every
+	}
+	sampler.Store(s)
+
+	return nil
+}
+
+func (s *logSampler) sample(level logrus.Level) bool {
+	if s == nil || int(level) >= len(s.every) || s.every[level] <= 1 {
+		return true
+	}
+	return (s.counts[level].Add(1)-1)%s.every[level] == 0
+}
+
+// Sampled returns the scope logger if this entry of the level is sampled, otherwise a logger that discards the entry.
+// Use it for logs written per ticket or per match.
+func (s *Scope) Sampled(level logrus.Level) *logrus.Entry {
+	if !s.Log.Logger.IsLevelEnabled(level) || !sampler.Load().sample(level) {
+		return discardLog
+	}
+	return s.Log
+}
@@ -0,0 +1,45 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package envelope
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// the log sampling is global, so this test is not parallel
+func TestScope_Sampled(t *testing.T) {
+	t.Cleanup(func() { require.NoError(t, SetLogSampling(nil)) })
+
+	var out bytes.Buffer
+	logger := logrus.New()
+	logger.SetOutput(&out)
+	logger.SetLevel(logrus.DebugLevel)
+
+	scope := NewRootScope(context.Background(), "TestScope_Sampled", "")
+	t.Cleanup(func() { scope.Finish() })
+	scope.SetLogger(logger)
+
+	require.NoError(t, SetLogSampling(map[string]uint64{"debug": 5}))
+	for i := 0; i < 10; i++ {
+		scope.Sampled(logrus.DebugLevel).Debug("sampled")
+		scope.Sampled(logrus.InfoLevel).Info("not sampled")
+	}
+	assert.Equal(t, 2, strings.Count(out.String(), "msg=sampled"))
+	assert.Equal(t, 10, strings.Count(out.String(), "not sampled"))
+
+	out.Reset()
+	logger.SetLevel(logrus.InfoLevel)
+	scope.Sampled(logrus.DebugLevel).Debug("disabled level")
+	assert.Empty(t, out.String())
+
+	assert.Error(t, SetLogSampling(map[string]uint64{"loud": 2}))
+}
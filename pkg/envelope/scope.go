@@ -43,7 +43,13 @@ func ChildScopeFromRemoteScope(ctx context.Context, name string) *Scope {
 }
 
 func NewRootScope(rootCtx context.Context, name string, abTraceID string) *Scope {
-	tracer := otel.Tracer(name)
+	return NewRootScopeWithTracerProvider(rootCtx, otel.GetTracerProvider(), name, abTraceID)
+}
+
+// NewRootScopeWithTracerProvider creates a root scope whose span and child spans are started by the tracer provider
+// instead of the global one. This is mostly useful for testing.
+func NewRootScopeWithTracerProvider(rootCtx context.Context, tracerProvider oteltrace.TracerProvider, name string, abTraceID string) *Scope {
+	tracer := tracerProvider.Tracer(name)
 	ctx, span := tracer.Start(rootCtx, name)
 
 	if abTraceID == "" || len(abTraceID) != 32 {
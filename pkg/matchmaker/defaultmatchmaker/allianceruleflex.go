@@ -53,6 +53,10 @@ func ApplyAllianceFlexingRule(allianceRule models.AllianceRule, allianceFlexingR
 			allianceRule.MinNumber = flexRule.MinNumber
 			allianceRule.PlayerMaxNumber = flexRule.PlayerMaxNumber
 			allianceRule.PlayerMinNumber = flexRule.PlayerMinNumber
+			allianceRule.Teams = flexRule.Teams
+			if flexRule.MaxPremadeSizeDifference != nil {
+				allianceRule.MaxPremadeSizeDifference = flexRule.MaxPremadeSizeDifference
+			}
 			isFlexed = true
 		}
 	}
@@ -68,6 +68,12 @@ func applyRuleFlexing(sourceRuleSet models.RuleSet, pivotTime time.Time) (models
 				if ruleset.MatchingRule[i].Attribute == flexRule.Attribute {
 					ruleset.MatchingRule[i].Reference = flexRule.Reference
 					ruleset.MatchingRule[i].Criteria = flexRule.Criteria
+					if flexRule.DeviationAttribute != "" {
+						ruleset.MatchingRule[i].DeviationAttribute = flexRule.DeviationAttribute
+					}
+					if flexRule.DeviationFactor > 0 {
+						ruleset.MatchingRule[i].DeviationFactor = flexRule.DeviationFactor
+					}
 					isFlexed = true
 					break
 				}
@@ -0,0 +1,198 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package defaultmatchmaker
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/AccelByte/extend-core-matchmaker/pkg/config"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/models"
+)
+
+// isLargeLobby returns true when the allies of the alliance rule are packed by findLargeLobbyAllies.
+// Asymmetric rules always use the reorder search, their teams differ. So do the rules with a max premade size
+// difference, first fit decreasing packs the largest parties without comparing them across the teams.
+func isLargeLobby(cfg *config.Config, allianceRule models.AllianceRule) bool {
+	return cfg != nil && cfg.LargeLobbyMinPlayers > 0 && !allianceRule.IsAsymmetric() &&
+		allianceRule.MaxPremadeSizeDifference == nil && allianceRule.GetMaxPlayer() >= cfg.LargeLobbyMinPlayers
+}
+
+// largeLobbyTeam is a team being packed, with its tickets and player count.
+type largeLobbyTeam struct {
+	tickets     []models.MatchmakingRequest
+	playerCount int
+}
+
+// findLargeLobbyAllies packs the tickets into teams with first fit decreasing, without searching ticket orders.
+// The tickets are taken in order, the pivot and the priority tickets first, until they can fill every team.
+// Those tickets are packed from the largest party down, each in the first team it fits, opening a new team
+// when none has room. The teams are then topped up with the next tickets in order.
+// Teams under the player minimum take parties from the teams that can spare them, or are dropped.
+// When blocked players cannot match, a ticket blocking or blocked by a placed ticket is left out of the lobby.
+func findLargeLobbyAllies(
+	sourceTickets []models.MatchmakingRequest,
+	pivotTicket models.MatchmakingRequest,
+	allianceRule models.AllianceRule,
+	blockedPlayerOption models.BlockedPlayerOption,
+) ([]models.MatchingAlly, []models.MatchmakingRequest, error) {
+	capacity := allianceRule.GetMaxPlayer()
+
+	// Take the tickets in order until they can fill the lobby
+	ordered := largeLobbyOrder(sourceTickets, pivotTicket, allianceRule.PlayerMaxNumber)
+	selectedCount := 0
+	selectedPlayers := 0
+	for selectedCount < len(ordered) && selectedPlayers < capacity {
+		selectedPlayers += ordered[selectedCount].CountPlayer()
+		selectedCount++
+	}
+
+	// First fit decreasing, the pivot stays first so it always opens the first team
+	selected := make([]models.MatchmakingRequest, selectedCount)
+	copy(selected, ordered[:selectedCount])
+	sort.SliceStable(selected[1:], func(i, j int) bool {
+		return selected[1+i].CountPlayer() > selected[1+j].CountPlayer()
+	})
+
+	var teams []*largeLobbyTeam
+	var placed []models.MatchmakingRequest
+	used := make(map[string]struct{}, selectedCount)
+	place := func(ticket models.MatchmakingRequest) {
+		// one blocked pair doesn't fail the lobby, the later ticket waits for another match
+		if blockedPlayerOption == models.BlockedPlayerCannotMatch && isContainBlockedPlayers(placed, &ticket) {
+			return
+		}
+		playerCount := ticket.CountPlayer()
+		for _, team := range teams {
+			if team.playerCount+playerCount > allianceRule.PlayerMaxNumber {
+				continue
+			}
+			if blockedPlayerOption == models.BlockedPlayerCanMatchOnDifferentTeam && isContainBlockedPlayers(team.tickets, &ticket) {
+				continue
+			}
+			team.tickets = append(team.tickets, ticket)
+			team.playerCount += playerCount
+			used[ticket.PartyID] = struct{}{}
+			placed = append(placed, ticket)
+			return
+		}
+		if len(teams) < allianceRule.MaxNumber {
+			teams = append(teams, &largeLobbyTeam{tickets: []models.MatchmakingRequest{ticket}, playerCount: playerCount})
+			used[ticket.PartyID] = struct{}{}
+			placed = append(placed, ticket)
+		}
+	}
+	for _, ticket := range selected {
+		place(ticket)
+	}
+
+	// Top up the gaps left by the parties that did not fit
+	playerCount := 0
+	for _, team := range teams {
+		playerCount += team.playerCount
+	}
+	for _, ticket := range ordered[selectedCount:] {
+		if playerCount >= capacity {
+			break
+		}
+		place(ticket)
+		if _, ok := used[ticket.PartyID]; ok {
+			playerCount += ticket.CountPlayer()
+		}
+	}
+
+	teams = balanceLargeLobbyTeams(teams, allianceRule, blockedPlayerOption)
+
+	allies := make([]models.MatchingAlly, 0, len(teams))
+	used = make(map[string]struct{}, selectedCount)
+	for _, team := range teams {
+		matchingParties := make([]models.MatchingParty, 0, len(team.tickets))
+		for i := range team.tickets {
+			matchingParties = append(matchingParties, createMatchingParty(&team.tickets[i]))
+			used[team.tickets[i].PartyID] = struct{}{}
+		}
+		allies = append(allies, models.MatchingAlly{
+			MatchingParties: matchingParties,
+			PlayerCount:     team.playerCount,
+		})
+	}
+
+	if err := allianceRule.ValidateAllies(allies, blockedPlayerOption); err != nil {
+		return nil, nil, err
+	}
+	if !isPartyInAllies(pivotTicket.PartyID, allies) {
+		return nil, nil, fmt.Errorf("pivot ticket %s has no team", pivotTicket.PartyID)
+	}
+
+	remaining := make([]models.MatchmakingRequest, 0, len(sourceTickets))
+	for _, ticket := range sourceTickets {
+		if _, ok := used[ticket.PartyID]; !ok {
+			remaining = append(remaining, ticket)
+		}
+	}
+	return allies, remaining, nil
+}
+
+// largeLobbyOrder returns the tickets that fit in a team, the pivot first, then the priority tickets, then the others,
+// each in their original order.
+func largeLobbyOrder(tickets []models.MatchmakingRequest, pivotTicket models.MatchmakingRequest, playerMaxNumber int) []models.MatchmakingRequest {
+	ordered := make([]models.MatchmakingRequest, 0, len(tickets))
+	ordered = append(ordered, pivotTicket)
+	var others []models.MatchmakingRequest
+	for _, ticket := range tickets {
+		if ticket.PartyID == pivotTicket.PartyID || ticket.CountPlayer() > playerMaxNumber || ticket.CountPlayer() == 0 {
+			continue
+		}
+		if ticket.IsPriority() {
+			ordered = append(ordered, ticket)
+			continue
+		}
+		others = append(others, ticket)
+	}
+	return append(ordered, others...)
+}
+
+// balanceLargeLobbyTeams moves parties to the teams under the player minimum from the teams that stay
+// at the minimum without them, and drops the teams that still miss it.
+func balanceLargeLobbyTeams(teams []*largeLobbyTeam, allianceRule models.AllianceRule, blockedPlayerOption models.BlockedPlayerOption) []*largeLobbyTeam {
+	minPlayer := allianceRule.PlayerMinNumber
+
+	for _, receiver := range teams {
+		for _, donor := range teams {
+			if receiver.playerCount >= minPlayer {
+				break
+			}
+			if donor == receiver {
+				continue
+			}
+			// the first ticket of the first team is the pivot, it stays
+			for i := len(donor.tickets) - 1; i >= 0 && receiver.playerCount < minPlayer; i-- {
+				ticket := donor.tickets[i]
+				if donor == teams[0] && i == 0 {
+					continue
+				}
+				playerCount := ticket.CountPlayer()
+				if donor.playerCount-playerCount < minPlayer || receiver.playerCount+playerCount > allianceRule.PlayerMaxNumber {
+					continue
+				}
+				if blockedPlayerOption == models.BlockedPlayerCanMatchOnDifferentTeam && isContainBlockedPlayers(receiver.tickets, &ticket) {
+					continue
+				}
+				donor.tickets = append(donor.tickets[:i], donor.tickets[i+1:]...)
+				donor.playerCount -= playerCount
+				receiver.tickets = append(receiver.tickets, ticket)
+				receiver.playerCount += playerCount
+			}
+		}
+	}
+
+	kept := teams[:0]
+	for _, team := range teams {
+		if team.playerCount >= minPlayer {
+			kept = append(kept, team)
+		}
+	}
+	return kept
+}
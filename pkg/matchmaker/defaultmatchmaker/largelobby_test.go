@@ -0,0 +1,154 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package defaultmatchmaker
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/AccelByte/extend-core-matchmaker/pkg/config"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/models"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/testsetup"
+	. "github.com/onsi/gomega"
+)
+
+// battleRoyaleRule is 25 squads of up to 4 players
+var battleRoyaleRule = models.AllianceRule{
+	MinNumber:       20,
+	MaxNumber:       25,
+	PlayerMinNumber: 2,
+	PlayerMaxNumber: 4,
+}
+
+// battleRoyaleTickets returns solo to squad sized tickets with a similar mmr
+func battleRoyaleTickets(count int) []models.MatchmakingRequest {
+	tickets := make([]models.MatchmakingRequest, 0, count)
+	for i := 0; i < count; i++ {
+		tickets = append(tickets, generateRequestWithMMR("battle-royale", 1, 1+rand.Intn(4), 10)...) //nolint:gosec
+	}
+	return tickets
+}
+
+func TestFindMatchingAlly_LargeLobby(t *testing.T) {
+	g := testsetup.ParallelWithGomega(t)
+
+	tickets := battleRoyaleTickets(400)
+	cfg := &config.Config{LargeLobbyMinPlayers: 50}
+	g.Expect(isLargeLobby(cfg, battleRoyaleRule)).To(BeTrue())
+
+	allies, remaining, err := findMatchingAlly(testsetup.NewTestScope(), cfg, tickets, tickets[0], battleRoyaleRule, nil, models.BlockedPlayerCannotMatch)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(allies).To(HaveLen(25))
+
+	playerCount := 0
+	for _, ally := range allies {
+		g.Expect(ally.CountPlayer()).To(BeNumerically(">=", 2))
+		g.Expect(ally.CountPlayer()).To(BeNumerically("<=", 4))
+		playerCount += ally.CountPlayer()
+	}
+	g.Expect(playerCount).To(Equal(100))
+	g.Expect(allyPartyIDs(allies[0])[0]).To(Equal(tickets[0].PartyID))
+	g.Expect(remaining).To(HaveLen(len(tickets) - countParties(allies)))
+}
+
+func TestFindMatchingAlly_LargeLobbyWithMaxPremadeSizeDifference(t *testing.T) {
+	g := testsetup.ParallelWithGomega(t)
+
+	maxPremadeSizeDifference := 0
+	rule := models.AllianceRule{MinNumber: 2, MaxNumber: 2, PlayerMinNumber: 4, PlayerMaxNumber: 4, MaxPremadeSizeDifference: &maxPremadeSizeDifference}
+	cfg := &config.Config{LargeLobbyMinPlayers: 4}
+	g.Expect(isLargeLobby(cfg, rule)).To(BeFalse())
+
+	// packing the largest party first would put the squad against two duos
+	tickets := generateRequestWithMemberCount("battle-royale", []int{2, 4, 2, 2, 2})
+	allies, _, err := findMatchingAlly(testsetup.NewTestScope(), cfg, tickets, tickets[0], rule, nil, models.BlockedPlayerCannotMatch)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(allies).To(HaveLen(2))
+	g.Expect(rule.ValidateAllies(allies, models.BlockedPlayerCannotMatch)).To(Succeed())
+}
+
+func TestFindLargeLobbyAllies_PlayerMinimum(t *testing.T) {
+	t.Parallel()
+
+	rule := models.AllianceRule{MinNumber: 2, MaxNumber: 3, PlayerMinNumber: 3, PlayerMaxNumber: 4}
+
+	t.Run("team under the minimum takes parties from a team that can spare them", func(t *testing.T) {
+		g := testsetup.ParallelWithGomega(t)
+
+		tickets := generateRequestWithMemberCount("battle-royale", []int{1, 1, 1, 1, 1, 1})
+		allies, remaining, err := findLargeLobbyAllies(tickets, tickets[0], rule, models.BlockedPlayerCannotMatch)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(allies).To(HaveLen(2))
+		g.Expect(allies[0].CountPlayer()).To(Equal(3))
+		g.Expect(allies[1].CountPlayer()).To(Equal(3))
+		g.Expect(remaining).To(BeEmpty())
+	})
+
+	t.Run("team under the minimum is dropped", func(t *testing.T) {
+		g := testsetup.ParallelWithGomega(t)
+
+		tickets := generateRequestWithMemberCount("battle-royale", []int{4, 4, 2})
+		allies, remaining, err := findLargeLobbyAllies(tickets, tickets[0], rule, models.BlockedPlayerCannotMatch)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(allies).To(HaveLen(2))
+		g.Expect(remaining).To(ConsistOf(tickets[2]))
+	})
+
+	t.Run("not enough teams", func(t *testing.T) {
+		g := testsetup.ParallelWithGomega(t)
+
+		tickets := generateRequestWithMemberCount("battle-royale", []int{4, 2})
+		_, _, err := findLargeLobbyAllies(tickets, tickets[0], rule, models.BlockedPlayerCannotMatch)
+		g.Expect(err).To(HaveOccurred())
+	})
+}
+
+func TestFindLargeLobbyAllies_LeavesBlockedTicketsOut(t *testing.T) {
+	g := testsetup.ParallelWithGomega(t)
+
+	rule := models.AllianceRule{MinNumber: 2, MaxNumber: 2, PlayerMinNumber: 2, PlayerMaxNumber: 2}
+	tickets := generateRequestWithMemberCount("battle-royale", []int{1, 1, 1, 1, 1})
+	tickets[1].PartyAttributes[models.AttributeBlocked] = []interface{}{tickets[2].PartyMembers[0].UserID}
+
+	// the blocked ticket is left for another match, the next ticket takes its place
+	allies, remaining, err := findLargeLobbyAllies(tickets, tickets[0], rule, models.BlockedPlayerCannotMatch)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(allies).To(HaveLen(2))
+	g.Expect(rule.ValidateAllies(allies, models.BlockedPlayerCannotMatch)).To(Succeed())
+	g.Expect(remaining).To(ConsistOf(tickets[2]))
+}
+
+func countParties(allies []models.MatchingAlly) int {
+	count := 0
+	for _, ally := range allies {
+		count += len(ally.MatchingParties)
+	}
+	return count
+}
+
+// BenchmarkFindMatchingAlly_LargeLobby compares the reorder search with the large lobby packing
+// for 100 player matches from a pool of 2000 tickets.
+func BenchmarkFindMatchingAlly_LargeLobby(b *testing.B) {
+	scope := testsetup.NewTestScope()
+	tickets := battleRoyaleTickets(2000)
+
+	benchmarks := []struct {
+		name string
+		cfg  *config.Config
+	}{
+		{name: "reorder", cfg: &config.Config{}},
+		{name: "large_lobby", cfg: &config.Config{LargeLobbyMinPlayers: 50}},
+	}
+	for _, benchmark := range benchmarks {
+		b.Run(benchmark.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				pivot := tickets[i%len(tickets)]
+				if _, _, err := findMatchingAlly(scope, benchmark.cfg, tickets, pivot, battleRoyaleRule, nil, models.BlockedPlayerCannotMatch); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
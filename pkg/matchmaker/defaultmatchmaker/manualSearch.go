@@ -15,6 +15,7 @@ import (
 
 	"github.com/AccelByte/extend-core-matchmaker/pkg/envelope"
 	"github.com/AccelByte/extend-core-matchmaker/pkg/models"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/utils"
 
 	"github.com/elliotchance/pie/v2"
 	"gopkg.in/typ.v4/slices"
@@ -320,6 +321,12 @@ type distance struct {
 	max               float64  // Maximum acceptable value
 	attributeMaxValue float64  // Maximum value for normalization
 	weight            *float64 // Weight for scoring
+
+	// rating criteria only
+	isRating             bool    // Scored by the draw probability instead of the difference
+	deviationAttribute   string  // The attribute name of the rating deviation
+	deviation            float64 // The pivot rating deviation
+	performanceDeviation float64 // Deviation of a performance around the rating
 }
 
 // getWeight returns the weight value for this distance criterion.
@@ -340,8 +347,29 @@ func getFilterByDistance(activeRuleSet *models.RuleSet, partyAttributes map[stri
 	}
 	distances := make([]distance, 0)
 	for _, rule := range activeRuleSet.MatchingRule {
+		if rule.Criteria == ratingCriteria {
+			value, ok := utils.ToFloat64(memberAttributes[rule.Attribute])
+			if !ok {
+				continue
+			}
+			// a ticket without a deviation is as certain as it gets
+			deviation, _ := utils.ToFloat64(memberAttributes[rule.DeviationAttribute])
+			width := rule.Reference + rule.GetDeviationFactor()*deviation
+			distances = append(distances, distance{
+				attribute:            rule.Attribute,
+				value:                value,
+				min:                  value - width,
+				max:                  value + width,
+				weight:               rule.Weight,
+				isRating:             true,
+				deviationAttribute:   rule.DeviationAttribute,
+				deviation:            deviation,
+				performanceDeviation: rule.GetPerformanceDeviation(),
+			})
+			continue
+		}
 		if rule.Criteria == distanceCriteria {
-			value, ok := memberAttributes[rule.Attribute].(float64)
+			value, ok := utils.ToFloat64(memberAttributes[rule.Attribute])
 			if !ok {
 				continue
 			}
@@ -363,6 +391,7 @@ func getFilterByDistance(activeRuleSet *models.RuleSet, partyAttributes map[stri
 
 // matchByDistance checks if a ticket matches distance-based criteria and returns a score.
 // The smaller the score, the better the match. This function considers rule flexing for aging tickets.
+// A rating criterion scores one minus the draw probability of the two ratings.
 func matchByDistance(ticket *models.MatchmakingRequest, originalRuleSet *models.RuleSet, distances []distance) (isMatch bool, score float64) {
 	if len(distances) == 0 {
 		return true, 0.0
@@ -378,7 +407,7 @@ func matchByDistance(ticket *models.MatchmakingRequest, originalRuleSet *models.
 		return false, 0.0
 	}
 	for _, distance := range distances {
-		value, ok := memberAttributes[distance.attribute].(float64)
+		value, ok := utils.ToFloat64(memberAttributes[distance.attribute])
 		if !ok {
 			return false, 0.0
 		}
@@ -409,7 +438,10 @@ func matchByDistance(ticket *models.MatchmakingRequest, originalRuleSet *models.
 			}
 		}
 		// Calculate score based on distance difference
-		if distance.attributeMaxValue > 0 {
+		if distance.isRating {
+			deviation, _ := utils.ToFloat64(memberAttributes[distance.deviationAttribute])
+			score += (1 - drawProbability(distance.value, distance.deviation, value, deviation, distance.performanceDeviation)) * distance.getWeight()
+		} else if distance.attributeMaxValue > 0 {
 			score += (math.Abs(value-distance.value) / distance.attributeMaxValue) * distance.getWeight()
 		} else {
 			score += math.Abs(value - distance.value)
@@ -868,3 +900,17 @@ func matchByAdditionalCriteria(ticket *models.MatchmakingRequest, additionalCrit
 	}
 	return true
 }
+
+// drawProbability returns the TrueSkill match quality of two ratings, the probability of a draw relative to
+// the draw of two equal and certain ratings: between 0 and 1, the closer and more certain the ratings the higher.
+func drawProbability(meanA, deviationA, meanB, deviationB, performanceDeviation float64) float64 {
+	variance := 2*performanceDeviation*performanceDeviation + deviationA*deviationA + deviationB*deviationB
+	if variance == 0 {
+		if meanA == meanB {
+			return 1
+		}
+		return 0
+	}
+	difference := meanA - meanB
+	return math.Sqrt(2*performanceDeviation*performanceDeviation/variance) * math.Exp(-difference*difference/(2*variance))
+}
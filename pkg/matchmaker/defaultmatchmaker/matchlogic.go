@@ -10,6 +10,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"sort"
 	"strings"
 	"sync"
@@ -17,6 +18,7 @@ import (
 
 	"github.com/AccelByte/extend-core-matchmaker/pkg/config"
 	"github.com/AccelByte/extend-core-matchmaker/pkg/envelope"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/metrics"
 	"github.com/AccelByte/extend-core-matchmaker/pkg/models"
 	"github.com/AccelByte/extend-core-matchmaker/pkg/utils"
 
@@ -29,12 +31,17 @@ import (
 // externalPartyID is used to identify parties that are not part of the internal system
 const externalPartyID = "external"
 
+// pivotCooldownsIdleTicks is how many MakeMatches calls of the other pools a pool can miss before its pivot cooldowns are forgotten
+const pivotCooldownsIdleTicks = 10000
+
 // defaultMatchMaker implements the MatchLogic interface with the default matchmaking algorithm.
 // It handles ticket validation, match creation, and backfill operations.
 type defaultMatchMaker struct {
-	unmatchedTickets    []matchmaker.Ticket   // Tickets that haven't been matched yet
-	mm                  matchmaker.Matchmaker // The underlying matchmaker implementation
-	indexedTicketLength int                   // Size of ticket chunks for processing
+	unmatchedTickets    []matchmaker.Ticket        // Tickets that haven't been matched yet
+	mm                  matchmaker.Matchmaker      // The underlying matchmaker implementation
+	indexedTicketLength int                        // Size of ticket chunks for processing
+	partitionPolicy     string                     // What happens to the tickets of another namespace or match pool in a stream
+	pivotCooldowns      *models.PoolPivotCooldowns // Tickets that failed as a pivot in the previous ticks of their match pool
 }
 
 // New returns a defaultMatchMaker of the MatchLogic interface.
@@ -42,7 +49,9 @@ type defaultMatchMaker struct {
 func New(cfg *config.Config) matchmaker.MatchLogic {
 	return defaultMatchMaker{
 		indexedTicketLength: cfg.TicketChunkSize,
+		partitionPolicy:     cfg.PartitionPolicy,
 		mm:                  NewMatchMaker(cfg),
+		pivotCooldowns:      models.NewPoolPivotCooldowns(pivotCooldownsIdleTicks),
 	}
 }
 
@@ -50,7 +59,6 @@ func New(cfg *config.Config) matchmaker.MatchLogic {
 // This method checks if a ticket meets all requirements to be queued for matchmaking.
 func (b defaultMatchMaker) ValidateTicket(scope *envelope.Scope, matchTicket matchmaker.Ticket, matchRules interface{}) (bool, error) {
 	scope.Log.Info("MATCHMAKER: validate ticket")
-	scope.Log.Info("Ticket Validation successful")
 
 	// Type assertion to get the ruleset
 	ruleSet, ok := matchRules.(models.RuleSet)
@@ -59,18 +67,14 @@ func (b defaultMatchMaker) ValidateTicket(scope *envelope.Scope, matchTicket mat
 		return false, errors.New("invalid ruleset")
 	}
 
-	// Check if the ticket has valid latency to at least one region
-	hasValidLatency := false
-	for _, latency := range matchTicket.Latencies {
-		if latency <= int64(ruleSet.RegionLatencyMaxMs) {
-			hasValidLatency = true
-			break
-		}
+	if err := validateTicket(matchTicket, ruleSet); err != nil {
+		scope.Log.WithField("ticketID", matchTicket.TicketID).
+			WithError(err).
+			Warn("ticket validation failed")
+		return false, err
 	}
 
-	if !hasValidLatency {
-		return false, errors.New("no region latency below max")
-	}
+	scope.Log.Info("Ticket Validation successful")
 
 	return true, nil
 }
@@ -134,20 +138,45 @@ func (b defaultMatchMaker) MakeMatches(rootScope *envelope.Scope, ticketProvider
 
 	go func() {
 		var wg sync.WaitGroup
-		channel := models.Channel{
-			Ruleset: ruleset,
-		}
+		b.pivotCooldowns.NextTick()
 
-		// Process tickets in chunks for better performance
-		ticketChannel := ticketProvider.GetTickets()
-		for requests, tickets := getNextNRequests(ticketChannel, b.indexedTicketLength, ruleset); len(tickets) > 0; requests, tickets = getNextNRequests(ticketChannel, b.indexedTicketLength, ruleset) {
+		// every namespace and match pool of the stream starts a tick of its own pivot cooldowns and region match counts
+		partitionChannels := make(map[partitionKey]models.Channel)
+
+		// Process tickets in chunks for better performance, tickets are only matched with tickets of the same namespace and match pool
+		chunker := newTicketChunker(b.partitionPolicy, b.indexedTicketLength)
+		runChunk := func(sourceTickets []matchmaker.Ticket) {
 			wg.Add(1)
+			requests := pie.Map(sourceTickets, toMatchRequest(ruleset))
 
-			requestValues := requests
-			sourceTickets := tickets
+			// a chunk only has the tickets of one partition
+			key := ticketPartitionKey(sourceTickets[0])
+			chunkChannel, ok := partitionChannels[key]
+			if !ok {
+				chunkChannel = models.Channel{
+					Ruleset:        ruleset,
+					RegionMatches:  models.NewRegionMatchCounter(),
+					PivotCooldowns: b.pivotCooldowns.ForPool(key.namespace, key.matchPool),
+				}
+				chunkChannel.PivotCooldowns.NextTick()
+				partitionChannels[key] = chunkChannel
+			}
 
 			// Run matchmaking in a separate goroutine
-			go b.runMatchMaking(scope, requestValues, results, &wg, channel, channel.Ruleset, sourceTickets)
+			go b.runMatchMaking(scope, requests, results, &wg, chunkChannel, ruleset, sourceTickets)
+		}
+		for ticket := range ticketProvider.GetTickets() {
+			if chunk := chunker.add(scope, ticket); len(chunk) > 0 {
+				runChunk(chunk)
+			}
+		}
+		for _, chunk := range chunker.flush() {
+			runChunk(chunk)
+		}
+
+		for _, key := range chunker.order {
+			metrics.TicketsReceived.WithLabelValues(key.namespace, key.matchPool).Add(float64(chunker.counts[key]))
+			metrics.TicketsPerTick.WithLabelValues(key.namespace, key.matchPool).Observe(float64(chunker.counts[key]))
 		}
 
 		wg.Wait()
@@ -177,15 +206,12 @@ func (b defaultMatchMaker) BackfillMatches(rootScope *envelope.Scope, ticketProv
 			Ruleset: ruleset,
 		}
 
-		// Process both backfill tickets and regular tickets
-		backfillTicketChannel := ticketProvider.GetBackfillTickets()
-		ticketChannel := ticketProvider.GetTickets()
-		for requests, sessions, tickets := getNextNBackfillRequests(scope, backfillTicketChannel, ticketChannel, b.indexedTicketLength, ruleset); len(sessions) > 0; requests, sessions, tickets = getNextNBackfillRequests(scope, backfillTicketChannel, ticketChannel, b.indexedTicketLength, ruleset) {
+		// Read both streams fully, every session can then be matched against every eligible ticket of its namespace and match pool
+		requests, sessions, tickets := readBackfillRequests(scope, ticketProvider.GetBackfillTickets(), ticketProvider.GetTickets(), ruleset)
+		partitioner := newPartitioner(b.partitionPolicy)
+		for _, partition := range partitioner.partitionBackfill(scope, requests, sessions, tickets) {
 			wg.Add(1)
-
-			requestValues := requests
-			sessionValues := sessions
-			go b.runBackfilling(scope, tickets, requestValues, sessionValues, channel, results, &wg)
+			go b.runBackfilling(scope, partition, channel, results, &wg)
 		}
 		wg.Wait()
 		close(results)
@@ -193,76 +219,88 @@ func (b defaultMatchMaker) BackfillMatches(rootScope *envelope.Scope, ticketProv
 	return results
 }
 
-// runBackfilling handles the backfill process for existing sessions.
-// This method attempts to add new players to existing matches that need more participants.
+// runBackfilling handles the backfill process for the existing sessions of one partition.
+// The sessions are matched in chunks one after another, so a ticket added to a session is not proposed to the next chunks.
 func (b defaultMatchMaker) runBackfilling(
-	rootScope *envelope.Scope, tickets []matchmaker.Ticket, requests []models.MatchmakingRequest,
-	sessions []*models.MatchmakingResult, channel models.Channel, results chan matchmaker.BackfillProposal,
-	wg *sync.WaitGroup,
+	rootScope *envelope.Scope, partition *backfillPartition, channel models.Channel,
+	results chan matchmaker.BackfillProposal, wg *sync.WaitGroup,
 ) {
 	scope := rootScope.NewChildScope("runBackfilling")
 	defer scope.Finish()
 	defer wg.Done()
-	namespace, matchPool := getNamespaceMatchPool(tickets)
-
-	// Attempt to match new players with existing sessions
-	updatedSessions, satisfiedSessions, satisfiedTickets, err := b.mm.MatchSessions(scope, namespace, matchPool, requests, sessions, channel)
-	if err != nil {
-		scope.Log.Errorf("error backfilling matches: %s", err)
-	}
+	namespace, matchPool := partition.key.namespace, partition.key.matchPool
+	requests, tickets := partition.requests, partition.tickets
+
+	chunkSize := b.indexedTicketLength
+	if chunkSize <= 0 {
+		chunkSize = len(partition.sessions)
+	}
+	for _, sessions := range pie.Chunk(partition.sessions, chunkSize) {
+		// Attempt to match new players with existing sessions
+		updatedSessions, satisfiedSessions, satisfiedTickets, err := b.mm.MatchSessions(scope, namespace, matchPool, requests, sessions, channel)
+		if err != nil {
+			scope.Log.Errorf("error backfilling matches: %s", err)
+		}
+
+		// Convert results to backfill proposals
+		for _, result := range append(updatedSessions, satisfiedSessions...) {
+			proposal := fromMatchResultToBackfillProposal(result, satisfiedTickets, tickets)
+			metrics.BackfillProposals.WithLabelValues(namespace, matchPool).Inc()
+			for _, ticket := range proposal.AddedTickets {
+				metrics.ObserveTimeToMatch(namespace, matchPool, ticket.CreatedAt)
+			}
+			results <- proposal
+		}
 
-	// Convert results to backfill proposals
-	for _, result := range updatedSessions {
-		results <- fromMatchResultToBackfillProposal(result, satisfiedTickets, tickets)
-	}
-	for _, result := range satisfiedSessions {
-		results <- fromMatchResultToBackfillProposal(result, satisfiedTickets, tickets)
+		requests, tickets = withoutSatisfiedRequests(requests, tickets, satisfiedTickets)
+		if len(requests) == 0 {
+			return
+		}
 	}
 }
 
-// getNextNRequests retrieves the next batch of tickets from the ticket channel.
-// This function processes tickets in chunks to improve performance and memory usage.
-func getNextNRequests(ticketChannel chan matchmaker.Ticket, maxTicketCount int, ruleset models.RuleSet) ([]models.MatchmakingRequest, []matchmaker.Ticket) {
-	var indexedTickets []matchmaker.Ticket
-	var requests []models.MatchmakingRequest
+// withoutSatisfiedRequests removes the satisfied requests and their tickets.
+// Requests are matched to tickets by ID since MatchSessions may reorder the requests.
+func withoutSatisfiedRequests(requests []models.MatchmakingRequest, tickets []matchmaker.Ticket,
+	satisfiedRequests []models.MatchmakingRequest) ([]models.MatchmakingRequest, []matchmaker.Ticket) {
+	if len(satisfiedRequests) == 0 {
+		return requests, tickets
+	}
 
-	// Collect up to maxTicketCount tickets from the channel
-	for i := 0; i < maxTicketCount; i++ {
-		ticket, ok := <-ticketChannel
-		if !ok {
-			break
-		}
-		indexedTickets = append(indexedTickets, ticket)
+	satisfied := make(map[string]struct{}, len(satisfiedRequests))
+	for _, request := range satisfiedRequests {
+		satisfied[request.PartyID] = struct{}{}
 	}
-	// Convert tickets to matchmaking requests
-	requests = pie.Map(indexedTickets, toMatchRequest(ruleset))
 
-	return requests, indexedTickets
+	remainingRequests := pie.Filter(requests, func(request models.MatchmakingRequest) bool {
+		_, ok := satisfied[request.PartyID]
+		return !ok
+	})
+	remainingTickets := pie.Filter(tickets, func(ticket matchmaker.Ticket) bool {
+		_, ok := satisfied[ticket.TicketID]
+		return !ok
+	})
+	return remainingRequests, remainingTickets
 }
 
-// getNextNBackfillRequests retrieves both backfill tickets and regular tickets for processing.
-// This function runs two goroutines concurrently to collect tickets and backfill sessions.
-func getNextNBackfillRequests(rootScope *envelope.Scope, backfillTicketChannel chan matchmaker.BackfillTicket,
-	ticketChannel chan matchmaker.Ticket, maxTicketCount int, ruleSet models.RuleSet) ([]models.MatchmakingRequest, []*models.MatchmakingResult, []matchmaker.Ticket) {
+// readBackfillRequests reads the tickets and the backfill tickets until both channels are closed.
+// The channels are read concurrently so the sender is never blocked on either of them.
+func readBackfillRequests(rootScope *envelope.Scope, backfillTicketChannel chan matchmaker.BackfillTicket,
+	ticketChannel chan matchmaker.Ticket, ruleSet models.RuleSet) ([]models.MatchmakingRequest, []*models.MatchmakingResult, []matchmaker.Ticket) {
 
-	scope := rootScope.NewChildScope("getNextNBackfillRequests")
+	scope := rootScope.NewChildScope("readBackfillRequests")
 	defer scope.Finish()
 
 	var indexedTickets []matchmaker.Ticket
 	var requests []models.MatchmakingRequest
 	var indexedBackfillTickets []matchmaker.BackfillTicket
-	var sessions []*models.MatchmakingResult
 	wg := sync.WaitGroup{}
 
 	// Collect regular tickets in a goroutine
 	wg.Add(1)
 	go func(wg *sync.WaitGroup) {
-		for i := 0; i < maxTicketCount; i++ {
-			ticket, ok := <-ticketChannel
-			if !ok {
-				break
-			}
-
+		defer wg.Done()
+		for ticket := range ticketChannel {
 			request := toMatchRequest(ruleSet)(ticket)
 			// Skip tickets that are only for new sessions
 			if request.IsNewSessionOnly() {
@@ -272,25 +310,21 @@ func getNextNBackfillRequests(rootScope *envelope.Scope, backfillTicketChannel c
 			indexedTickets = append(indexedTickets, ticket)
 			requests = append(requests, request)
 		}
-		wg.Done()
 	}(&wg)
 
 	// Collect backfill tickets in a goroutine
 	wg.Add(1)
 	go func(wg *sync.WaitGroup) {
-		for i := 0; i < maxTicketCount; i++ {
-			backfillTicket, ok := <-backfillTicketChannel
-			if !ok {
-				break
-			}
+		defer wg.Done()
+		for backfillTicket := range backfillTicketChannel {
 			indexedBackfillTickets = append(indexedBackfillTickets, backfillTicket)
 		}
-		// Convert backfill tickets to matchmaking results
-		sessions = pie.Map(indexedBackfillTickets, fromBackfillTicketsToMatchResult(scope, ruleSet))
-		wg.Done()
 	}(&wg)
 	wg.Wait()
 
+	// Convert backfill tickets to matchmaking results
+	sessions := pie.Map(indexedBackfillTickets, fromBackfillTicketsToMatchResult(scope, ruleSet))
+
 	return requests, sessions, indexedTickets
 }
 
@@ -342,38 +376,75 @@ func toMatchRequest(ruleset models.RuleSet) func(ticket matchmaker.Ticket) model
 
 // avergaeMatchingRuleAttributes calculates the average of matching rule attributes across all players.
 // This function aggregates player attributes to create party-level attributes for matchmaking.
+// Rule attributes can be dotted paths into nested attributes (e.g. "ranked.mmr"), and players
+// missing an attribute are left out of the average instead of being counted as 0.
 func avergaeMatchingRuleAttributes(players []player.PlayerData, ruleset models.RuleSet) map[string]interface{} {
 	memberAttributes := make(map[string]interface{})
 
 	for _, rule := range ruleset.MatchingRule {
 		var totalAttr float64
+		var count int
 		for _, playerData := range players {
-			if playerData.Attributes == nil {
-				continue
-			}
-			if attr, ok := playerData.Attributes[rule.Attribute]; ok {
-				// Handle different numeric types
-				switch attr.(type) {
-				case float64:
-					totalAttr += attr.(float64)
-				case int32:
-					totalAttr += float64(attr.(int32))
-				case int64:
-					totalAttr += float64(attr.(int64))
-				case int:
-					totalAttr += float64(attr.(int))
-				}
+			if value, ok := getPlayerAttributeFloat64(playerData, rule.Attribute); ok {
+				totalAttr += value
+				count++
 			}
 		}
-		if len(players) > 0 {
-			memberAttributes[rule.Attribute] = totalAttr / float64(len(players))
+		if count > 0 {
+			memberAttributes[rule.Attribute] = totalAttr / float64(count)
 		} else {
 			memberAttributes[rule.Attribute] = float64(0)
 		}
+
+		// The deviation of a party is the root mean square of the deviations of its players
+		if rule.Criteria == ratingCriteria {
+			var totalVariance float64
+			var deviationCount int
+			for _, playerData := range players {
+				if deviation, ok := getPlayerAttributeFloat64(playerData, rule.DeviationAttribute); ok {
+					totalVariance += deviation * deviation
+					deviationCount++
+				}
+			}
+			if deviationCount > 0 {
+				memberAttributes[rule.DeviationAttribute] = math.Sqrt(totalVariance / float64(deviationCount))
+			} else {
+				memberAttributes[rule.DeviationAttribute] = float64(0)
+			}
+		}
 	}
 	return memberAttributes
 }
 
+// getPlayerAttributeFloat64 resolves a (possibly dotted) attribute path on a player and converts it to float64.
+func getPlayerAttributeFloat64(playerData player.PlayerData, attribute string) (float64, bool) {
+	value, ok := utils.GetMapValueByPath(playerData.Attributes, attribute)
+	if !ok {
+		return 0, false
+	}
+	return utils.ToFloat64(value)
+}
+
+// getMissingMatchingRuleAttributes returns the matching rule attributes that a player does not have as a number.
+func getMissingMatchingRuleAttributes(playerData player.PlayerData, ruleset models.RuleSet) []string {
+	var missing []string
+	for _, rule := range ruleset.MatchingRule {
+		if utils.Contains(missing, rule.Attribute) {
+			continue
+		}
+		if _, ok := getPlayerAttributeFloat64(playerData, rule.Attribute); !ok {
+			missing = append(missing, rule.Attribute)
+		}
+		if rule.Criteria != ratingCriteria || utils.Contains(missing, rule.DeviationAttribute) {
+			continue
+		}
+		if _, ok := getPlayerAttributeFloat64(playerData, rule.DeviationAttribute); !ok {
+			missing = append(missing, rule.DeviationAttribute)
+		}
+	}
+	return missing
+}
+
 // fromMatchResult converts a models.MatchmakingResult to a matchmaker.Match.
 // This function handles the conversion from internal matchmaking result to the external match format.
 func fromMatchResult(result *models.MatchmakingResult, sourceTickets []matchmaker.Ticket, ruleset models.RuleSet) matchmaker.Match {
@@ -405,7 +476,7 @@ func fromMatchResult(result *models.MatchmakingResult, sourceTickets []matchmake
 	return matchmaker.Match{
 		Tickets:          matchingTickets,
 		Teams:            toTeams(sourceTickets, result.MatchingAllies),
-		RegionPreference: []string{result.Region},
+		RegionPreference: regionPreference(result),
 		MatchAttributes:  result.PartyAttributes,
 		Backfill:         backfill,
 		ServerName:       result.ServerName,
@@ -415,6 +486,14 @@ func fromMatchResult(result *models.MatchmakingResult, sourceTickets []matchmake
 	}
 }
 
+// regionPreference returns the ranked regions of the result, or its only region.
+func regionPreference(result *models.MatchmakingResult) []string {
+	if len(result.RegionPreference) > 0 {
+		return result.RegionPreference
+	}
+	return []string{result.Region}
+}
+
 // isMatchFull determines if a match has reached its maximum player capacity.
 // This function considers alliance flexing rules when calculating the maximum player count.
 func isMatchFull(tickets []matchmaker.Ticket, result *models.MatchmakingResult, ruleset models.RuleSet) bool {
@@ -426,7 +505,7 @@ func isMatchFull(tickets []matchmaker.Ticket, result *models.MatchmakingResult,
 	var maxPlayerCount int
 	{
 		currentRule, _ := applyAllianceFlexingRules(ruleset, oldestTicket.CreatedAt)
-		maxPlayerCount = currentRule.AllianceRule.MaxNumber * currentRule.AllianceRule.PlayerMaxNumber
+		maxPlayerCount = currentRule.AllianceRule.GetMaxPlayer()
 	}
 
 	// Check if match is full
@@ -549,11 +628,20 @@ func fromBackfillTicketsToMatchResult(rootScope *envelope.Scope, rules models.Ru
 
 		partyAttributes[models.AttributeMemberAttr] = avergaeMatchingRuleAttributes(playerData, rules)
 
+		// The backfill ticket has no namespace, take it from the tickets already in the match
+		var namespace string
+		for _, ticket := range backfillTicket.PartialMatch.Tickets {
+			if ticket.Namespace != "" {
+				namespace = ticket.Namespace
+				break
+			}
+		}
+
 		return &models.MatchmakingResult{
 			MatchID:         backfillTicket.TicketID,
 			MatchSessionID:  backfillTicket.MatchSessionID,
 			Channel:         backfillTicket.MatchPool,
-			Namespace:       "",
+			Namespace:       namespace,
 			GameMode:        "",
 			ServerName:      serverName,
 			ClientVersion:   clientVersion,
@@ -695,6 +783,17 @@ func (b defaultMatchMaker) runMatchMaking(rootScope *envelope.Scope, requests []
 		for _, allies := range result.MatchingAllies {
 			matchedTicketCount += len(allies.MatchingParties)
 		}
-		resultChan <- fromMatchResult(result, sourceTickets, ruleSet)
+		match := fromMatchResult(result, sourceTickets, ruleSet)
+
+		metrics.MatchesMade.WithLabelValues(namespace, matchPool).Inc()
+		metrics.PlayersPerMatch.WithLabelValues(namespace, matchPool).Observe(float64(result.CountPlayer()))
+		for _, ticket := range match.Tickets {
+			metrics.ObserveTimeToMatch(namespace, matchPool, ticket.CreatedAt)
+		}
+
+		resultChan <- match
+	}
+	if unmatched := len(requests) - matchedTicketCount; unmatched > 0 {
+		metrics.UnmatchedTickets.WithLabelValues(namespace, matchPool).Add(float64(unmatched))
 	}
 }
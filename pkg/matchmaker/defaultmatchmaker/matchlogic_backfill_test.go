@@ -893,3 +893,79 @@ func TestDefaultMatchMaker_BackfillWithExcludedSessions(t *testing.T) {
 		))
 	})
 }
+
+func backfill1v1Session(playerID string, mmr int) matchmaker.BackfillTicket {
+	return matchmaker.BackfillTicket{
+		TicketID: utils.GenerateUUID(),
+		PartialMatch: matchmaker.Match{
+			MatchAttributes: map[string]interface{}{models.AttributeMemberAttr: map[string]interface{}{"mmr": mmr}},
+			Tickets: []matchmaker.Ticket{{
+				TicketID: utils.GenerateUUID(),
+				Players: []player.PlayerData{{
+					PlayerID: player.IDFromString(playerID), Attributes: map[string]interface{}{"mmr": mmr},
+				}},
+			}},
+			Teams: []matchmaker.Team{{
+				TeamID:  utils.GenerateUUID(),
+				UserIDs: []player.ID{player.IDFromString(playerID)},
+			}},
+			Backfill: true,
+		},
+		MatchSessionID: utils.GenerateUUID(),
+	}
+}
+
+func backfill1v1Ticket(playerID string, mmr int) matchmaker.Ticket {
+	return matchmaker.Ticket{
+		TicketID: utils.GenerateUUID(),
+		Players: []player.PlayerData{{
+			PlayerID:   player.IDFromString(playerID),
+			Attributes: map[string]interface{}{"mmr": mmr},
+		}},
+	}
+}
+
+func TestDefaultMatchMaker_Backfill_MatchesSessionsWithTicketsBeyondTheFirstChunk(t *testing.T) {
+	g := testsetup.ParallelWithGomega(t)
+	mm := New(&config.Config{TicketChunkSize: 1})
+
+	// the first ticket is out of the mmr distance, the session can only take the second one
+	nearTicket := backfill1v1Ticket("near", 10)
+	ticketProvider := testsetup.StubMatchTicketProvider{
+		Tickets:         []matchmaker.Ticket{backfill1v1Ticket("far", 500), nearTicket},
+		BackfillTickets: []matchmaker.BackfillTicket{backfill1v1Session("in-session", 10)},
+	}
+	proposals := mm.BackfillMatches(testsetup.NewTestScope(), ticketProvider, backfill1v1RUles)
+
+	var results []matchmaker.BackfillProposal
+	for proposal := range proposals {
+		results = append(results, proposal)
+	}
+
+	g.Expect(results).To(HaveLen(1))
+	g.Expect(results[0].AddedTickets).To(ConsistOf(nearTicket))
+}
+
+func TestDefaultMatchMaker_Backfill_DoesNotProposeATicketToSeveralSessionChunks(t *testing.T) {
+	g := testsetup.ParallelWithGomega(t)
+	mm := New(&config.Config{TicketChunkSize: 1})
+
+	ticket := backfill1v1Ticket("joining", 10)
+	ticketProvider := testsetup.StubMatchTicketProvider{
+		Tickets: []matchmaker.Ticket{ticket},
+		BackfillTickets: []matchmaker.BackfillTicket{
+			backfill1v1Session("in-session-1", 10),
+			backfill1v1Session("in-session-2", 10),
+			backfill1v1Session("in-session-3", 10),
+		},
+	}
+	proposals := mm.BackfillMatches(testsetup.NewTestScope(), ticketProvider, backfill1v1RUles)
+
+	var results []matchmaker.BackfillProposal
+	for proposal := range proposals {
+		results = append(results, proposal)
+	}
+
+	g.Expect(results).To(HaveLen(1))
+	g.Expect(results[0].AddedTickets).To(ConsistOf(ticket))
+}
@@ -14,12 +14,14 @@ import (
 	"github.com/AccelByte/extend-core-matchmaker/pkg/config"
 	"github.com/AccelByte/extend-core-matchmaker/pkg/matchmaker"
 	"github.com/AccelByte/extend-core-matchmaker/pkg/matchmaker/defaultmatchmaker/basic"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/metrics"
 	"github.com/AccelByte/extend-core-matchmaker/pkg/models"
 	player "github.com/AccelByte/extend-core-matchmaker/pkg/playerdata"
 	"github.com/AccelByte/extend-core-matchmaker/pkg/testsetup"
 	"github.com/AccelByte/extend-core-matchmaker/pkg/utils"
 	"github.com/elliotchance/pie/v2"
 	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 )
 
 func TestDefaultMatchMaker_ClosesChannelWhenWrongTypeOfRulesPassed(t *testing.T) {
@@ -574,3 +576,78 @@ func TestToTeamsConvertion(t *testing.T) {
 
 	g.Expect(result).To(Equal(expectedResult))
 }
+
+func TestDefaultMatchMaker_MakeMatches_RecordsMetrics(t *testing.T) {
+	g := testsetup.ParallelWithGomega(t)
+	mm := newMatchLogic()
+
+	const namespace, matchPool = "metrics-namespace", "metrics-pool"
+	tickets := make([]matchmaker.Ticket, len(basic.SampleFiveSinglePlayerTickets))
+	for i, ticket := range basic.SampleFiveSinglePlayerTickets {
+		ticket.Namespace = namespace
+		ticket.MatchPool = matchPool
+		tickets[i] = ticket
+	}
+
+	matches := mm.MakeMatches(testsetup.NewTestScope(), testsetup.StubMatchTicketProvider{Tickets: tickets}, get1v1Rules())
+	for range matches {
+	}
+
+	g.Expect(testutil.ToFloat64(metrics.TicketsReceived.WithLabelValues(namespace, matchPool))).To(Equal(float64(5)))
+	g.Expect(testutil.ToFloat64(metrics.MatchesMade.WithLabelValues(namespace, matchPool))).To(Equal(float64(2)))
+	g.Expect(testutil.ToFloat64(metrics.UnmatchedTickets.WithLabelValues(namespace, matchPool))).To(Equal(float64(1)))
+	g.Expect(testutil.ToFloat64(metrics.PivotIterations.WithLabelValues(namespace, matchPool))).To(BeNumerically(">", 0))
+}
+
+func TestDefaultMatchMaker_WithFlexing_RecordsFlexActivationsPerMatch(t *testing.T) {
+	g := testsetup.ParallelWithGomega(t)
+	mm := newMatchLogic()
+
+	const namespace, matchPool = "flex-metrics-namespace", "flex-metrics-pool"
+	oneMinuteAgo := time.Now().Add(-1 * time.Minute)
+	var tickets []matchmaker.Ticket
+	for i, mmr := range []int{10, 150, 1000, 5000} {
+		tickets = append(tickets, matchmaker.Ticket{
+			TicketID:  fmt.Sprintf("ticket%d", i),
+			Namespace: namespace,
+			MatchPool: matchPool,
+			CreatedAt: oneMinuteAgo,
+			Players:   []player.PlayerData{{PlayerID: player.ID(fmt.Sprintf("user%d", i)), Attributes: map[string]interface{}{"mmr": mmr}}},
+		})
+	}
+
+	rules := models.RuleSet{
+		AllianceRule: models.AllianceRule{
+			MinNumber:       2,
+			MaxNumber:       2,
+			PlayerMinNumber: 1,
+			PlayerMaxNumber: 1,
+		},
+		MatchingRule: []models.MatchingRule{
+			{
+				Attribute: "mmr",
+				Criteria:  "distance",
+				Reference: float64(100),
+			},
+		},
+		FlexingRule: []models.FlexingRule{
+			{
+				Duration: int64(30),
+				MatchingRule: models.MatchingRule{
+					Attribute: "mmr",
+					Criteria:  "distance",
+					Reference: float64(200),
+				},
+			},
+		},
+	}
+
+	var results []matchmaker.Match
+	for match := range mm.MakeMatches(testsetup.NewTestScope(), testsetup.StubMatchTicketProvider{Tickets: tickets}, rules) {
+		results = append(results, match)
+	}
+
+	// only the first two tickets are within the flexed distance, the pivots that fail to match are not counted
+	g.Expect(results).To(HaveLen(1))
+	g.Expect(testutil.ToFloat64(metrics.FlexActivations.WithLabelValues(namespace, matchPool, metrics.FlexRuleMatching))).To(Equal(float64(1)))
+}
@@ -0,0 +1,287 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package defaultmatchmaker
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/AccelByte/extend-core-matchmaker/pkg/config"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/matchmaker"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/models"
+	player "github.com/AccelByte/extend-core-matchmaker/pkg/playerdata"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/testsetup"
+	. "github.com/onsi/gomega"
+)
+
+func getNestedMMRRules() models.RuleSet {
+	ruleSet := get1v1Rules()
+	ruleSet.AllianceRule.PlayerMaxNumber = 2
+	ruleSet.RegionLatencyMaxMs = 200
+	ruleSet.MatchingRule[0].Attribute = "ranked.mmr"
+	return ruleSet
+}
+
+func TestDefaultMatchMaker_ValidateTicket_NestedAttributePath(t *testing.T) {
+	g := testsetup.ParallelWithGomega(t)
+	mm := newMatchLogic()
+
+	ticket := matchmaker.Ticket{
+		TicketID: "ticket1",
+		Players: []player.PlayerData{
+			{PlayerID: "player1", Attributes: map[string]interface{}{"ranked": map[string]interface{}{"mmr": float64(1000)}}},
+			{PlayerID: "player2", Attributes: map[string]interface{}{"ranked": map[string]interface{}{"mmr": "1200"}}},
+		},
+		Latencies: map[string]int64{"us-east-2": 50},
+	}
+
+	ok, err := mm.ValidateTicket(testsetup.NewTestScope(), ticket, getNestedMMRRules())
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(ok).To(BeTrue())
+}
+
+func TestDefaultMatchMaker_ValidateTicket_MissingAttribute(t *testing.T) {
+	g := testsetup.ParallelWithGomega(t)
+	mm := newMatchLogic()
+
+	ticket := matchmaker.Ticket{
+		TicketID: "ticket1",
+		Players: []player.PlayerData{
+			{PlayerID: "player1", Attributes: map[string]interface{}{"ranked": map[string]interface{}{"mmr": float64(1000)}}},
+			{PlayerID: "player2", Attributes: map[string]interface{}{"ranked": map[string]interface{}{"mmr": "not a number"}}},
+		},
+		Latencies: map[string]int64{"us-east-2": 50},
+	}
+
+	ok, err := mm.ValidateTicket(testsetup.NewTestScope(), ticket, getNestedMMRRules())
+	g.Expect(ok).To(BeFalse())
+	g.Expect(err).To(MatchError(ContainSubstring("player2")))
+	g.Expect(err).To(MatchError(ContainSubstring("ranked.mmr")))
+}
+
+func TestAverageMatchingRuleAttributes_CoercesAndSkipsMissing(t *testing.T) {
+	g := testsetup.ParallelWithGomega(t)
+
+	players := []player.PlayerData{
+		{PlayerID: "player1", Attributes: map[string]interface{}{"ranked": map[string]interface{}{"mmr": int64(1000)}}},
+		{PlayerID: "player2", Attributes: map[string]interface{}{"ranked": map[string]interface{}{"mmr": " 2000 "}}},
+		{PlayerID: "player3", Attributes: map[string]interface{}{}},
+	}
+
+	attributes := avergaeMatchingRuleAttributes(players, getNestedMMRRules())
+	g.Expect(attributes).To(HaveKeyWithValue("ranked.mmr", float64(1500)))
+}
+
+func TestSearchMatchTickets_DistanceWithNumericString(t *testing.T) {
+	g := testsetup.ParallelWithGomega(t)
+	mm := NewMatchMaker(&config.Config{})
+
+	ruleSet := getNestedMMRRules()
+	channel := &models.Channel{Ruleset: ruleSet}
+	pivot := &models.MatchmakingRequest{
+		PartyID:         "party1",
+		PartyMembers:    []models.PartyMember{{UserID: "user1"}},
+		PartyAttributes: map[string]interface{}{memberAttributesKey: map[string]interface{}{"ranked.mmr": "1000"}},
+	}
+	tickets := []models.MatchmakingRequest{
+		{
+			PartyID:         "party2",
+			PartyMembers:    []models.PartyMember{{UserID: "user2"}},
+			PartyAttributes: map[string]interface{}{memberAttributesKey: map[string]interface{}{"ranked.mmr": "1500"}},
+		},
+		{
+			PartyID:         "party3",
+			PartyMembers:    []models.PartyMember{{UserID: "user3"}},
+			PartyAttributes: map[string]interface{}{memberAttributesKey: map[string]interface{}{"ranked.mmr": int32(3000)}},
+		},
+	}
+
+	result := mm.SearchMatchTickets(&ruleSet, &ruleSet, channel, 0, pivot, tickets, nil)
+	g.Expect(result).To(HaveLen(1))
+	g.Expect(result[0].PartyID).To(Equal("party2"))
+}
+
+func TestDefaultMatchMaker_ValidateTicket_Policies(t *testing.T) {
+	t.Parallel()
+
+	newPlayer := func(id string) player.PlayerData {
+		return player.PlayerData{PlayerID: player.ID(id), Attributes: map[string]interface{}{"mmr": float64(1000)}}
+	}
+	baseRules := func() models.RuleSet {
+		ruleSet := get1v1Rules()
+		ruleSet.AllianceRule.PlayerMaxNumber = 2
+		ruleSet.RegionLatencyMaxMs = 100
+		ruleSet.MatchOptions.Options = []models.MatchOption{
+			{Name: "mode", Type: models.MatchOptionTypeAny, AllowedValues: []string{"ranked", "casual"}},
+		}
+		return ruleSet
+	}
+
+	testCases := []struct {
+		name           string
+		ticket         matchmaker.Ticket
+		ruleSet        func() models.RuleSet
+		expectedReason models.TicketValidationReason
+	}{
+		{
+			name: "valid ticket",
+			ticket: matchmaker.Ticket{
+				Players:          []player.PlayerData{newPlayer("a"), newPlayer("b")},
+				TicketAttributes: map[string]interface{}{"mode": []interface{}{"ranked"}},
+				Latencies:        map[string]int64{"us-east-2": 50},
+			},
+			ruleSet: baseRules,
+		},
+		{
+			name: "party too large",
+			ticket: matchmaker.Ticket{
+				Players:   []player.PlayerData{newPlayer("a"), newPlayer("b"), newPlayer("c")},
+				Latencies: map[string]int64{"us-east-2": 50},
+			},
+			ruleSet:        baseRules,
+			expectedReason: models.TicketValidationPartyTooLarge,
+		},
+		{
+			name: "party fits flexed alliance",
+			ticket: matchmaker.Ticket{
+				Players:   []player.PlayerData{newPlayer("a"), newPlayer("b"), newPlayer("c")},
+				Latencies: map[string]int64{"us-east-2": 50},
+			},
+			ruleSet: func() models.RuleSet {
+				ruleSet := baseRules()
+				ruleSet.AllianceFlexingRule = []models.AllianceFlexingRule{
+					{Duration: 30, AllianceRule: models.AllianceRule{MinNumber: 2, MaxNumber: 2, PlayerMinNumber: 1, PlayerMaxNumber: 3}},
+				}
+				return ruleSet
+			},
+		},
+		{
+			name: "duplicate player",
+			ticket: matchmaker.Ticket{
+				Players:   []player.PlayerData{newPlayer("a"), newPlayer("a")},
+				Latencies: map[string]int64{"us-east-2": 50},
+			},
+			ruleSet:        baseRules,
+			expectedReason: models.TicketValidationDuplicatePlayer,
+		},
+		{
+			name: "missing attribute",
+			ticket: matchmaker.Ticket{
+				Players:   []player.PlayerData{{PlayerID: "a"}},
+				Latencies: map[string]int64{"us-east-2": 50},
+			},
+			ruleSet:        baseRules,
+			expectedReason: models.TicketValidationMissingAttribute,
+		},
+		{
+			name: "missing attribute allowed",
+			ticket: matchmaker.Ticket{
+				Players:   []player.PlayerData{{PlayerID: "a"}},
+				Latencies: map[string]int64{"us-east-2": 50},
+			},
+			ruleSet: func() models.RuleSet {
+				ruleSet := baseRules()
+				ruleSet.TicketValidation.RequireMatchingAttributes = models.FALSE()
+				return ruleSet
+			},
+		},
+		{
+			name: "match option not allowed",
+			ticket: matchmaker.Ticket{
+				Players:          []player.PlayerData{newPlayer("a")},
+				TicketAttributes: map[string]interface{}{"mode": []interface{}{"ranked", "hardcore"}},
+				Latencies:        map[string]int64{"us-east-2": 50},
+			},
+			ruleSet:        baseRules,
+			expectedReason: models.TicketValidationMatchOptionNotAllowed,
+		},
+		{
+			name: "too many blocked players",
+			ticket: matchmaker.Ticket{
+				Players:          []player.PlayerData{newPlayer("a")},
+				TicketAttributes: map[string]interface{}{models.AttributeBlocked: []interface{}{"x", "y"}},
+				Latencies:        map[string]int64{"us-east-2": 50},
+			},
+			ruleSet: func() models.RuleSet {
+				ruleSet := baseRules()
+				ruleSet.TicketValidation.MaxBlockedPlayers = 1
+				return ruleSet
+			},
+			expectedReason: models.TicketValidationTooManyBlockedPlayers,
+		},
+		{
+			name: "empty latency with region rule",
+			ticket: matchmaker.Ticket{
+				Players: []player.PlayerData{newPlayer("a")},
+			},
+			ruleSet:        baseRules,
+			expectedReason: models.TicketValidationEmptyLatency,
+		},
+		{
+			name: "empty latency without region rule",
+			ticket: matchmaker.Ticket{
+				Players: []player.PlayerData{newPlayer("a")},
+			},
+			ruleSet: func() models.RuleSet {
+				ruleSet := baseRules()
+				ruleSet.RegionLatencyMaxMs = 0
+				return ruleSet
+			},
+		},
+		{
+			name: "empty latency allowed",
+			ticket: matchmaker.Ticket{
+				Players: []player.PlayerData{newPlayer("a")},
+			},
+			ruleSet: func() models.RuleSet {
+				ruleSet := baseRules()
+				ruleSet.TicketValidation.EmptyLatencyPolicy = models.EmptyLatencyAllow
+				return ruleSet
+			},
+		},
+		{
+			name: "empty latency rejected",
+			ticket: matchmaker.Ticket{
+				Players: []player.PlayerData{newPlayer("a")},
+			},
+			ruleSet: func() models.RuleSet {
+				ruleSet := baseRules()
+				ruleSet.RegionLatencyMaxMs = 0
+				ruleSet.TicketValidation.EmptyLatencyPolicy = models.EmptyLatencyReject
+				return ruleSet
+			},
+			expectedReason: models.TicketValidationEmptyLatency,
+		},
+		{
+			name: "no region latency below max",
+			ticket: matchmaker.Ticket{
+				Players:   []player.PlayerData{newPlayer("a")},
+				Latencies: map[string]int64{"us-east-2": 150},
+			},
+			ruleSet:        baseRules,
+			expectedReason: models.TicketValidationNoRegionLatencyBelowMax,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			g := testsetup.ParallelWithGomega(t)
+			mm := newMatchLogic()
+
+			ok, err := mm.ValidateTicket(testsetup.NewTestScope(), testCase.ticket, testCase.ruleSet())
+			if testCase.expectedReason == "" {
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(ok).To(BeTrue())
+				return
+			}
+
+			g.Expect(ok).To(BeFalse())
+			var validationErr *models.TicketValidationError
+			g.Expect(errors.As(err, &validationErr)).To(BeTrue())
+			g.Expect(validationErr.Reason).To(Equal(testCase.expectedReason))
+		})
+	}
+}
@@ -7,6 +7,7 @@
 package defaultmatchmaker
 
 import (
+	"fmt"
 	"sort"
 	"strings"
 	"time"
@@ -15,9 +16,13 @@ import (
 	"github.com/AccelByte/extend-core-matchmaker/pkg/constants"
 	"github.com/AccelByte/extend-core-matchmaker/pkg/envelope"
 	"github.com/AccelByte/extend-core-matchmaker/pkg/matchmaker"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/metrics"
 	"github.com/AccelByte/extend-core-matchmaker/pkg/models"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/tickhistory"
 	"github.com/AccelByte/extend-core-matchmaker/pkg/utils"
 	reordertool "github.com/AccelByte/extend-core-matchmaker/pkg/utils/reorder-tool"
+
+	"github.com/elliotchance/pie/v2"
 )
 
 // Constants for attribute keys used in matchmaking
@@ -30,6 +35,7 @@ const (
 	userIDKey           = "user_id"                  // Key for user ID
 	latencyMapKey       = "latency_map"              // Key for latency mapping
 	distanceCriteria    = "distance"                 // Key for distance criteria
+	ratingCriteria      = "rating"                   // Key for rating criteria, a distance with a deviation
 	// June 6th, 1983 00:00:00 - Date used to force flexing rules
 	dateToForceFlexingRule = 423792000
 )
@@ -42,6 +48,7 @@ var _ matchmaker.Matchmaker = (*MatchMaker)(nil)
 type MatchMaker struct {
 	cfg              *config.Config // Configuration for the matchmaker
 	isMatchAnyCommon bool           // Flag to enable matching any common attributes
+	timeLimit        time.Duration  // Overrides the match time limit when set
 }
 
 // NewMatchMaker creates a new instance of the MatchMaker with the given configuration.
@@ -54,9 +61,46 @@ func NewMatchMaker(cfg *config.Config) *MatchMaker {
 
 // MatchPlayers tries to match as many request as possible.
 // This is the main entry point for player matchmaking operations.
+// When MATCH_OPTIMIZATION_BUDGET_MS is set, the matches go through optimizeMatches within the remaining match time limit.
+func (mm *MatchMaker) MatchPlayers(rootScope *envelope.Scope, namespace string, matchPool string, matchmakingRequests []models.MatchmakingRequest, channel models.Channel) ([]*models.MatchmakingResult, []models.MatchmakingRequest, error) {
+	if mm.cfg == nil || mm.cfg.MatchOptimizationBudgetMs <= 0 {
+		return mm.matchPlayers(rootScope, namespace, matchPool, matchmakingRequests, channel)
+	}
+
+	// matchPlayers sorts and shrinks the requests
+	requests := append([]models.MatchmakingRequest(nil), matchmakingRequests...)
+	startTime := time.Now()
+	results, satisfiedTickets, err := mm.matchPlayers(rootScope, namespace, matchPool, matchmakingRequests, channel)
+	if err != nil || len(results) == 0 {
+		return results, satisfiedTickets, err
+	}
+
+	budget := time.Duration(mm.cfg.MatchOptimizationBudgetMs) * time.Millisecond
+	if remaining := mm.matchTimeLimit() - time.Since(startTime); remaining < budget {
+		budget = remaining
+	}
+	if budget <= 0 {
+		return results, satisfiedTickets, nil
+	}
+
+	return mm.optimizeMatches(rootScope, namespace, matchPool, requests, results, satisfiedTickets, channel, time.Now().Add(budget))
+}
+
+// matchTimeLimit returns how long matchPlayers can look for matches.
+func (mm *MatchMaker) matchTimeLimit() time.Duration {
+	if mm.timeLimit > 0 {
+		return mm.timeLimit
+	}
+	if mm.cfg != nil && mm.cfg.MatchTimeLimitSecond > 0 {
+		return time.Duration(mm.cfg.MatchTimeLimitSecond) * time.Second
+	}
+	return (constants.PoolLockTimeLimit * 2) / 5
+}
+
+// matchPlayers matches the requests greedily, every pivot takes the best candidates left by the previous pivots.
 //
 //nolint:gocyclo
-func (mm *MatchMaker) MatchPlayers(rootScope *envelope.Scope, namespace string, matchPool string, matchmakingRequests []models.MatchmakingRequest, channel models.Channel) ([]*models.MatchmakingResult, []models.MatchmakingRequest, error) {
+func (mm *MatchMaker) matchPlayers(rootScope *envelope.Scope, namespace string, matchPool string, matchmakingRequests []models.MatchmakingRequest, channel models.Channel) ([]*models.MatchmakingResult, []models.MatchmakingRequest, error) {
 	scope := rootScope.NewChildScope("MatchMaker.MatchPlayers")
 	defer scope.Finish()
 
@@ -66,6 +110,10 @@ func (mm *MatchMaker) MatchPlayers(rootScope *envelope.Scope, namespace string,
 		pivotMatchingCounter    int // Counter for pivot-based matching attempts
 		findMatchingAllyCounter int // Counter for ally finding attempts
 	)
+	defer func() {
+		metrics.PivotIterations.WithLabelValues(namespace, matchPool).Add(float64(pivotMatchingCounter))
+		metrics.FindMatchingAllyAttempts.WithLabelValues(namespace, matchPool).Add(float64(findMatchingAllyCounter))
+	}()
 
 	// Early return if no requests to process
 	if len(matchmakingRequests) == 0 {
@@ -85,6 +133,7 @@ func (mm *MatchMaker) MatchPlayers(rootScope *envelope.Scope, namespace string,
 
 	// Check if there are enough requests to form a match
 	if len(matchmakingRequests) < allianceComposition.MinTeam && !isUsingAllianceFlexing {
+		recordUnmatched(scope, matchmakingRequests, "not_enough_tickets")
 		return nil, nil, nil
 	}
 
@@ -94,7 +143,7 @@ func (mm *MatchMaker) MatchPlayers(rootScope *envelope.Scope, namespace string,
 		playerCount += len(mmRequest.PartyMembers)
 	}
 	if playerCount < allianceComposition.MinTotalPlayer() && !isUsingAllianceFlexing {
-
+		recordUnmatched(scope, matchmakingRequests, "not_enough_players")
 		return nil, nil, nil
 	}
 
@@ -106,50 +155,105 @@ func (mm *MatchMaker) MatchPlayers(rootScope *envelope.Scope, namespace string,
 
 	// Set up timeout safeguard for pool lock
 	startTime := time.Now()
-	timeLimit := (constants.PoolLockTimeLimit * 2) / 5
-	if mm.cfg != nil && mm.cfg.MatchTimeLimitSecond > 0 {
-		timeLimit = time.Duration(mm.cfg.MatchTimeLimitSecond) * time.Second
-	}
+	timeLimit := mm.matchTimeLimit()
 
 	batchResult := make([]*models.MatchmakingResult, 0)
 
+	// Pick the pivots by the pivot strategy of the ruleset, skipping the tickets that failed as a pivot recently
+	sortOldestFirst(matchmakingRequests)
+	pivots := newPivotSelector(&channel, matchmakingRequests)
+	if cooldownCount := pivots.cooldownCount(matchmakingRequests); cooldownCount > 0 {
+		metrics.PivotCooldownSkips.WithLabelValues(namespace, matchPool).Add(float64(cooldownCount))
+	}
+	scope.SetAttributes("pivot_strategy", string(pivots.strategy))
+
+	// Every pivot attempt and region attempt gets its own span, the outcome is recorded when the attempt ends
+	var (
+		pivotScope    *envelope.Scope
+		pivotOutcome  string
+		regionScope   *envelope.Scope
+		regionOutcome string
+	)
+	finishRegionScope := func() {
+		if regionScope != nil {
+			regionScope.SetAttributes("outcome", regionOutcome)
+			regionScope.Finish()
+			regionScope = nil
+		}
+	}
+	finishPivotScope := func() {
+		finishRegionScope()
+		if pivotScope != nil {
+			pivotScope.SetAttributes("outcome", pivotOutcome)
+			pivotScope.Finish()
+			pivotScope = nil
+		}
+	}
+	defer finishPivotScope()
+
 pivotMatching:
 	pivotMatchingCounter++
+	finishPivotScope()
 	scope.Log.Debugf("executing %d requests on local pool", len(matchmakingRequests))
 	scope.Log.WithField("matchmakingRequests", matchmakingRequests).Debug("incoming requests")
 
-	// Sort the ticket before choosing a pivot, so the pivot ticket is always the oldest
+	// Sort the ticket before choosing a pivot, so the strategies break their ties by age
 	sortOldestFirst(matchmakingRequests)
 
-	pivotRequest := matchmakingRequests[0]
+	pivotIndex, pivotFound := pivots.next(matchmakingRequests)
+	if !pivotFound {
+		// Every ticket left is on cooldown, the pivots of the next ticks can still match them
+		recordUnmatched(scope, matchmakingRequests, "pivot_cooldown")
+		return batchResult, satisfiedTickets, nil
+	}
+	pivotRequest := matchmakingRequests[pivotIndex]
 	pivotTimeStampRequest := time.Unix(pivotRequest.CreatedAt, 0)
 
 	// Determine if rule needs flexing based on pivot ticket age
-	activeRulesetBefore, _ := applyRuleFlexing(ruleset, pivotTimeStampRequest)
-	activeRuleset, _ := applyAllianceFlexingRules(activeRulesetBefore, pivotTimeStampRequest)
-
+	activeRulesetBefore, isRuleFlexed := applyRuleFlexing(ruleset, pivotTimeStampRequest)
+	activeRuleset, isAllianceFlexed := applyAllianceFlexingRules(activeRulesetBefore, pivotTimeStampRequest)
+
+	pivotScope = scope.NewChildScope("MatchMaker.MatchPlayers.pivot")
+	pivotOutcome = "unmatched"
+	pivotScope.SetAttributes("pivot_id", pivotRequest.PartyID)
+	pivotScope.SetAttributes("pivot_age_seconds", time.Since(pivotTimeStampRequest).Seconds())
+	pivotScope.SetAttributes("pivot_party_size", len(pivotRequest.PartyMembers))
+	pivotScope.SetAttributes("flexed", isRuleFlexed)
+	pivotScope.SetAttributes("alliance_flexed", isAllianceFlexed)
+	pivotScope.SetAttributes("remaining_requests", len(matchmakingRequests))
 	scope.Log.WithField("ruleset", activeRuleset).Debug("ruleset applied")
 
 	allianceComposition = DetermineAllianceComposition(activeRuleset)
 
 	// Determine how many regions should this request be matchmaked based on
 	// number of regions in the request and attempt count
-	filteredRegion := filterRegionByStep(&pivotRequest, &channel)
+	filteredRegion := weightedRegionWindow(&pivotRequest, &channel)
 	regionsToTry := len(filteredRegion)
 	if regionsToTry == 0 {
 		regionsToTry = 1
 	}
+	pivotScope.SetAttributes("regions_to_try", regionsToTry)
 
 regionloop:
 	for regionIndex := 0; regionIndex < regionsToTry; regionIndex++ {
+		finishRegionScope()
+		regionScope = pivotScope.NewChildScope("MatchMaker.MatchPlayers.region")
+		regionOutcome = "no_match"
+		regionScope.SetAttributes("region_index", regionIndex)
+		if regionIndex < len(filteredRegion) {
+			regionScope.SetAttributes("region", filteredRegion[regionIndex].Region)
+		}
+
 		// Make sure pivot request is usable
 		if len(pivotRequest.PartyMembers) == 0 {
+			regionOutcome = "empty_pivot"
 			break
 		}
 
 		// Search for matching tickets using manual search algorithm
 		// [MANUALSEARCH]
 		result := mm.SearchMatchTickets(&ruleset, &activeRuleset, &channel, regionIndex, &pivotRequest, matchmakingRequests, filteredRegion)
+		regionScope.SetAttributes("candidate_count", len(result))
 
 		var mmRequests []models.MatchmakingRequest
 		playerCount = 0
@@ -157,6 +261,7 @@ regionloop:
 		// Insert the pivot request as the first candidate
 		req := getMatchmakingRequest(pivotRequest.PartyID, matchmakingRequests)
 		if req == nil {
+			regionOutcome = "pivot_not_found"
 			continue
 		}
 		mmRequests = append(mmRequests, *req)
@@ -179,11 +284,13 @@ regionloop:
 
 		// Don't bother finding ally if number of tickets cannot form minimum teams
 		if len(mmRequests) < allianceComposition.MinTeam {
+			regionOutcome = "not_enough_candidates"
 			continue
 		}
 
 		// Don't bother finding ally if number of matched players is less than minimum needed
 		if playerCount < allianceComposition.MinTotalPlayer() {
+			regionOutcome = "not_enough_players"
 			continue
 		}
 
@@ -197,8 +304,9 @@ regionloop:
 		var matchingAllies []models.MatchingAlly
 
 		{
-			matchingAllies, _ = findMatchingAlly(
-				scope,
+			var allyErr error
+			matchingAllies, _, allyErr = findMatchingAlly(
+				regionScope,
 				mm.cfg,
 				mmRequests,
 				pivotRequest,
@@ -208,7 +316,12 @@ regionloop:
 			)
 
 			findMatchingAllyCounter++
+			regionScope.SetAttributes("ally_count", len(matchingAllies))
+			if allyErr != nil {
+				regionScope.SetAttributes("ally_error", allyErr.Error())
+			}
 		}
+		regionOutcome = "no_allies"
 
 		// If we found enough allies to form a match
 		if len(matchingAllies) >= allianceComposition.MinTeam {
@@ -218,8 +331,8 @@ regionloop:
 
 			// Get the matched region (if any)
 			region := ""
-			if len(pivotRequest.SortedLatency) > 0 && regionIndex < len(pivotRequest.SortedLatency) {
-				region = pivotRequest.SortedLatency[regionIndex].Region
+			if regionIndex < len(filteredRegion) {
+				region = filteredRegion[regionIndex].Region
 			}
 
 			// Filter based on optional match, skip if does not make sense
@@ -265,6 +378,7 @@ regionloop:
 					}
 					for val, count := range option {
 						if partyCount != count {
+							regionOutcome = "match_option_mismatch"
 							continue regionloop
 						}
 						selectedOptions[name] = append(selectedOptions[name], val)
@@ -293,12 +407,14 @@ regionloop:
 					}
 
 					if len(selectedOptions) == 0 {
+						regionOutcome = "match_option_mismatch"
 						continue regionloop
 					}
 				case models.MatchOptionTypeUnique:
 					// Fail if there's any common option
 					for val, count := range option {
 						if count > 1 {
+							regionOutcome = "match_option_mismatch"
 							continue regionloop
 						}
 						selectedOptions[name] = append(selectedOptions[name], val)
@@ -306,6 +422,33 @@ regionloop:
 				}
 			}
 
+			// Choose the region from the latencies of every matched ticket, the searched region is the fallback
+			var matchedRequests []models.MatchmakingRequest
+			for _, ally := range matchingAllies {
+				for _, party := range ally.MatchingParties {
+					if req := getMatchmakingRequest(party.PartyID, matchmakingRequests); req != nil {
+						matchedRequests = append(matchedRequests, *req)
+					}
+				}
+			}
+			regionPreference := selectMatchRegions(pivotRequest, matchedRequests, &channel, region)
+			if len(regionPreference) > 0 {
+				region = regionPreference[0]
+			}
+			regionScope.SetAttributes("selected_region", region)
+
+			// Score the match, a match under the minimum score of the pivot age waits for a better one
+			var qualityScore matchQualityScore
+			if ruleset.MatchQuality.IsEnabled() {
+				qualityScore = scoreMatch(&ruleset, matchingAllies, matchedRequests, region)
+				regionScope.SetAttributes("quality_score", qualityScore.score)
+				if qualityScore.score < ruleset.MatchQuality.GetMinScore(Now().Sub(pivotTimeStampRequest)) {
+					regionOutcome = "low_quality"
+					continue regionloop
+				}
+			}
+			channel.RegionMatches.Add(region)
+
 			// Combine party attributes into session attributes
 			attributes := make(map[string]interface{})
 
@@ -405,26 +548,40 @@ regionloop:
 				}
 			}
 
+			if ruleset.MatchQuality.IsEnabled() {
+				attributes[models.AttributeMatchQuality] = qualityScore.attributes()
+			}
+
 			// Create the matchmaking result
 			mmResults = append(mmResults, &models.MatchmakingResult{
-				Status:          models.MatchmakingStatusDone,
-				MatchID:         matchID,
-				Channel:         channelSlug,
-				Namespace:       getNamespace(channelSlug),
-				GameMode:        getGameMode(channelSlug),
-				ServerName:      serverName,
-				ClientVersion:   clientVersion,
-				Region:          region,
-				MatchingAllies:  matchingAllies,
-				PartyAttributes: attributes,
-				UpdatedAt:       time.Now(),
-				PivotID:         pivotRequest.PartyID,
+				Status:           models.MatchmakingStatusDone,
+				MatchID:          matchID,
+				Channel:          channelSlug,
+				Namespace:        getNamespace(channelSlug),
+				GameMode:         getGameMode(channelSlug),
+				ServerName:       serverName,
+				ClientVersion:    clientVersion,
+				Region:           region,
+				RegionPreference: regionPreference,
+				MatchingAllies:   matchingAllies,
+				PartyAttributes:  attributes,
+				UpdatedAt:        time.Now(),
+				PivotID:          pivotRequest.PartyID,
 			})
 		}
 
 		// If we found matches, add them to batch results and continue with remaining tickets
 		if len(mmResults) != 0 {
+			regionOutcome = "matched"
+			pivotOutcome = "matched"
+			pivotScope.SetAttributes("match_id", mmResults[0].MatchID)
 			batchResult = append(batchResult, mmResults...)
+			if isRuleFlexed {
+				metrics.FlexActivations.WithLabelValues(namespace, matchPool, metrics.FlexRuleMatching).Add(float64(len(mmResults)))
+			}
+			if isAllianceFlexed {
+				metrics.FlexActivations.WithLabelValues(namespace, matchPool, metrics.FlexRuleAlliance).Add(float64(len(mmResults)))
+			}
 			if len(matchmakingRequests) > 0 && len(matchmakingRequests) >= allianceComposition.MinTeam {
 				goto pivotMatching
 			}
@@ -434,6 +591,8 @@ regionloop:
 		}
 	}
 
+	finishRegionScope()
+
 	// Handle timeout and cleanup of unmatchable tickets
 	elapsed := time.Since(startTime)
 	reqLen := len(matchmakingRequests)
@@ -441,6 +600,21 @@ regionloop:
 	for _, mmRequest := range matchmakingRequests {
 		playerCount += len(mmRequest.PartyMembers)
 	}
+	if reqLen > 0 && elapsed >= timeLimit {
+		scope.Log.WithField("elapsed", elapsed).WithField("remainingRequests", reqLen).Warn("match time limit reached")
+		metrics.MatchTimeouts.WithLabelValues(namespace, matchPool).Inc()
+		if pivotOutcome != "matched" {
+			pivotOutcome = "timeout"
+		}
+	}
+	switch pivotOutcome {
+	case "matched":
+	case "timeout":
+		tickhistory.RecordUnmatched(scope.Ctx, pivotRequest.PartyID, pivotOutcome)
+	default:
+		tickhistory.RecordUnmatched(scope.Ctx, pivotRequest.PartyID, regionOutcome)
+		channel.PivotCooldowns.Fail(pivotRequest.PartyID, ruleset.PivotSelection.CooldownTicks)
+	}
 	if reqLen > 0 && reqLen >= allianceComposition.MinTeam && !(playerCount < allianceComposition.MinTotalPlayer() && !isUsingAllianceFlexing) && elapsed < timeLimit {
 		// Remove the unmatchable ticket from the queue
 		// Optimize selecting next pivot in case of unmatchable ticket found
@@ -472,9 +646,23 @@ regionloop:
 		remainingPlayers[i] = req.CountPlayer()
 	}
 
+	// The remaining tickets were never tried as a pivot
+	remainingReason := "not_enough_tickets"
+	if elapsed >= timeLimit {
+		remainingReason = "timeout"
+	}
+	recordUnmatched(scope, matchmakingRequests, remainingReason)
+
 	return batchResult, satisfiedTickets, nil
 }
 
+// recordUnmatched records why the requests were not matched for the tick history
+func recordUnmatched(scope *envelope.Scope, matchmakingRequests []models.MatchmakingRequest, reason string) {
+	for _, req := range matchmakingRequests {
+		tickhistory.RecordUnmatched(scope.Ctx, req.PartyID, reason)
+	}
+}
+
 // handleSinglePlayer handles matchmaking for single-player scenarios (1v1 or similar).
 // This function creates individual matches for each single player request.
 func (mm *MatchMaker) handleSinglePlayer(scope *envelope.Scope, namespace string, matchPool string, matchmakingRequests []models.MatchmakingRequest, channel models.Channel) ([]*models.MatchmakingResult, []models.MatchmakingRequest, error) {
@@ -498,24 +686,32 @@ func (mm *MatchMaker) handleSinglePlayer(scope *envelope.Scope, namespace string
 
 		// Get region preference
 		region := ""
-		if len(req.SortedLatency) > 0 {
+		if window := weightedRegionWindow(&req, &channel); len(window) > 0 {
+			region = window[0].Region
+		} else if len(req.SortedLatency) > 0 {
 			region = req.SortedLatency[0].Region
 		}
+		regionPreference := selectMatchRegions(req, nil, &channel, region)
+		if len(regionPreference) > 0 {
+			region = regionPreference[0]
+		}
+		channel.RegionMatches.Add(region)
 
 		// Create matchmaking result for this single player
 		mmResults = append(mmResults, &models.MatchmakingResult{
-			Status:          models.MatchmakingStatusDone,
-			MatchID:         utils.GenerateUUID(),
-			Channel:         channelSlug,
-			Namespace:       getNamespace(channelSlug),
-			GameMode:        getGameMode(channelSlug),
-			PartyAttributes: req.PartyAttributes,
-			MatchingAllies:  []models.MatchingAlly{team},
-			ServerName:      serverName,
-			ClientVersion:   clientVersion,
-			Region:          region,
-			UpdatedAt:       time.Now(),
-			PivotID:         req.PartyID,
+			Status:           models.MatchmakingStatusDone,
+			MatchID:          utils.GenerateUUID(),
+			Channel:          channelSlug,
+			Namespace:        getNamespace(channelSlug),
+			GameMode:         getGameMode(channelSlug),
+			PartyAttributes:  req.PartyAttributes,
+			MatchingAllies:   []models.MatchingAlly{team},
+			ServerName:       serverName,
+			ClientVersion:    clientVersion,
+			Region:           region,
+			RegionPreference: regionPreference,
+			UpdatedAt:        time.Now(),
+			PivotID:          req.PartyID,
 		})
 	}
 
@@ -524,6 +720,7 @@ func (mm *MatchMaker) handleSinglePlayer(scope *envelope.Scope, namespace string
 
 // findMatchingAlly attempts to find matching allies for a pivot ticket.
 // This function uses a reordering algorithm to find optimal team combinations.
+// When no combination is found, it returns the validation error of the last tried combination.
 func findMatchingAlly(
 	rootScope *envelope.Scope,
 	config *config.Config,
@@ -532,10 +729,21 @@ func findMatchingAlly(
 	allianceRule models.AllianceRule,
 	matchingRules []models.MatchingRule,
 	blockedPlayerOption models.BlockedPlayerOption,
-) ([]models.MatchingAlly, []models.MatchmakingRequest) {
+) ([]models.MatchingAlly, []models.MatchmakingRequest, error) {
 	scope := rootScope.NewChildScope("findMatchingAlly")
 	defer scope.Finish()
 
+	if isLargeLobby(config, allianceRule) {
+		scope.SetAttributes("large_lobby", true)
+		allies, tickets, err := findLargeLobbyAllies(sourceTickets, pivotTicket, allianceRule, blockedPlayerOption)
+		if err != nil {
+			scope.SetAttributes("ally_error", err.Error())
+		}
+		return allies, tickets, err
+	}
+
+	var validationErr error
+
 	// Get pivot index and set up reordering
 	pivotIndex := getPivotTicketIndexFromTickets(sourceTickets, &pivotTicket)
 	elementsAlwaysFirst := []int{pivotIndex}
@@ -561,28 +769,33 @@ func findMatchingAlly(
 
 		var ticketsPerTeam [][]models.MatchmakingRequest
 
-		// Calculate minimum member number across all tickets
-		minMemberNumber := allianceRule.PlayerMaxNumber
-		for _, ticket := range tickets {
-			memberCount := len(ticket.PartyMembers)
-			if minMemberNumber > len(ticket.PartyMembers) {
-				minMemberNumber = memberCount
+		// Calculate minimum member number across all tickets of each team
+		playerMaxNumbers := make([]int, minAllyCount)
+		for i := range playerMaxNumbers {
+			team := allianceRule.GetTeamRule(i)
+			minMemberNumber := team.PlayerMaxNumber
+			for _, ticket := range tickets {
+				memberCount := len(ticket.PartyMembers)
+				if minMemberNumber > memberCount && team.Accepts(ticket.PartyAttributes) {
+					minMemberNumber = memberCount
+				}
 			}
-		}
 
-		playerMaxNumber := allianceRule.PlayerMinNumber
-		if playerMaxNumber < minMemberNumber {
-			playerMaxNumber = minMemberNumber
+			playerMaxNumbers[i] = team.PlayerMinNumber
+			if playerMaxNumbers[i] < minMemberNumber {
+				playerMaxNumbers[i] = minMemberNumber
+			}
 		}
 
 		// Step 1: Create a match with min team & min players
 		for i := 0; i < minAllyCount; i++ {
+			team := allianceRule.GetTeamRule(i)
 			matchedTickets := FindPartyCombination(
 				config,
-				tickets,
+				ticketsForPremadeSize(allianceRule, i, ticketsPerTeam, ticketsForTeam(team, tickets)),
 				pivotTicket,
-				allianceRule.PlayerMinNumber,
-				playerMaxNumber,
+				team.PlayerMinNumber,
+				playerMaxNumbers[i],
 				nil,
 				blockedPlayerOption,
 			)
@@ -606,12 +819,13 @@ func findMatchingAlly(
 				curTeamTickets = ticketsPerTeam[i]
 			}
 
+			team := allianceRule.GetTeamRule(i)
 			matchedTickets := FindPartyCombination(
 				config,
-				tickets,
+				ticketsForPremadeSize(allianceRule, i, ticketsPerTeam, ticketsForTeam(team, tickets)),
 				pivotTicket,
-				allianceRule.PlayerMinNumber,
-				allianceRule.PlayerMaxNumber,
+				team.PlayerMinNumber,
+				team.PlayerMaxNumber,
 				curTeamTickets,
 				blockedPlayerOption,
 			)
@@ -655,12 +869,92 @@ func findMatchingAlly(
 
 		// Check if these alliances can be used to fill a session
 		if err := allianceRule.ValidateAllies(teams, blockedPlayerOption); err != nil {
+			validationErr = err
 			continue
 		}
-		return teams, tickets
+		// The pivot is always first in a symmetric match, but it can miss every team it is allowed in of an asymmetric one
+		if allianceRule.IsAsymmetric() && !isPartyInAllies(pivotTicket.PartyID, teams) {
+			validationErr = fmt.Errorf("pivot ticket %s has no team", pivotTicket.PartyID)
+			continue
+		}
+		return teams, tickets, nil
 	}
 
-	return nil, nil
+	if validationErr != nil {
+		scope.SetAttributes("ally_error", validationErr.Error())
+	}
+	return nil, nil, validationErr
+}
+
+// ticketsForTeam returns the tickets that meet the requirement of a team.
+func ticketsForTeam(team models.TeamRule, tickets []models.MatchmakingRequest) []models.MatchmakingRequest {
+	if team.Attribute == "" {
+		return tickets
+	}
+	return pie.Filter(tickets, func(ticket models.MatchmakingRequest) bool {
+		return team.Accepts(ticket.PartyAttributes)
+	})
+}
+
+// ticketsForPremadeSize returns the tickets a team can take under the max premade size difference of the alliance rule,
+// against the largest party of the other teams found so far. The tickets of a party too large are left out,
+// and when the team has no party large enough yet, the tickets of the parties large enough go first.
+func ticketsForPremadeSize(allianceRule models.AllianceRule, teamIndex int, ticketsPerTeam [][]models.MatchmakingRequest, tickets []models.MatchmakingRequest) []models.MatchmakingRequest {
+	if allianceRule.MaxPremadeSizeDifference == nil {
+		return tickets
+	}
+	maxDifference := *allianceRule.MaxPremadeSizeDifference
+
+	smallest, largest, current := -1, -1, 0
+	for i, teamTickets := range ticketsPerTeam {
+		size := 0
+		for _, ticket := range teamTickets {
+			if ticket.CountPlayer() > size {
+				size = ticket.CountPlayer()
+			}
+		}
+		if i == teamIndex {
+			current = size
+			continue
+		}
+		if size == 0 {
+			continue
+		}
+		if smallest < 0 || size < smallest {
+			smallest = size
+		}
+		if size > largest {
+			largest = size
+		}
+	}
+	if largest < 0 {
+		return tickets
+	}
+
+	allowed := pie.Filter(tickets, func(ticket models.MatchmakingRequest) bool {
+		return ticket.CountPlayer() <= smallest+maxDifference
+	})
+	if current >= largest-maxDifference {
+		return allowed
+	}
+	largeEnough := pie.Filter(allowed, func(ticket models.MatchmakingRequest) bool {
+		return ticket.CountPlayer() >= largest-maxDifference
+	})
+	return append(largeEnough, pie.Filter(allowed, func(ticket models.MatchmakingRequest) bool {
+		return ticket.CountPlayer() < largest-maxDifference
+	})...)
+}
+
+// isPartyInAllies returns true when one of the allies has the party.
+func isPartyInAllies(partyID string, allies []models.MatchingAlly) bool {
+	for _, ally := range allies {
+		for _, party := range ally.MatchingParties {
+			if party.PartyID == partyID {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 // FindPartyCombination finds the optimal combination of parties for a team.
@@ -675,7 +969,11 @@ func FindPartyCombination(
 	blockedPlayerOption models.BlockedPlayerOption,
 ) []models.MatchmakingRequest {
 	// Define the partyFinder based on player requirements
-	pf := GetPartyFinder(minPlayer, maxPlayer, current)
+	strategy := PartyFinderStrategyGreedy
+	if config != nil {
+		strategy = config.PartyFinderStrategy
+	}
+	pf := GetPartyFinderWithStrategy(strategy, pivotTicket.PartyID, minPlayer, maxPlayer, current)
 
 	// Get pivot index and priority indexes for reordering
 	pivotIndex := getPivotTicketIndexFromTickets(sourceTickets, &pivotTicket)
@@ -690,8 +988,9 @@ func FindPartyCombination(
 	}
 
 	// Set up reorder tool with configuration
+	// The exact party finder considers every combination of the tickets, another order won't fill more
 	maxLoop := 1
-	if config != nil && config.FindPartyMaxLoop > 0 {
+	if config != nil && config.FindPartyMaxLoop > 0 && strategy != PartyFinderStrategyExact {
 		maxLoop = config.FindPartyMaxLoop
 	}
 	r := reordertool.NewTwoPointerByLength(len(sourceTickets))
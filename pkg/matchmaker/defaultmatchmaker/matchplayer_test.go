@@ -21,6 +21,8 @@ import (
 	"github.com/AccelByte/extend-core-matchmaker/pkg/constants"
 	"github.com/AccelByte/extend-core-matchmaker/pkg/envelope"
 	"github.com/AccelByte/extend-core-matchmaker/pkg/models"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/testsetup"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/tickhistory"
 	"github.com/AccelByte/extend-core-matchmaker/pkg/utils"
 	"github.com/caarlos0/env"
 	"github.com/davecgh/go-spew/spew"
@@ -29,6 +31,8 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 var (
@@ -767,6 +771,55 @@ func TestMatchmakerAllyFindingFail_NotEnoughMatchedPlayers(t *testing.T) {
 	assert.Truef(t, len(results) == 0, "unexpected matchmaking result count. expected: %d, actual: %d", 0, len(results))
 }
 
+func TestMatchmaker_RecordsUnmatchedReason(t *testing.T) {
+	t.Parallel()
+	scope := envelope.NewRootScope(context.Background(), "TestMatchmaker_RecordsUnmatchedReason", "")
+	t.Cleanup(func() { scope.Finish() })
+
+	channelName := "moba:3v3"
+	matchmaker := NewMatchmaker()
+	mmRequest := generateRequestWithMMR(channelName, 1, 3, 10)
+	mmRequest = append(mmRequest, generateRequestWithMMR(channelName, 1, 3, 1000)...)
+
+	history := tickhistory.New(1)
+	recorder := history.Start(tickhistory.KindMakeMatches, "{}")
+	for _, req := range mmRequest {
+		recorder.Ticket("", channelName, req.PartyID)
+	}
+	scope.Ctx = tickhistory.WithRecorder(scope.Ctx, recorder)
+
+	channel := models.Channel{
+		Ruleset: models.RuleSet{
+			AllianceRule: models.AllianceRule{
+				MinNumber:       2,
+				MaxNumber:       2,
+				PlayerMinNumber: 3,
+				PlayerMaxNumber: 3,
+			},
+			MatchingRule: []models.MatchingRule{
+				{
+					Attribute: "mmr",
+					Criteria:  "distance",
+					Reference: float64(50),
+				},
+			},
+		},
+	}
+	results, _, err := matchmaker.MatchPlayers(scope, "", "", mmRequest, channel)
+	require.NoError(t, err)
+	require.Empty(t, results)
+	recorder.Finish()
+
+	// the mmr of the tickets are too far apart, so neither pivot finds a candidate
+	pools := history.Pools("", channelName)
+	require.Len(t, pools, 1)
+	reasons := make([]string, 0)
+	for _, unmatched := range pools[0].Ticks[0].Unmatched {
+		reasons = append(reasons, unmatched.Reason)
+	}
+	assert.Equal(t, []string{"not_enough_candidates", "not_enough_candidates"}, reasons)
+}
+
 // nolint: dupl
 func TestMatchmakerAllyFillingSuccess(t *testing.T) {
 	t.Parallel()
@@ -1110,6 +1163,73 @@ func TestMatchmaker1v1WithLatencySuccess(t *testing.T) {
 	assert.Contains(t, results[0].MatchingAllies[1].MatchingParties[0].PartyAttributes[models.AttributeLatencies], "us", "region should be US")
 }
 
+func TestMatchPlayers_PivotAndRegionSpans(t *testing.T) {
+	t.Parallel()
+	exporter := tracetest.NewInMemoryExporter()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	scope := testsetup.NewTestScopeWithTracerProvider(tracerProvider)
+
+	mmRequests := generateRequest("chess:duel", 3, 1)
+	// the oldest ticket is the first pivot, it has no other ticket in its region
+	for i, region := range []string{"eu", "us", "us"} {
+		mmRequests[i].CreatedAt = time.Now().Add(time.Duration(i-3) * time.Minute).Unix()
+		mmRequests[i].LatencyMap = map[string]int{region: 100}
+		mmRequests[i].SortedLatency = []models.Region{{Region: region, Latency: 100}}
+	}
+	channel := models.Channel{
+		Ruleset: models.RuleSet{
+			AllianceRule: models.AllianceRule{
+				MinNumber:       2,
+				MaxNumber:       2,
+				PlayerMinNumber: 1,
+				PlayerMaxNumber: 1,
+			},
+		},
+	}
+
+	results, _, err := NewMatchmaker().MatchPlayers(scope, "", "", mmRequests, channel)
+	scope.Finish()
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+
+	var pivotSpans, regionSpans []tracetest.SpanStub
+	for _, span := range exporter.GetSpans() {
+		switch span.Name {
+		case "MatchMaker.MatchPlayers.pivot":
+			pivotSpans = append(pivotSpans, span)
+		case "MatchMaker.MatchPlayers.region":
+			regionSpans = append(regionSpans, span)
+		}
+	}
+	spanAttributes := func(span tracetest.SpanStub) map[string]interface{} {
+		attributes := make(map[string]interface{})
+		for _, attribute := range span.Attributes {
+			attributes[string(attribute.Key)] = attribute.Value.AsInterface()
+		}
+		return attributes
+	}
+
+	require.Len(t, pivotSpans, 2)
+	require.Len(t, regionSpans, 2)
+	for i := range pivotSpans {
+		assert.Equal(t, pivotSpans[i].SpanContext.SpanID(), regionSpans[i].Parent.SpanID(), "region span should be a child of its pivot span")
+	}
+
+	unmatchedPivot, unmatchedRegion := spanAttributes(pivotSpans[0]), spanAttributes(regionSpans[0])
+	assert.Equal(t, mmRequests[0].PartyID, unmatchedPivot["pivot_id"])
+	assert.Equal(t, "unmatched", unmatchedPivot["outcome"])
+	assert.Equal(t, "eu", unmatchedRegion["region"])
+	assert.Equal(t, "not_enough_candidates", unmatchedRegion["outcome"])
+	assert.NotContains(t, unmatchedRegion, "selected_region")
+
+	matchedPivot, matchedRegion := spanAttributes(pivotSpans[1]), spanAttributes(regionSpans[1])
+	assert.Equal(t, mmRequests[1].PartyID, matchedPivot["pivot_id"])
+	assert.Equal(t, "matched", matchedPivot["outcome"])
+	assert.Equal(t, results[0].MatchID, matchedPivot["match_id"])
+	assert.Equal(t, "matched", matchedRegion["outcome"])
+	assert.Equal(t, "us", matchedRegion["selected_region"])
+}
+
 func TestMatchmaker1v1WithLatencySuccess_SecondTry(t *testing.T) {
 	t.Parallel()
 	scope := envelope.NewRootScope(context.Background(), "TestMatchmaker1v1WithLatencySuccess_SecondTry", "")
@@ -2613,10 +2733,26 @@ func TestMatchPlayers_FindMatchingAlly(t *testing.T) {
 			Reference: 100,
 		}}
 
-		allies, _ := findMatchingAlly(scope, cfg, sourceTickets, sourceTickets[0], allianceRule, matchingRules, models.BlockedPlayerCannotMatch)
+		allies, _, err := findMatchingAlly(scope, cfg, sourceTickets, sourceTickets[0], allianceRule, matchingRules, models.BlockedPlayerCannotMatch)
+		require.NoError(t, err)
 		// alliance MinNumber=1 PlayerMinNumber=1 supplied with 1 ticket should produce 1 alliance
 		require.Lenf(t, allies, 1, " should produce 1 allies")
 	})
+
+	t.Run("returnValidationErrorWhenNotEnoughAlliance", func(t *testing.T) {
+		channel := "test"
+		sourceTickets := generateRequestWithMMR(channel, 1, 1, 0)
+		allianceRule := models.AllianceRule{
+			MinNumber:       2,
+			MaxNumber:       2,
+			PlayerMinNumber: 1,
+			PlayerMaxNumber: 1,
+		}
+
+		allies, _, err := findMatchingAlly(scope, cfg, sourceTickets, sourceTickets[0], allianceRule, nil, models.BlockedPlayerCannotMatch)
+		require.Error(t, err)
+		require.Empty(t, allies)
+	})
 }
 
 func setRole(m *models.PartyMember, role ...string) {
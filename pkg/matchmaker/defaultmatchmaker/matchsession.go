@@ -7,13 +7,14 @@
 package defaultmatchmaker
 
 import (
+	"math"
 	"sort"
 	"strings"
 	"time"
 
-	"github.com/AccelByte/extend-core-matchmaker/pkg/constants"
 	"github.com/AccelByte/extend-core-matchmaker/pkg/envelope"
 	"github.com/AccelByte/extend-core-matchmaker/pkg/models"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/utils"
 )
 
 // while adding ticket to a session, we should check the mmr balance
@@ -33,10 +34,7 @@ func (mm *MatchMaker) MatchSessions(rootScope *envelope.Scope, namespace string,
 
 	// Set up timeout safeguard for pool lock
 	startTime := time.Now()
-	timeLimit := (constants.PoolLockTimeLimit * 2) / 5
-	if mm.cfg != nil && mm.cfg.MatchTimeLimitSecond > 0 {
-		timeLimit = time.Duration(mm.cfg.MatchTimeLimitSecond) * time.Second
-	}
+	timeLimit := mm.matchTimeLimit()
 	satisfiedTickets = make([]models.MatchmakingRequest, 0)
 	updatedSessions = make([]*models.MatchmakingResult, 0)
 	satisfiedSessions = make([]*models.MatchmakingResult, 0)
@@ -258,9 +256,16 @@ allsession:
 					// Try to add ticket to existing allies, starting with the smallest
 					for _, allyIndex := range sortedIndex {
 						ally := session.MatchingAllies[allyIndex]
+						team := allianceRule.GetTeamRule(allyIndex)
+						if !team.Accepts(candidateTicket.PartyAttributes) {
+							continue
+						}
+						if !isPremadeSizeAllowed(allianceRule, session.MatchingAllies, allyIndex, candidateTicket) {
+							continue
+						}
 						// Prepare PartyFinder params
-						minPlayer := allianceRule.PlayerMinNumber
-						maxPlayer := allianceRule.PlayerMaxNumber
+						minPlayer := team.PlayerMinNumber
+						maxPlayer := team.PlayerMaxNumber
 						current := []models.MatchmakingRequest{
 							// PartyFinder only need the party members to find a party
 							{PartyMembers: ally.GetMembers()},
@@ -315,7 +320,7 @@ allsession:
 				}
 
 				// Clean up empty matching parties
-				session.MatchingAllies = RemoveEmptyMatchingParties(session.MatchingAllies)
+				session.MatchingAllies = removeEmptyAllies(session.MatchingAllies, activeRuleset.AllianceRule)
 
 				if !found {
 					continue
@@ -373,12 +378,12 @@ allsession:
 					ticketMemberAttributes = make(map[string]interface{})
 				}
 				for _, rule := range activeRuleset.MatchingRule {
-					if rule.Criteria == distanceCriteria {
-						currentAvg, ok := sessionMemberAttributes[rule.Attribute].(float64)
+					if rule.Criteria == distanceCriteria || rule.Criteria == ratingCriteria {
+						currentAvg, ok := utils.ToFloat64(sessionMemberAttributes[rule.Attribute])
 						if !ok {
 							currentAvg = 0
 						}
-						ticketAvg, ok := ticketMemberAttributes[rule.Attribute].(float64)
+						ticketAvg, ok := utils.ToFloat64(ticketMemberAttributes[rule.Attribute])
 						if !ok {
 							ticketAvg = 0
 						}
@@ -386,6 +391,13 @@ allsession:
 						newAvg := (float64(originalSessionPlayerCount)*currentAvg + float64(ticketPlayerCount)*ticketAvg) / (float64(originalSessionPlayerCount) + float64(ticketPlayerCount))
 						sessionMemberAttributes[rule.Attribute] = newAvg
 					}
+					if rule.Criteria == ratingCriteria {
+						// The deviations are combined as the root mean square, like the deviations of a party
+						currentDeviation, _ := utils.ToFloat64(sessionMemberAttributes[rule.DeviationAttribute])
+						ticketDeviation, _ := utils.ToFloat64(ticketMemberAttributes[rule.DeviationAttribute])
+						newVariance := (float64(originalSessionPlayerCount)*currentDeviation*currentDeviation + float64(ticketPlayerCount)*ticketDeviation*ticketDeviation) / (float64(originalSessionPlayerCount) + float64(ticketPlayerCount))
+						sessionMemberAttributes[rule.DeviationAttribute] = math.Sqrt(newVariance)
+					}
 				}
 				session.PartyAttributes[memberAttributesKey] = sessionMemberAttributes
 
@@ -406,7 +418,7 @@ allsession:
 					if teamCount == allianceRule.MaxNumber {
 						full = true
 						for teamIndex := 0; teamIndex < teamCount; teamIndex++ {
-							if playerPerTeamCount[teamIndex] < allianceRule.PlayerMaxNumber {
+							if playerPerTeamCount[teamIndex] < allianceRule.GetTeamRule(teamIndex).PlayerMaxNumber {
 								full = false
 								break
 							}
@@ -506,3 +518,17 @@ func updateBlockedPlayerInSession(session *models.MatchmakingResult) {
 	}
 	session.PartyAttributes[models.AttributeBlocked] = blockedPlayers
 }
+
+// isPremadeSizeAllowed returns true when the allies of a session still meet the max premade size difference of the alliance rule
+// with the ticket added to the ally at the index.
+func isPremadeSizeAllowed(allianceRule models.AllianceRule, allies []models.MatchingAlly, allyIndex int, ticket *models.MatchmakingRequest) bool {
+	if allianceRule.MaxPremadeSizeDifference == nil {
+		return true
+	}
+	withTicket := make([]models.MatchingAlly, len(allies))
+	copy(withTicket, allies)
+	parties := make([]models.MatchingParty, 0, len(allies[allyIndex].MatchingParties)+1)
+	parties = append(parties, allies[allyIndex].MatchingParties...)
+	withTicket[allyIndex].MatchingParties = append(parties, createMatchingParty(ticket))
+	return allianceRule.ValidatePremadeSize(withTicket) == nil
+}
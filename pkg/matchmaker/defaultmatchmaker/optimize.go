@@ -0,0 +1,531 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package defaultmatchmaker
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/AccelByte/extend-core-matchmaker/pkg/envelope"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/metrics"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/models"
+)
+
+// qualityEpsilon keeps the local search from swapping parties back and forth on rounding errors
+const qualityEpsilon = 1e-9
+
+// ignoredSwapAttributes are the ticket attributes two parties can differ on and still be swapped,
+// every other attribute may end up in the match attributes.
+var ignoredSwapAttributes = map[string]struct{}{
+	models.AttributeMemberAttr:           {},
+	models.AttributeMatchAttempt:         {},
+	models.AttributeLatencies:            {},
+	models.AttributeBlocked:              {},
+	models.AttributeBlockedPlayersDetail: {},
+}
+
+// matchQuality is the quality of a set of matches, lower is better.
+// distance is the sum of the distance scores of the parties to the pivots of their match,
+// latencySpread is the sum of the differences between the highest and lowest latency to the region of each match.
+type matchQuality struct {
+	matches       int
+	distance      float64
+	latencySpread float64
+}
+
+func (q matchQuality) averageDistance() float64 {
+	if q.matches == 0 {
+		return 0
+	}
+	return q.distance / float64(q.matches)
+}
+
+func (q matchQuality) averageLatencySpread() float64 {
+	if q.matches == 0 {
+		return 0
+	}
+	return q.latencySpread / float64(q.matches)
+}
+
+// optimizedMatch is a match with the pivot and the ruleset the match was made with.
+type optimizedMatch struct {
+	result    *models.MatchmakingResult
+	pivot     models.MatchmakingRequest
+	ruleset   models.RuleSet
+	distances []distance
+	changed   bool
+}
+
+// matchOptimizer runs the local search over the matches of one MatchPlayers call.
+type matchOptimizer struct {
+	mm        *MatchMaker
+	scope     *envelope.Scope
+	namespace string
+	matchPool string
+	channel   models.Channel
+	deadline  time.Time
+
+	requests  map[string]models.MatchmakingRequest
+	matches   []*optimizedMatch
+	leftovers []models.MatchmakingRequest
+	satisfied []models.MatchmakingRequest
+}
+
+// optimizeMatches improves the greedy matches of MatchPlayers until the deadline.
+// It first swaps compatible parties of the same size between matches while it lowers the total distance score
+// or latency spread without raising the other, then tries to make extra matches from the leftover tickets
+// by pulling one party out of a match that stays valid without it. No rule of the matches is broken:
+// a party only joins a match it could have been matched in by the pivot, and shares every ticket attribute
+// but its member attributes, latencies and blocked players with the party it replaces.
+// The quality before and after is logged and exported by the match quality metric.
+func (mm *MatchMaker) optimizeMatches(rootScope *envelope.Scope, namespace string, matchPool string, requests []models.MatchmakingRequest,
+	results []*models.MatchmakingResult, satisfiedTickets []models.MatchmakingRequest, channel models.Channel, deadline time.Time,
+) ([]*models.MatchmakingResult, []models.MatchmakingRequest, error) {
+	scope := rootScope.NewChildScope("MatchMaker.optimizeMatches")
+	defer scope.Finish()
+
+	o := &matchOptimizer{
+		mm:        mm,
+		scope:     scope,
+		namespace: namespace,
+		matchPool: matchPool,
+		channel:   channel,
+		deadline:  deadline,
+		requests:  make(map[string]models.MatchmakingRequest, len(requests)),
+		satisfied: satisfiedTickets,
+	}
+	for _, request := range requests {
+		o.requests[request.PartyID] = request
+	}
+	satisfied := make(map[string]struct{}, len(satisfiedTickets))
+	for _, ticket := range satisfiedTickets {
+		satisfied[ticket.PartyID] = struct{}{}
+	}
+	for _, request := range requests {
+		if _, ok := satisfied[request.PartyID]; !ok {
+			o.leftovers = append(o.leftovers, request)
+		}
+	}
+	for _, result := range results {
+		o.addMatch(result)
+	}
+
+	before := o.quality()
+	unmatchedBefore := len(o.leftovers)
+
+	swaps := o.swapParties()
+	extraMatches := o.formExtraMatches()
+	for _, match := range o.matches {
+		if match.changed {
+			o.refreshMatch(match)
+		}
+	}
+
+	after := o.quality()
+
+	metrics.MatchQuality.WithLabelValues(namespace, matchPool, metrics.QualityDistance, metrics.StageBefore).Set(before.averageDistance())
+	metrics.MatchQuality.WithLabelValues(namespace, matchPool, metrics.QualityDistance, metrics.StageAfter).Set(after.averageDistance())
+	metrics.MatchQuality.WithLabelValues(namespace, matchPool, metrics.QualityLatencySpread, metrics.StageBefore).Set(before.averageLatencySpread())
+	metrics.MatchQuality.WithLabelValues(namespace, matchPool, metrics.QualityLatencySpread, metrics.StageAfter).Set(after.averageLatencySpread())
+	metrics.OptimizationMoves.WithLabelValues(namespace, matchPool, metrics.MoveSwap).Add(float64(swaps))
+	metrics.OptimizationMoves.WithLabelValues(namespace, matchPool, metrics.MoveExtraMatch).Add(float64(extraMatches))
+
+	scope.SetAttributes("swaps", swaps)
+	scope.SetAttributes("extra_matches", extraMatches)
+	scope.Log.
+		WithField("matchesBefore", before.matches).
+		WithField("matchesAfter", after.matches).
+		WithField("unmatchedBefore", unmatchedBefore).
+		WithField("unmatchedAfter", len(o.leftovers)).
+		WithField("distanceBefore", before.distance).
+		WithField("distanceAfter", after.distance).
+		WithField("latencySpreadBefore", before.latencySpread).
+		WithField("latencySpreadAfter", after.latencySpread).
+		WithField("swaps", swaps).
+		WithField("extraMatches", extraMatches).
+		Info("matches optimized")
+
+	optimized := make([]*models.MatchmakingResult, 0, len(o.matches))
+	for _, match := range o.matches {
+		optimized = append(optimized, match.result)
+	}
+	return optimized, o.satisfied, nil
+}
+
+func (o *matchOptimizer) expired() bool {
+	return !time.Now().Before(o.deadline)
+}
+
+// addMatch keeps the ruleset the pivot of the match was matched with
+func (o *matchOptimizer) addMatch(result *models.MatchmakingResult) {
+	pivot, ok := o.requests[result.PivotID]
+	if !ok {
+		// a match without a known pivot is left untouched
+		pivot = models.MatchmakingRequest{PartyID: result.PivotID}
+	}
+	pivotTime := time.Unix(pivot.CreatedAt, 0)
+	ruleset, _ := applyRuleFlexing(o.channel.Ruleset, pivotTime)
+	ruleset, _ = applyAllianceFlexingRules(ruleset, pivotTime)
+
+	o.matches = append(o.matches, &optimizedMatch{
+		result:    result,
+		pivot:     pivot,
+		ruleset:   ruleset,
+		distances: getFilterByDistance(&ruleset, pivot.PartyAttributes),
+	})
+}
+
+// quality returns the quality of the current matches
+func (o *matchOptimizer) quality() matchQuality {
+	quality := matchQuality{matches: len(o.matches)}
+	for _, match := range o.matches {
+		for _, ally := range match.result.MatchingAllies {
+			for _, party := range ally.MatchingParties {
+				_, score := o.distanceScore(match, party.PartyID)
+				quality.distance += score
+			}
+		}
+		quality.latencySpread += o.latencySpread(match, nil)
+	}
+	return quality
+}
+
+// distanceScore returns if the party can be matched by the pivot of the match, and its distance score to the pivot
+func (o *matchOptimizer) distanceScore(match *optimizedMatch, partyID string) (bool, float64) {
+	if partyID == match.pivot.PartyID {
+		return true, 0
+	}
+	request, ok := o.requests[partyID]
+	if !ok {
+		return false, 0
+	}
+	return matchByDistance(&request, &o.channel.Ruleset, match.distances)
+}
+
+// latencySpread returns the difference between the highest and lowest latency to the region of the match,
+// the replaced parties are swapped for their replacement first
+func (o *matchOptimizer) latencySpread(match *optimizedMatch, replaced map[string]string) float64 {
+	region := match.result.Region
+	if region == "" {
+		return 0
+	}
+
+	var lowest, highest int
+	found := false
+	for _, ally := range match.result.MatchingAllies {
+		for _, party := range ally.MatchingParties {
+			partyID := party.PartyID
+			if replacement, ok := replaced[partyID]; ok {
+				partyID = replacement
+			}
+			latency, ok := o.requests[partyID].LatencyMap[region]
+			if !ok {
+				continue
+			}
+			if !found || latency < lowest {
+				lowest = latency
+			}
+			if !found || latency > highest {
+				highest = latency
+			}
+			found = true
+		}
+	}
+	return float64(highest - lowest)
+}
+
+// swapParties swaps parties between matches while the quality improves, it returns the number of swaps
+func (o *matchOptimizer) swapParties() int {
+	swaps := 0
+	for improved := true; improved; {
+		improved = false
+		for x := 0; x < len(o.matches); x++ {
+			for y := x + 1; y < len(o.matches); y++ {
+				if o.expired() {
+					return swaps
+				}
+				if o.trySwap(o.matches[x], o.matches[y]) {
+					swaps++
+					improved = true
+				}
+			}
+		}
+	}
+	return swaps
+}
+
+// trySwap makes the first swap between the two matches that improves the quality
+func (o *matchOptimizer) trySwap(x, y *optimizedMatch) bool {
+	for i, allyX := range x.result.MatchingAllies {
+		for p, partyX := range allyX.MatchingParties {
+			if partyX.PartyID == x.pivot.PartyID {
+				continue
+			}
+			for j, allyY := range y.result.MatchingAllies {
+				for q, partyY := range allyY.MatchingParties {
+					if partyY.PartyID == y.pivot.PartyID || partyX.CountPlayer() != partyY.CountPlayer() {
+						continue
+					}
+					if !o.improves(x, y, partyX.PartyID, partyY.PartyID) {
+						continue
+					}
+					if !o.canReplace(x, i, partyX.PartyID, partyY.PartyID) || !o.canReplace(y, j, partyY.PartyID, partyX.PartyID) {
+						continue
+					}
+
+					x.result.MatchingAllies[i].MatchingParties[p] = partyY
+					y.result.MatchingAllies[j].MatchingParties[q] = partyX
+					if !o.meetsMinScore(x, x.result.MatchingAllies) || !o.meetsMinScore(y, y.result.MatchingAllies) {
+						x.result.MatchingAllies[i].MatchingParties[p] = partyX
+						y.result.MatchingAllies[j].MatchingParties[q] = partyY
+						continue
+					}
+					x.changed = true
+					y.changed = true
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// improves returns true when swapping the parties lowers the distance score or the latency spread without raising the other
+func (o *matchOptimizer) improves(x, y *optimizedMatch, partyX, partyY string) bool {
+	okX, newX := o.distanceScore(x, partyY)
+	okY, newY := o.distanceScore(y, partyX)
+	if !okX || !okY {
+		return false
+	}
+	_, oldX := o.distanceScore(x, partyX)
+	_, oldY := o.distanceScore(y, partyY)
+	distanceBefore := oldX + oldY
+	distanceAfter := newX + newY
+
+	latencyBefore := o.latencySpread(x, nil) + o.latencySpread(y, nil)
+	latencyAfter := o.latencySpread(x, map[string]string{partyX: partyY}) + o.latencySpread(y, map[string]string{partyY: partyX})
+
+	if distanceAfter < distanceBefore-qualityEpsilon && latencyAfter <= latencyBefore {
+		return true
+	}
+	return latencyAfter < latencyBefore && distanceAfter <= distanceBefore+qualityEpsilon
+}
+
+// canReplace returns true when the joining party can take the place of the leaving party in the team of the match
+func (o *matchOptimizer) canReplace(match *optimizedMatch, allyIndex int, leavingID, joiningID string) bool {
+	leaving, okLeaving := o.requests[leavingID]
+	joining, okJoining := o.requests[joiningID]
+	if !okLeaving || !okJoining {
+		return false
+	}
+
+	if !sameSwapAttributes(leaving.PartyAttributes, joining.PartyAttributes) {
+		return false
+	}
+	if !match.ruleset.AllianceRule.GetTeamRule(allyIndex).Accepts(joining.PartyAttributes) {
+		return false
+	}
+
+	// the region of the match must stay in the region window of the joining party
+	if region := match.result.Region; region != "" && len(joining.LatencyMap) > 0 {
+		inWindow := false
+		for _, r := range filterRegionByStep(&joining, &o.channel) {
+			if r.Region == region {
+				inWindow = true
+				break
+			}
+		}
+		if !inWindow {
+			return false
+		}
+	}
+
+	blockedPlayerOption := o.channel.Ruleset.BlockedPlayerOption
+	if blockedPlayerOption == models.BlockedPlayerCanMatch {
+		return true
+	}
+	var others []models.MatchmakingRequest
+	for i, ally := range match.result.MatchingAllies {
+		if blockedPlayerOption == models.BlockedPlayerCanMatchOnDifferentTeam && i != allyIndex {
+			continue
+		}
+		for _, party := range ally.MatchingParties {
+			if party.PartyID != leavingID {
+				others = append(others, o.requests[party.PartyID])
+			}
+		}
+	}
+	return !isContainBlockedPlayers(others, &joining)
+}
+
+// sameSwapAttributes returns true when the ticket attributes are the same but the ignored swap attributes
+func sameSwapAttributes(a, b map[string]interface{}) bool {
+	for key, value := range a {
+		if _, ignored := ignoredSwapAttributes[key]; ignored {
+			continue
+		}
+		if other, ok := b[key]; !ok || !reflect.DeepEqual(value, other) {
+			return false
+		}
+	}
+	for key := range b {
+		if _, ignored := ignoredSwapAttributes[key]; ignored {
+			continue
+		}
+		if _, ok := a[key]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// formExtraMatches matches the leftovers with one party pulled out of a match, it returns the number of extra matches
+func (o *matchOptimizer) formExtraMatches() int {
+	extraMatches := 0
+	matches := o.matches
+	for _, match := range matches {
+	partyLoop:
+		for i, ally := range match.result.MatchingAllies {
+			for p, party := range ally.MatchingParties {
+				if len(o.leftovers) == 0 || o.expired() {
+					return extraMatches
+				}
+				if party.PartyID == match.pivot.PartyID {
+					continue
+				}
+				allies, ok := o.withoutParty(match, i, p)
+				if !ok {
+					continue
+				}
+
+				pulled, ok := o.requests[party.PartyID]
+				if !ok {
+					continue
+				}
+				candidates := make([]models.MatchmakingRequest, 0, len(o.leftovers)+1)
+				candidates = append(candidates, o.leftovers...)
+				candidates = append(candidates, pulled)
+
+				// the greedy search gets the rest of the budget and no further optimization
+				inner := *o.mm
+				inner.timeLimit = time.Until(o.deadline)
+				results, satisfiedTickets, err := inner.matchPlayers(o.scope, o.namespace, o.matchPool, candidates, o.channel)
+				if err != nil || !resultsContainParty(results, party.PartyID) {
+					continue
+				}
+
+				match.result.MatchingAllies = allies
+				match.changed = true
+				for _, result := range results {
+					o.addMatch(result)
+				}
+				extraMatches += len(results)
+
+				matched := make(map[string]struct{}, len(satisfiedTickets))
+				for _, ticket := range satisfiedTickets {
+					matched[ticket.PartyID] = struct{}{}
+					if ticket.PartyID != party.PartyID {
+						o.satisfied = append(o.satisfied, ticket)
+					}
+				}
+				leftovers := o.leftovers[:0]
+				for _, ticket := range o.leftovers {
+					if _, ok := matched[ticket.PartyID]; !ok {
+						leftovers = append(leftovers, ticket)
+					}
+				}
+				o.leftovers = leftovers
+
+				// the match changed, move on to the next one
+				break partyLoop
+			}
+		}
+	}
+	return extraMatches
+}
+
+// withoutParty returns the allies of the match without the party, when the match is still valid without it
+func (o *matchOptimizer) withoutParty(match *optimizedMatch, allyIndex, partyIndex int) ([]models.MatchingAlly, bool) {
+	allies := make([]models.MatchingAlly, len(match.result.MatchingAllies))
+	copy(allies, match.result.MatchingAllies)
+
+	ally := allies[allyIndex]
+	parties := make([]models.MatchingParty, 0, len(ally.MatchingParties)-1)
+	parties = append(parties, ally.MatchingParties[:partyIndex]...)
+	parties = append(parties, ally.MatchingParties[partyIndex+1:]...)
+	ally.PlayerCount -= ally.MatchingParties[partyIndex].CountPlayer()
+	ally.MatchingParties = parties
+	allies[allyIndex] = ally
+	allies = removeEmptyAllies(allies, match.ruleset.AllianceRule)
+
+	if err := match.ruleset.AllianceRule.ValidateAllies(allies, o.channel.Ruleset.BlockedPlayerOption); err != nil {
+		return nil, false
+	}
+	if !o.meetsMinScore(match, allies) {
+		return nil, false
+	}
+	return allies, true
+}
+
+// meetsMinScore returns true when the allies score at least the minimum match quality of the pivot age of the match
+func (o *matchOptimizer) meetsMinScore(match *optimizedMatch, allies []models.MatchingAlly) bool {
+	quality := o.channel.Ruleset.MatchQuality
+	if !quality.IsEnabled() {
+		return true
+	}
+	var requests []models.MatchmakingRequest
+	for _, ally := range allies {
+		for _, party := range ally.MatchingParties {
+			if request, ok := o.requests[party.PartyID]; ok {
+				requests = append(requests, request)
+			}
+		}
+	}
+	score := scoreMatch(&o.channel.Ruleset, allies, requests, match.result.Region)
+	return score.score >= quality.GetMinScore(Now().Sub(time.Unix(match.pivot.CreatedAt, 0)))
+}
+
+// refreshMatch updates the blocked players, the region preference and the quality score of a match after its parties changed
+func (o *matchOptimizer) refreshMatch(match *optimizedMatch) {
+	var blocked []interface{}
+	var requests []models.MatchmakingRequest
+	for _, ally := range match.result.MatchingAllies {
+		for _, party := range ally.MatchingParties {
+			if ids, ok := party.PartyAttributes[models.AttributeBlocked].([]interface{}); ok {
+				blocked = append(blocked, ids...)
+			}
+			if request, ok := o.requests[party.PartyID]; ok {
+				requests = append(requests, request)
+			}
+		}
+	}
+	if match.result.PartyAttributes != nil {
+		if blocked != nil {
+			match.result.PartyAttributes[models.AttributeBlocked] = blocked
+		} else {
+			delete(match.result.PartyAttributes, models.AttributeBlocked)
+		}
+	}
+
+	if match.result.Region != "" {
+		regions := selectMatchRegions(match.pivot, requests, &o.channel, match.result.Region)
+		match.result.RegionPreference = withRegionFirst(regions, match.result.Region)
+	}
+
+	if o.channel.Ruleset.MatchQuality.IsEnabled() && match.result.PartyAttributes != nil {
+		score := scoreMatch(&o.channel.Ruleset, match.result.MatchingAllies, requests, match.result.Region)
+		match.result.PartyAttributes[models.AttributeMatchQuality] = score.attributes()
+	}
+}
+
+// resultsContainParty returns true when one of the matches has the party
+func resultsContainParty(results []*models.MatchmakingResult, partyID string) bool {
+	for _, result := range results {
+		if isPartyInAllies(partyID, result.MatchingAllies) {
+			return true
+		}
+	}
+	return false
+}
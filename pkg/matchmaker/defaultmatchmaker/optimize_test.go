@@ -0,0 +1,110 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package defaultmatchmaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AccelByte/extend-core-matchmaker/pkg/config"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/models"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/testsetup"
+	. "github.com/onsi/gomega"
+)
+
+func TestOptimizeMatches_Swap(t *testing.T) {
+	g := testsetup.ParallelWithGomega(t)
+
+	low := generateRequestWithMMR("optimize", 2, 1, 100)
+	high := generateRequestWithMMR("optimize", 2, 1, 900)
+	requests := append(append([]models.MatchmakingRequest{}, low...), high...)
+
+	// the greedy matches paired each pivot with the other mmr
+	results := []*models.MatchmakingResult{
+		{PivotID: low[0].PartyID, MatchingAllies: []models.MatchingAlly{createMatchingAlly(low[0]), createMatchingAlly(high[1])}},
+		{PivotID: high[0].PartyID, MatchingAllies: []models.MatchingAlly{createMatchingAlly(high[0]), createMatchingAlly(low[1])}},
+	}
+	channel := models.Channel{Ruleset: get1v1Rules()}
+
+	mm := NewMatchMaker(&config.Config{})
+	optimized, satisfied, err := mm.optimizeMatches(testsetup.NewTestScope(), "", "", requests, results, requests, channel, time.Now().Add(time.Second))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(satisfied).To(HaveLen(4))
+	g.Expect(optimized).To(HaveLen(2))
+	g.Expect(allyPartyIDs(optimized[0].MatchingAllies[1])).To(ConsistOf(low[1].PartyID))
+	g.Expect(allyPartyIDs(optimized[1].MatchingAllies[1])).To(ConsistOf(high[1].PartyID))
+}
+
+func TestOptimizeMatches_NoSwapOnDifferentAttributes(t *testing.T) {
+	g := testsetup.ParallelWithGomega(t)
+
+	low := generateRequestWithMMR("optimize", 2, 1, 100)
+	high := generateRequestWithMMR("optimize", 2, 1, 900)
+	high[1].PartyAttributes["server_name"] = "server-a"
+	requests := append(append([]models.MatchmakingRequest{}, low...), high...)
+
+	results := []*models.MatchmakingResult{
+		{PivotID: low[0].PartyID, MatchingAllies: []models.MatchingAlly{createMatchingAlly(low[0]), createMatchingAlly(high[1])}},
+		{PivotID: high[0].PartyID, MatchingAllies: []models.MatchingAlly{createMatchingAlly(high[0]), createMatchingAlly(low[1])}},
+	}
+	channel := models.Channel{Ruleset: get1v1Rules()}
+
+	mm := NewMatchMaker(&config.Config{})
+	optimized, _, err := mm.optimizeMatches(testsetup.NewTestScope(), "", "", requests, results, requests, channel, time.Now().Add(time.Second))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(allyPartyIDs(optimized[0].MatchingAllies[1])).To(ConsistOf(high[1].PartyID))
+	g.Expect(allyPartyIDs(optimized[1].MatchingAllies[1])).To(ConsistOf(low[1].PartyID))
+}
+
+func TestOptimizeMatches_ExtraMatch(t *testing.T) {
+	g := testsetup.ParallelWithGomega(t)
+
+	requests := generateRequestWithMMR("optimize", 4, 1, 100)
+	pivot, teammate, opponent, leftover := requests[0], requests[1], requests[2], requests[3]
+
+	// the teammate can leave, the match still has a player on each team
+	results := []*models.MatchmakingResult{
+		{PivotID: pivot.PartyID, MatchingAllies: []models.MatchingAlly{createMatchingAlly(pivot, teammate), createMatchingAlly(opponent)}},
+	}
+	channel := models.Channel{Ruleset: models.RuleSet{
+		AllianceRule: models.AllianceRule{MinNumber: 2, MaxNumber: 2, PlayerMinNumber: 1, PlayerMaxNumber: 2},
+		MatchingRule: get1v1Rules().MatchingRule,
+	}}
+
+	mm := NewMatchMaker(&config.Config{})
+	satisfied := []models.MatchmakingRequest{pivot, teammate, opponent}
+	optimized, satisfied, err := mm.optimizeMatches(testsetup.NewTestScope(), "", "", requests, results, satisfied, channel, time.Now().Add(time.Second))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(optimized).To(HaveLen(2))
+	g.Expect(satisfied).To(ConsistOf(requests))
+	g.Expect(countParties(optimized[0].MatchingAllies)).To(Equal(2))
+	g.Expect(countParties(optimized[1].MatchingAllies)).To(Equal(2))
+	g.Expect(resultsContainParty(optimized[1:], leftover.PartyID)).To(BeTrue())
+	g.Expect(resultsContainParty(optimized[1:], teammate.PartyID)).To(BeTrue())
+}
+
+func TestMatchPlayers_OptimizationBudget(t *testing.T) {
+	g := testsetup.ParallelWithGomega(t)
+
+	requests := generateRequestWithMemberCount("optimize", []int{1, 1, 1, 1, 1, 1, 1, 1})
+	channel := models.Channel{Ruleset: get1v1Rules()}
+
+	mm := NewMatchMaker(&config.Config{MatchOptimizationBudgetMs: 100})
+	results, satisfied, err := mm.MatchPlayers(testsetup.NewTestScope(), "", "", requests, channel)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(results).To(HaveLen(4))
+	g.Expect(satisfied).To(HaveLen(8))
+
+	matched := map[string]int{}
+	for _, result := range results {
+		g.Expect(channel.Ruleset.AllianceRule.ValidateAllies(result.MatchingAllies, channel.Ruleset.BlockedPlayerOption)).To(Succeed())
+		for _, ally := range result.MatchingAllies {
+			for _, partyID := range allyPartyIDs(ally) {
+				matched[partyID]++
+			}
+		}
+	}
+	g.Expect(matched).To(HaveLen(8))
+}
@@ -0,0 +1,193 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package defaultmatchmaker
+
+import (
+	"fmt"
+
+	"github.com/AccelByte/extend-core-matchmaker/pkg/envelope"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/matchmaker"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/metrics"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/models"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/tickhistory"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Partition policies, they decide what happens to the tickets of a stream that don't share
+// the namespace and match pool of the first ticket of the stream.
+const (
+	// PartitionPolicyIsolate matches the tickets of every namespace and match pool separately
+	PartitionPolicyIsolate = "isolate"
+	// PartitionPolicyReject drops the tickets of any other namespace and match pool
+	PartitionPolicyReject = "reject"
+)
+
+// partitionMismatchReason is the unmatched reason and rejected input reason of a ticket dropped by the reject policy
+const partitionMismatchReason = "partition_mismatch"
+
+// ValidatePartitionPolicy returns an error if the policy is not one of the partition policies.
+func ValidatePartitionPolicy(policy string) error {
+	switch policy {
+	case PartitionPolicyIsolate, PartitionPolicyReject:
+		return nil
+	default:
+		return fmt.Errorf("unknown partition policy %q, expected %s or %s", policy, PartitionPolicyIsolate, PartitionPolicyReject)
+	}
+}
+
+// partitionKey identifies the tickets that can be matched together.
+type partitionKey struct {
+	namespace string
+	matchPool string
+}
+
+func ticketPartitionKey(ticket matchmaker.Ticket) partitionKey {
+	return partitionKey{namespace: ticket.Namespace, matchPool: ticket.MatchPool}
+}
+
+// partitioner splits the tickets of one stream by namespace and match pool.
+// The first accepted ticket or session sets the primary partition of the stream.
+type partitioner struct {
+	policy  string
+	primary *partitionKey
+}
+
+func newPartitioner(policy string) *partitioner {
+	return &partitioner{policy: policy}
+}
+
+// accept returns false when the policy drops a ticket of the partition, the dropped ticket is logged and counted.
+func (p *partitioner) accept(scope *envelope.Scope, key partitionKey, ticketID string) bool {
+	if p.primary == nil {
+		p.primary = &key
+		return true
+	}
+	if key == *p.primary || p.policy != PartitionPolicyReject {
+		return true
+	}
+
+	scope.Sampled(logrus.WarnLevel).
+		WithField("ticketID", ticketID).
+		WithField("namespace", key.namespace).
+		WithField("matchPool", key.matchPool).
+		Warnf("dropping ticket outside of the stream partition %s/%s", p.primary.namespace, p.primary.matchPool)
+	metrics.RejectedInput.WithLabelValues(partitionMismatchReason).Inc()
+	tickhistory.RecordUnmatched(scope.Ctx, ticketID, partitionMismatchReason)
+
+	return false
+}
+
+// sessionKey returns the partition of a backfill session, a session without a known namespace
+// belongs to the namespace of the stream.
+func (p *partitioner) sessionKey(session *models.MatchmakingResult, streamNamespace string) partitionKey {
+	namespace := session.Namespace
+	if namespace == "" {
+		namespace = streamNamespace
+		if p.primary != nil {
+			namespace = p.primary.namespace
+		}
+	}
+	return partitionKey{namespace: namespace, matchPool: session.Channel}
+}
+
+// ticketChunker groups the accepted tickets by partition and hands out chunks of at most maxTicketCount tickets.
+type ticketChunker struct {
+	*partitioner
+	maxTicketCount int
+	pending        map[partitionKey][]matchmaker.Ticket
+	counts         map[partitionKey]int
+	order          []partitionKey
+}
+
+func newTicketChunker(policy string, maxTicketCount int) *ticketChunker {
+	return &ticketChunker{
+		partitioner:    newPartitioner(policy),
+		maxTicketCount: maxTicketCount,
+		pending:        make(map[partitionKey][]matchmaker.Ticket),
+		counts:         make(map[partitionKey]int),
+	}
+}
+
+// add adds a ticket and returns the chunk of its partition once the chunk is full
+func (c *ticketChunker) add(scope *envelope.Scope, ticket matchmaker.Ticket) []matchmaker.Ticket {
+	key := ticketPartitionKey(ticket)
+	if !c.accept(scope, key, ticket.TicketID) {
+		return nil
+	}
+
+	if _, ok := c.counts[key]; !ok {
+		c.order = append(c.order, key)
+	}
+	c.counts[key]++
+	c.pending[key] = append(c.pending[key], ticket)
+
+	if c.maxTicketCount > 0 && len(c.pending[key]) >= c.maxTicketCount {
+		chunk := c.pending[key]
+		c.pending[key] = nil
+		return chunk
+	}
+	return nil
+}
+
+// flush returns the remaining chunks, in the order the partitions were first seen
+func (c *ticketChunker) flush() [][]matchmaker.Ticket {
+	var chunks [][]matchmaker.Ticket
+	for _, key := range c.order {
+		if len(c.pending[key]) > 0 {
+			chunks = append(chunks, c.pending[key])
+			c.pending[key] = nil
+		}
+	}
+	return chunks
+}
+
+// backfillPartition is the sessions and the tickets of one partition of the backfill input.
+type backfillPartition struct {
+	key      partitionKey
+	requests []models.MatchmakingRequest
+	tickets  []matchmaker.Ticket
+	sessions []*models.MatchmakingResult
+}
+
+// partitionBackfill splits the backfill input by partition, the sessions are keyed first so the first
+// backfill ticket of the stream sets the primary partition. Partitions without sessions are left out.
+func (p *partitioner) partitionBackfill(scope *envelope.Scope, requests []models.MatchmakingRequest,
+	sessions []*models.MatchmakingResult, tickets []matchmaker.Ticket) []*backfillPartition {
+
+	streamNamespace, _ := getNamespaceMatchPool(tickets)
+
+	var partitions []*backfillPartition
+	byKey := make(map[partitionKey]*backfillPartition)
+	for _, session := range sessions {
+		key := p.sessionKey(session, streamNamespace)
+		if !p.accept(scope, key, session.MatchID) {
+			continue
+		}
+		partition, ok := byKey[key]
+		if !ok {
+			partition = &backfillPartition{key: key}
+			byKey[key] = partition
+			partitions = append(partitions, partition)
+		}
+		partition.sessions = append(partition.sessions, session)
+	}
+
+	for i, ticket := range tickets {
+		key := ticketPartitionKey(ticket)
+		if !p.accept(scope, key, ticket.TicketID) {
+			continue
+		}
+		partition, ok := byKey[key]
+		if !ok {
+			// no session to backfill in this partition
+			continue
+		}
+		partition.requests = append(partition.requests, requests[i])
+		partition.tickets = append(partition.tickets, ticket)
+	}
+
+	return partitions
+}
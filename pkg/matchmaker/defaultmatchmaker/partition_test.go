@@ -0,0 +1,160 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package defaultmatchmaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AccelByte/extend-core-matchmaker/pkg/config"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/matchmaker"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/metrics"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/models"
+	player "github.com/AccelByte/extend-core-matchmaker/pkg/playerdata"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/testsetup"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func newPartitionedMatchLogic(policy string) matchmaker.MatchLogic {
+	return New(&config.Config{TicketChunkSize: 10, PartitionPolicy: policy})
+}
+
+func partitionTicket(ticketID, namespace, matchPool string) matchmaker.Ticket {
+	return matchmaker.Ticket{
+		TicketID:  ticketID,
+		Namespace: namespace,
+		MatchPool: matchPool,
+		CreatedAt: time.Now(),
+		Players: []player.PlayerData{{
+			PlayerID:   player.ID("player-" + ticketID),
+			Attributes: map[string]interface{}{"mmr": 10},
+		}},
+	}
+}
+
+// interleavedTickets alternates the namespaces, so an unpartitioned 1v1 would match across them
+func interleavedTickets(namespaceA, namespaceB string) []matchmaker.Ticket {
+	return []matchmaker.Ticket{
+		partitionTicket("a1", namespaceA, "pool"),
+		partitionTicket("b1", namespaceB, "pool"),
+		partitionTicket("a2", namespaceA, "pool"),
+		partitionTicket("b2", namespaceB, "pool"),
+	}
+}
+
+func TestValidatePartitionPolicy(t *testing.T) {
+	g := testsetup.ParallelWithGomega(t)
+
+	g.Expect(ValidatePartitionPolicy(PartitionPolicyIsolate)).To(Succeed())
+	g.Expect(ValidatePartitionPolicy(PartitionPolicyReject)).To(Succeed())
+	g.Expect(ValidatePartitionPolicy("mixed")).ToNot(Succeed())
+}
+
+func TestDefaultMatchMaker_IsolatesNamespacesInOneStream(t *testing.T) {
+	g := testsetup.ParallelWithGomega(t)
+	mm := newPartitionedMatchLogic(PartitionPolicyIsolate)
+
+	receivedA := metrics.TicketsReceived.WithLabelValues("isolate-a", "pool")
+	receivedB := metrics.TicketsReceived.WithLabelValues("isolate-b", "pool")
+	receivedBeforeA, receivedBeforeB := testutil.ToFloat64(receivedA), testutil.ToFloat64(receivedB)
+
+	ticketProvider := testsetup.StubMatchTicketProvider{Tickets: interleavedTickets("isolate-a", "isolate-b")}
+	matches := mm.MakeMatches(testsetup.NewTestScope(), ticketProvider, get1v1Rules())
+
+	var results []matchmaker.Match
+	for match := range matches {
+		results = append(results, match)
+	}
+
+	g.Expect(results).To(HaveLen(2))
+	for _, result := range results {
+		g.Expect(result.Tickets).To(HaveLen(2))
+		g.Expect(result.Tickets[0].Namespace).To(Equal(result.Tickets[1].Namespace))
+	}
+	g.Expect(testutil.ToFloat64(receivedA) - receivedBeforeA).To(Equal(2.0))
+	g.Expect(testutil.ToFloat64(receivedB) - receivedBeforeB).To(Equal(2.0))
+}
+
+func TestDefaultMatchMaker_CountsRegionMatchesPerPartition(t *testing.T) {
+	g := testsetup.ParallelWithGomega(t)
+	mm := newPartitionedMatchLogic(PartitionPolicyIsolate)
+
+	ruleset := get1v1Rules()
+	ruleset.RegionLatencyInitialRangeMs = 200
+	ruleset.RegionPolicy = models.RegionPolicy{MaxMatchesPerTick: map[string]int{"us": 1}}
+
+	tickets := interleavedTickets("region-a", "region-b")
+	for i := range tickets {
+		tickets[i].Latencies = map[string]int64{"us": 20, "eu": 30}
+	}
+	ticketProvider := testsetup.StubMatchTicketProvider{Tickets: tickets}
+	matches := mm.MakeMatches(testsetup.NewTestScope(), ticketProvider, ruleset)
+
+	var results []matchmaker.Match
+	for match := range matches {
+		results = append(results, match)
+	}
+
+	// each namespace makes its one match of the tick in us, the other namespace doesn't use its cap
+	g.Expect(results).To(HaveLen(2))
+	for _, result := range results {
+		g.Expect(result.RegionPreference[0]).To(Equal("us"))
+	}
+}
+
+func TestDefaultMatchMaker_RejectsOtherNamespacesInOneStream(t *testing.T) {
+	g := testsetup.ParallelWithGomega(t)
+	mm := newPartitionedMatchLogic(PartitionPolicyReject)
+
+	rejected := metrics.RejectedInput.WithLabelValues(partitionMismatchReason)
+	rejectedBefore := testutil.ToFloat64(rejected)
+
+	ticketProvider := testsetup.StubMatchTicketProvider{Tickets: interleavedTickets("reject-a", "reject-b")}
+	matches := mm.MakeMatches(testsetup.NewTestScope(), ticketProvider, get1v1Rules())
+
+	var results []matchmaker.Match
+	for match := range matches {
+		results = append(results, match)
+	}
+
+	g.Expect(results).To(HaveLen(1))
+	g.Expect(results[0].Tickets).To(ConsistOf(ticketProvider.Tickets[0], ticketProvider.Tickets[2]))
+	g.Expect(testutil.ToFloat64(rejected) - rejectedBefore).To(Equal(2.0))
+}
+
+func TestDefaultMatchMaker_BackfillsOnlyWithTicketsOfTheSessionPool(t *testing.T) {
+	g := testsetup.ParallelWithGomega(t)
+	mm := newPartitionedMatchLogic(PartitionPolicyIsolate)
+
+	otherPoolTicket := partitionTicket("other", "ns", "pool-b")
+	samePoolTicket := partitionTicket("same", "ns", "pool-a")
+	ticketProvider := testsetup.StubMatchTicketProvider{
+		Tickets: []matchmaker.Ticket{otherPoolTicket, samePoolTicket},
+		BackfillTickets: []matchmaker.BackfillTicket{{
+			TicketID:  "backfill",
+			MatchPool: "pool-a",
+			PartialMatch: matchmaker.Match{
+				MatchAttributes: map[string]interface{}{models.AttributeMemberAttr: map[string]interface{}{"mmr": 10}},
+				Tickets:         []matchmaker.Ticket{partitionTicket("in-session", "ns", "pool-a")},
+				Teams: []matchmaker.Team{{
+					TeamID:  "team",
+					UserIDs: []player.ID{"player-in-session"},
+				}},
+				Backfill: true,
+			},
+			MatchSessionID: "session",
+		}},
+	}
+	proposals := mm.BackfillMatches(testsetup.NewTestScope(), ticketProvider, backfill1v1RUles)
+
+	var results []matchmaker.BackfillProposal
+	for proposal := range proposals {
+		results = append(results, proposal)
+	}
+
+	g.Expect(results).To(HaveLen(1))
+	g.Expect(results[0].AddedTickets).To(ConsistOf(samePoolTicket))
+}
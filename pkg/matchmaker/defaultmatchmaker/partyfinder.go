@@ -7,9 +7,29 @@
 package defaultmatchmaker
 
 import (
+	"fmt"
+
 	"github.com/AccelByte/extend-core-matchmaker/pkg/models"
 )
 
+// Party finder strategies, they decide how FindPartyCombination combines the tickets of a team.
+const (
+	// PartyFinderStrategyGreedy appends the tickets that fit in the order of the search
+	PartyFinderStrategyGreedy = "greedy"
+	// PartyFinderStrategyExact solves the subset sum of the party sizes, it finds a full team whenever the tickets can make one
+	PartyFinderStrategyExact = "exact"
+)
+
+// ValidatePartyFinderStrategy returns an error if the strategy is not one of the party finder strategies.
+func ValidatePartyFinderStrategy(strategy string) error {
+	switch strategy {
+	case PartyFinderStrategyGreedy, PartyFinderStrategyExact:
+		return nil
+	default:
+		return fmt.Errorf("unknown party finder strategy %q, expected %s or %s", strategy, PartyFinderStrategyGreedy, PartyFinderStrategyExact)
+	}
+}
+
 // PartyFinder defines the interface for finding and managing party combinations during matchmaking.
 // This interface is used to determine optimal party groupings for different matchmaking scenarios.
 type PartyFinder interface {
@@ -44,3 +64,12 @@ type PartyFinder interface {
 func GetPartyFinder(playerMinNumber, playerMaxNumber int, current []models.MatchmakingRequest) (pf PartyFinder) {
 	return newNormal(playerMinNumber, playerMaxNumber, current)
 }
+
+// GetPartyFinderWithStrategy returns the party finder of the strategy, newExact() for the exact strategy
+// and GetPartyFinder() otherwise. The exact party finder always keeps the ticket of pivotID when it is a candidate.
+func GetPartyFinderWithStrategy(strategy string, pivotID string, playerMinNumber, playerMaxNumber int, current []models.MatchmakingRequest) (pf PartyFinder) {
+	if strategy == PartyFinderStrategyExact {
+		return newExact(pivotID, playerMinNumber, playerMaxNumber, current)
+	}
+	return GetPartyFinder(playerMinNumber, playerMaxNumber, current)
+}
@@ -0,0 +1,155 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package defaultmatchmaker
+
+import (
+	"github.com/AccelByte/extend-core-matchmaker/pkg/models"
+)
+
+// exact implements the PartyFinder interface by solving the subset sum of the party sizes.
+// The assigned tickets are candidates, IsFulfilled picks among them the combination with the most players
+// up to the maximum, so a full team is found whenever the candidates can make one.
+// Among the combinations with that many players it prefers the earlier candidates, which keeps the order
+// of the search: the pivot is always kept when it is a candidate, then the priority tickets and the better scores.
+type exact struct {
+	pivotID   string // Party ID of the pivot ticket, kept whenever it is a candidate
+	minPlayer int    // Minimum number of players required for a match
+	maxPlayer int    // Maximum number of players allowed in a match
+
+	current    []models.MatchmakingRequest // Current party combination being evaluated
+	best       []models.MatchmakingRequest // Best party combination found so far
+	candidates []models.MatchmakingRequest // Assigned tickets the combination is chosen from
+	result     []models.MatchmakingRequest // Current result set
+}
+
+// newExact creates a new exact party finder instance.
+func newExact(
+	pivotID string,
+	minPlayer int,
+	maxPlayer int,
+	current []models.MatchmakingRequest,
+) PartyFinder {
+	return &exact{
+		pivotID:   pivotID,
+		minPlayer: minPlayer,
+		maxPlayer: maxPlayer,
+
+		current: current,
+		best:    current,
+		result:  current,
+	}
+}
+
+// Reset resets the party finder to its initial state.
+func (f *exact) Reset() {
+	f.candidates = nil
+	f.result = f.current
+}
+
+// GetCurrentResult returns the current party combination and the candidates not chosen yet.
+// The blocked players check runs against it, so a candidate blocked by an earlier candidate is left out.
+func (f *exact) GetCurrentResult() []models.MatchmakingRequest {
+	return f.result
+}
+
+// GetBestResult returns the best party combination found so far.
+func (f *exact) GetBestResult() []models.MatchmakingRequest {
+	return f.best
+}
+
+// AssignMembers returns true when the ticket fits in the team next to the current party combination.
+func (f *exact) AssignMembers(ticket models.MatchmakingRequest) (success bool) {
+	return countPlayers(f.current)+len(ticket.PartyMembers) <= f.maxPlayer
+}
+
+// AppendResult adds a ticket to the candidates.
+func (f *exact) AppendResult(ticket models.MatchmakingRequest) {
+	f.candidates = append(f.candidates, ticket)
+	f.result = append(f.result, ticket)
+}
+
+// IsFulfilled chooses the combination of candidates and returns true when it fills the team.
+func (f *exact) IsFulfilled() bool {
+	currentCount := countPlayers(f.current)
+	chosen, ok := choosePartySizes(f.candidates, f.pivotID, f.maxPlayer-currentCount, f.minPlayer-currentCount)
+	if !ok {
+		f.result = f.current
+		return false
+	}
+
+	result := make([]models.MatchmakingRequest, 0, len(f.current)+len(chosen))
+	result = append(result, f.current...)
+	result = append(result, chosen...)
+	f.result = result
+
+	playerCount := countPlayers(result)
+	if playerCount > countPlayers(f.best) {
+		f.best = result
+	}
+
+	return playerCount == f.maxPlayer
+}
+
+// choosePartySizes returns the candidates with the most players up to capacity and at least minimum,
+// preferring the earlier candidates, the candidate of pivotID is always chosen when there is one.
+// It returns false when no combination has the minimum.
+func choosePartySizes(candidates []models.MatchmakingRequest, pivotID string, capacity, minimum int) ([]models.MatchmakingRequest, bool) {
+	if len(candidates) == 0 || capacity <= 0 {
+		return nil, minimum <= 0
+	}
+
+	// the pivot is chosen first, the others are chosen from the rest
+	var chosen []models.MatchmakingRequest
+	rest := make([]models.MatchmakingRequest, 0, len(candidates))
+	for _, candidate := range candidates {
+		if pivotID != "" && candidate.PartyID == pivotID && len(chosen) == 0 {
+			chosen = append(chosen, candidate)
+			continue
+		}
+		rest = append(rest, candidate)
+	}
+	first := countPlayers(chosen)
+	if first > capacity {
+		return nil, false
+	}
+	capacity -= first
+
+	// reachable[i][s] is true when some of rest[i:] have s players in total
+	reachable := make([][]bool, len(rest)+1)
+	reachable[len(rest)] = make([]bool, capacity+1)
+	reachable[len(rest)][0] = true
+	for i := len(rest) - 1; i >= 0; i-- {
+		size := len(rest[i].PartyMembers)
+		row := make([]bool, capacity+1)
+		copy(row, reachable[i+1])
+		for s := size; s <= capacity; s++ {
+			if reachable[i+1][s-size] {
+				row[s] = true
+			}
+		}
+		reachable[i] = row
+	}
+
+	target := capacity
+	for target > 0 && !reachable[0][target] {
+		target--
+	}
+	if first+target < minimum {
+		return nil, false
+	}
+
+	// walk the candidates in order, taking each one that still leaves the target reachable
+	for i, candidate := range rest {
+		size := len(candidate.PartyMembers)
+		if size <= target && reachable[i+1][target-size] {
+			chosen = append(chosen, candidate)
+			target -= size
+		}
+		if target == 0 {
+			break
+		}
+	}
+	return chosen, true
+}
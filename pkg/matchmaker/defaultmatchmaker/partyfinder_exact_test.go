@@ -0,0 +1,174 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package defaultmatchmaker
+
+import (
+	"testing"
+
+	"github.com/AccelByte/extend-core-matchmaker/pkg/config"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_exact_findParty(t *testing.T) {
+	type args struct {
+		Tickets     []models.MatchmakingRequest
+		PivotTicket models.MatchmakingRequest
+		MinPlayer   int
+		MaxPlayer   int
+		Current     []models.MatchmakingRequest
+	}
+	type testCase struct {
+		name       string
+		args       args
+		want       []models.MatchmakingRequest
+		wantGreedy []models.MatchmakingRequest
+	}
+
+	var (
+		tests   = []testCase{}
+		tickets []models.MatchmakingRequest
+	)
+
+	// case 1
+	tickets = generateRequestWithMemberCount("", []int{3, 1, 2})
+	tests = append(tests, testCase{
+		name: "exact | min 2 max 5 | greedy takes 3+1",
+		args: args{
+			Tickets:     tickets,
+			PivotTicket: tickets[0],
+			MinPlayer:   2,
+			MaxPlayer:   5,
+		},
+		want:       []models.MatchmakingRequest{tickets[0], tickets[2]},
+		wantGreedy: []models.MatchmakingRequest{tickets[0], tickets[1]},
+	})
+
+	// case 2
+	tickets = generateRequestWithMemberCount("", []int{1, 2, 2, 1, 1})
+	tests = append(tests, testCase{
+		name: "exact | min 2 max 3 | earlier tickets are preferred",
+		args: args{
+			Tickets:     tickets,
+			PivotTicket: tickets[0],
+			MinPlayer:   2,
+			MaxPlayer:   3,
+		},
+		want:       []models.MatchmakingRequest{tickets[0], tickets[1]},
+		wantGreedy: []models.MatchmakingRequest{tickets[0], tickets[1]},
+	})
+
+	// case 3
+	tickets = generateRequestWithMemberCount("", []int{2, 2, 2, 1})
+	tickets[2].Priority = 1
+	tests = append(tests, testCase{
+		name: "exact | min 2 max 4 | priority ticket is preferred",
+		args: args{
+			Tickets:     tickets,
+			PivotTicket: tickets[0],
+			MinPlayer:   2,
+			MaxPlayer:   4,
+		},
+		want:       []models.MatchmakingRequest{tickets[0], tickets[2]},
+		wantGreedy: []models.MatchmakingRequest{tickets[0], tickets[2]},
+	})
+
+	// case 4
+	tickets = generateRequestWithMemberCount("", []int{4, 1, 3, 2})
+	tests = append(tests, testCase{
+		name: "exact | min 6 max 6 | pivot is kept",
+		args: args{
+			Tickets:     tickets,
+			PivotTicket: tickets[0],
+			MinPlayer:   6,
+			MaxPlayer:   6,
+		},
+		want:       []models.MatchmakingRequest{tickets[0], tickets[3]},
+		wantGreedy: []models.MatchmakingRequest{},
+	})
+
+	// case 5
+	tickets = generateRequestWithMemberCount("", []int{3, 3})
+	tests = append(tests, testCase{
+		name: "exact | min 4 max 5 | no combination has the minimum",
+		args: args{
+			Tickets:     tickets,
+			PivotTicket: tickets[0],
+			MinPlayer:   4,
+			MaxPlayer:   5,
+		},
+		want:       []models.MatchmakingRequest{},
+		wantGreedy: []models.MatchmakingRequest{},
+	})
+
+	// case 6
+	current := generateRequestWithMemberCount("", []int{2})
+	tickets = generateRequestWithMemberCount("", []int{2, 2, 1})
+	tests = append(tests, testCase{
+		name: "exact | min 2 max 5 | with current",
+		args: args{
+			Tickets:     tickets,
+			PivotTicket: tickets[0],
+			MinPlayer:   2,
+			MaxPlayer:   5,
+			Current:     current,
+		},
+		want:       []models.MatchmakingRequest{current[0], tickets[0], tickets[2]},
+		wantGreedy: []models.MatchmakingRequest{current[0], tickets[0], tickets[2]},
+	})
+
+	// case 7
+	pivot := generateRequestWithMemberCount("", []int{5})[0]
+	tickets = generateRequestWithMemberCount("", []int{4, 3, 2})
+	tests = append(tests, testCase{
+		name: "exact | min 5 max 5 | team without the pivot",
+		args: args{
+			Tickets:     tickets,
+			PivotTicket: pivot,
+			MinPlayer:   5,
+			MaxPlayer:   5,
+		},
+		want:       []models.MatchmakingRequest{tickets[1], tickets[2]},
+		wantGreedy: []models.MatchmakingRequest{},
+	})
+
+	// case 8
+	current = generateRequestWithMemberCount("", []int{1})
+	tickets = generateRequestWithMemberCount("", []int{2, 4, 3})
+	tests = append(tests, testCase{
+		name: "exact | min 5 max 5 | pivot already in current",
+		args: args{
+			Tickets:     tickets,
+			PivotTicket: current[0],
+			MinPlayer:   5,
+			MaxPlayer:   5,
+			Current:     current,
+		},
+		want:       []models.MatchmakingRequest{current[0], tickets[1]},
+		wantGreedy: []models.MatchmakingRequest{current[0]},
+	})
+
+	exactConfig := &config.Config{PartyFinderStrategy: PartyFinderStrategyExact}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FindPartyCombination(exactConfig, tt.args.Tickets, tt.args.PivotTicket, tt.args.MinPlayer, tt.args.MaxPlayer, tt.args.Current, "")
+			if !assert.ElementsMatch(t, got, tt.want) {
+				t.Errorf("exact.findParty() = %v, want %v", got, tt.want)
+			}
+
+			greedy := FindPartyCombination(nil, tt.args.Tickets, tt.args.PivotTicket, tt.args.MinPlayer, tt.args.MaxPlayer, tt.args.Current, "")
+			assert.ElementsMatch(t, greedy, tt.wantGreedy)
+		})
+	}
+}
+
+func TestValidatePartyFinderStrategy(t *testing.T) {
+	t.Parallel()
+
+	assert.NoError(t, ValidatePartyFinderStrategy(PartyFinderStrategyGreedy))
+	assert.NoError(t, ValidatePartyFinderStrategy(PartyFinderStrategyExact))
+	assert.Error(t, ValidatePartyFinderStrategy("knapsack"))
+}
@@ -0,0 +1,225 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package defaultmatchmaker
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/AccelByte/extend-core-matchmaker/pkg/models"
+)
+
+// compatibilitySampleSize bounds how many other tickets each ticket is compared with by the most constrained strategy,
+// so its pre-pass stays linear in the tickets of a large pool and doesn't eat the matchmaking time budget.
+const compatibilitySampleSize = 256
+
+// pivotSelector picks the pivot tickets of one MatchPlayers call by the pivot strategy of the ruleset.
+// The tickets on cooldown after failing as a pivot in the previous ticks are skipped, they stay candidates of the other pivots.
+type pivotSelector struct {
+	strategy  models.PivotStrategy
+	cooldowns *models.PivotCooldowns
+
+	compatible      map[string]int    // most constrained: how many tickets each ticket can be matched with
+	preferredRegion map[string]string // region round robin: the best region of each ticket
+	regions         []string          // region round robin: the preferred regions, in order of their oldest ticket
+	nextRegion      int               // region round robin: the region the next pivot is picked from
+
+	now    time.Time
+	random func() float64 // weighted random by age
+}
+
+// newPivotSelector prepares the pivot strategy of the channel for the requests, sorted by sortOldestFirst.
+func newPivotSelector(channel *models.Channel, requests []models.MatchmakingRequest) *pivotSelector {
+	s := &pivotSelector{
+		strategy:  channel.Ruleset.PivotSelection.GetStrategy(),
+		cooldowns: channel.PivotCooldowns,
+		now:       time.Now(),
+		random:    rand.Float64, //nolint:gosec
+	}
+
+	switch s.strategy {
+	case models.PivotStrategyMostConstrained:
+		s.compatible = countCompatibleTickets(channel, requests, compatibilitySampleSize)
+	case models.PivotStrategyRegionRoundRobin:
+		s.preferredRegion = make(map[string]string, len(requests))
+		seen := make(map[string]struct{})
+		for i := range requests {
+			region := ""
+			if window := weightedRegionWindow(&requests[i], channel); len(window) > 0 {
+				region = window[0].Region
+			}
+			s.preferredRegion[requests[i].PartyID] = region
+			if _, ok := seen[region]; !ok {
+				seen[region] = struct{}{}
+				s.regions = append(s.regions, region)
+			}
+		}
+	}
+	return s
+}
+
+// cooldownCount returns how many of the requests are on cooldown
+func (s *pivotSelector) cooldownCount(requests []models.MatchmakingRequest) int {
+	count := 0
+	for _, request := range requests {
+		if s.cooldowns.IsCoolingDown(request.PartyID) {
+			count++
+		}
+	}
+	return count
+}
+
+// next returns the index of the next pivot in the requests, sorted by sortOldestFirst.
+// It picks among the tickets of the highest priority that are not on cooldown, and returns false when every ticket is.
+func (s *pivotSelector) next(requests []models.MatchmakingRequest) (int, bool) {
+	eligible := make([]int, 0, len(requests))
+	for i, request := range requests {
+		if s.cooldowns.IsCoolingDown(request.PartyID) {
+			continue
+		}
+		if len(eligible) > 0 && request.Priority < requests[eligible[0]].Priority {
+			break
+		}
+		eligible = append(eligible, i)
+	}
+	if len(eligible) == 0 {
+		return 0, false
+	}
+
+	switch s.strategy {
+	case models.PivotStrategyMostConstrained:
+		best := eligible[0]
+		for _, i := range eligible[1:] {
+			if s.compatible[requests[i].PartyID] < s.compatible[requests[best].PartyID] {
+				best = i
+			}
+		}
+		return best, true
+
+	case models.PivotStrategyLargestParty:
+		best := eligible[0]
+		for _, i := range eligible[1:] {
+			if requests[i].CountPlayer() > requests[best].CountPlayer() {
+				best = i
+			}
+		}
+		return best, true
+
+	case models.PivotStrategyRegionRoundRobin:
+		for step := 0; step < len(s.regions); step++ {
+			regionIndex := (s.nextRegion + step) % len(s.regions)
+			for _, i := range eligible {
+				if s.preferredRegion[requests[i].PartyID] == s.regions[regionIndex] {
+					s.nextRegion = regionIndex + 1
+					return i, true
+				}
+			}
+		}
+		return eligible[0], true
+
+	case models.PivotStrategyWeightedRandomAge:
+		// the weight is the age in seconds, plus one so the new tickets can be picked too
+		weights := make([]float64, len(eligible))
+		total := 0.0
+		for j, i := range eligible {
+			age := s.now.Sub(time.Unix(requests[i].CreatedAt, 0)).Seconds()
+			if age < 0 {
+				age = 0
+			}
+			weights[j] = age + 1
+			total += weights[j]
+		}
+		pick := s.random() * total
+		for j, weight := range weights {
+			if pick < weight {
+				return eligible[j], true
+			}
+			pick -= weight
+		}
+		return eligible[len(eligible)-1], true
+
+	default:
+		return eligible[0], true
+	}
+}
+
+// compatibilityWindow is what a ticket accepts of another ticket: the distances of the matching rules flexed by its age
+// and the regions of its filterRegionByStep window.
+type compatibilityWindow struct {
+	distances []distance
+	regions   map[string]struct{}
+}
+
+// countCompatibleTickets returns how many other tickets each ticket accepts and is accepted by,
+// on the distance matching rules and the region windows. Above sampleSize other tickets the count is
+// an estimate on sampleSize tickets spread evenly over the requests, the same amount for every ticket.
+func countCompatibleTickets(channel *models.Channel, requests []models.MatchmakingRequest, sampleSize int) map[string]int {
+	windows := make([]compatibilityWindow, len(requests))
+	for i := range requests {
+		ruleset, _ := applyRuleFlexing(channel.Ruleset, time.Unix(requests[i].CreatedAt, 0))
+		windows[i].distances = getFilterByDistance(&ruleset, requests[i].PartyAttributes)
+		if window := filterRegionByStep(&requests[i], channel); len(window) > 0 {
+			windows[i].regions = make(map[string]struct{}, len(window))
+			for _, region := range window {
+				windows[i].regions[region.Region] = struct{}{}
+			}
+		}
+	}
+
+	compatible := make(map[string]int, len(requests))
+	others := len(requests) - 1
+	if sampleSize <= 0 || others <= sampleSize {
+		for i := range requests {
+			for j := i + 1; j < len(requests); j++ {
+				if isWindowCompatible(windows[i], windows[j]) {
+					compatible[requests[i].PartyID]++
+					compatible[requests[j].PartyID]++
+				}
+			}
+		}
+		return compatible
+	}
+
+	for i := range requests {
+		for s := 0; s < sampleSize; s++ {
+			// the offsets are distinct and between 1 and others, so the ticket is never compared with itself
+			j := (i + 1 + s*others/sampleSize) % len(requests)
+			if isWindowCompatible(windows[i], windows[j]) {
+				compatible[requests[i].PartyID]++
+			}
+		}
+	}
+	return compatible
+}
+
+// isWindowCompatible returns true when both tickets are inside the distances of each other and share a region,
+// a ticket without latencies accepts any region
+func isWindowCompatible(a, b compatibilityWindow) bool {
+	for _, da := range a.distances {
+		found := false
+		for _, db := range b.distances {
+			if da.attribute != db.attribute {
+				continue
+			}
+			found = true
+			if db.value < da.min || db.value > da.max || da.value < db.min || da.value > db.max {
+				return false
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if a.regions == nil || b.regions == nil {
+		return true
+	}
+	for region := range a.regions {
+		if _, ok := b.regions[region]; ok {
+			return true
+		}
+	}
+	return false
+}
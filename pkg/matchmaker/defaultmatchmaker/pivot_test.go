@@ -0,0 +1,286 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package defaultmatchmaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AccelByte/extend-core-matchmaker/pkg/config"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/matchmaker"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/models"
+	player "github.com/AccelByte/extend-core-matchmaker/pkg/playerdata"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/testsetup"
+	. "github.com/onsi/gomega"
+)
+
+func pivotChannel(strategy models.PivotStrategy) models.Channel {
+	ruleset := get1v1Rules()
+	ruleset.PivotSelection = models.PivotSelection{Strategy: strategy, CooldownTicks: 2}
+	return models.Channel{Ruleset: ruleset, PivotCooldowns: models.NewPivotCooldowns()}
+}
+
+// pivotTickets returns tickets created a minute apart, the first one is the oldest
+func pivotTickets(mmrs ...int) []models.MatchmakingRequest {
+	tickets := make([]models.MatchmakingRequest, 0, len(mmrs))
+	for i, mmr := range mmrs {
+		ticket := generateRequestWithMMR("pivot", 1, 1, mmr)[0]
+		ticket.CreatedAt = time.Now().Add(-time.Duration(len(mmrs)-i) * time.Minute).Unix()
+		tickets = append(tickets, ticket)
+	}
+	return tickets
+}
+
+func TestPivotSelector(t *testing.T) {
+	t.Parallel()
+
+	t.Run("oldest first", func(t *testing.T) {
+		g := testsetup.ParallelWithGomega(t)
+
+		channel := pivotChannel("")
+		tickets := pivotTickets(100, 100, 100)
+		index, ok := newPivotSelector(&channel, tickets).next(tickets)
+		g.Expect(ok).To(BeTrue())
+		g.Expect(index).To(Equal(0))
+	})
+
+	t.Run("priority first", func(t *testing.T) {
+		g := testsetup.ParallelWithGomega(t)
+
+		channel := pivotChannel(models.PivotStrategyLargestParty)
+		tickets := pivotTickets(100, 100)
+		tickets[0].Priority = 1
+		tickets[1].PartyMembers = append(tickets[1].PartyMembers, tickets[1].PartyMembers...)
+		index, _ := newPivotSelector(&channel, tickets).next(tickets)
+		g.Expect(index).To(Equal(0))
+	})
+
+	t.Run("largest party", func(t *testing.T) {
+		g := testsetup.ParallelWithGomega(t)
+
+		channel := pivotChannel(models.PivotStrategyLargestParty)
+		tickets := pivotTickets(100, 100, 100)
+		tickets[2].PartyMembers = append(tickets[2].PartyMembers, tickets[2].PartyMembers...)
+		index, _ := newPivotSelector(&channel, tickets).next(tickets)
+		g.Expect(index).To(Equal(2))
+	})
+
+	t.Run("most constrained", func(t *testing.T) {
+		g := testsetup.ParallelWithGomega(t)
+
+		channel := pivotChannel(models.PivotStrategyMostConstrained)
+		channel.Ruleset.MatchingRule[0].Reference = 100
+		tickets := pivotTickets(100, 120, 140, 900)
+		index, _ := newPivotSelector(&channel, tickets).next(tickets)
+		g.Expect(index).To(Equal(3))
+	})
+
+	t.Run("region round robin", func(t *testing.T) {
+		g := testsetup.ParallelWithGomega(t)
+
+		channel := pivotChannel(models.PivotStrategyRegionRoundRobin)
+		tickets := pivotTickets(100, 100, 100, 100)
+		for i, region := range []string{"us", "us", "eu", "us"} {
+			tickets[i].SortedLatency = []models.Region{{Region: region, Latency: 50}}
+		}
+		selector := newPivotSelector(&channel, tickets)
+
+		var pivots []string
+		for len(tickets) > 0 {
+			index, ok := selector.next(tickets)
+			g.Expect(ok).To(BeTrue())
+			pivots = append(pivots, tickets[index].SortedLatency[0].Region)
+			tickets = append(tickets[:index], tickets[index+1:]...)
+		}
+		g.Expect(pivots).To(Equal([]string{"us", "eu", "us", "us"}))
+	})
+
+	t.Run("weighted random by age", func(t *testing.T) {
+		g := testsetup.ParallelWithGomega(t)
+
+		channel := pivotChannel(models.PivotStrategyWeightedRandomAge)
+		tickets := pivotTickets(100, 100)
+		selector := newPivotSelector(&channel, tickets)
+
+		// the oldest ticket is 120 seconds old, the other 60 seconds
+		selector.random = func() float64 { return 0.1 }
+		index, _ := selector.next(tickets)
+		g.Expect(index).To(Equal(0))
+
+		selector.random = func() float64 { return 0.9 }
+		index, _ = selector.next(tickets)
+		g.Expect(index).To(Equal(1))
+	})
+
+	t.Run("cooldown", func(t *testing.T) {
+		g := testsetup.ParallelWithGomega(t)
+
+		channel := pivotChannel("")
+		tickets := pivotTickets(100, 100)
+		channel.PivotCooldowns.Fail(tickets[0].PartyID, 1)
+		channel.PivotCooldowns.NextTick()
+
+		selector := newPivotSelector(&channel, tickets)
+		g.Expect(selector.cooldownCount(tickets)).To(Equal(1))
+		index, ok := selector.next(tickets)
+		g.Expect(ok).To(BeTrue())
+		g.Expect(index).To(Equal(1))
+
+		_, ok = selector.next(tickets[:1])
+		g.Expect(ok).To(BeFalse())
+	})
+}
+
+func TestCountCompatibleTickets_Sampled(t *testing.T) {
+	g := testsetup.ParallelWithGomega(t)
+
+	channel := pivotChannel(models.PivotStrategyMostConstrained)
+	channel.Ruleset.MatchingRule[0].Reference = 100
+	tickets := pivotTickets(100, 110, 120, 130, 140, 150, 160, 900)
+
+	exact := countCompatibleTickets(&channel, tickets, 0)
+	g.Expect(exact[tickets[0].PartyID]).To(Equal(6))
+	g.Expect(exact[tickets[7].PartyID]).To(Equal(0))
+
+	// each ticket is compared with 3 others, the outlier is still the most constrained
+	sampled := countCompatibleTickets(&channel, tickets, 3)
+	for _, ticket := range tickets[:7] {
+		g.Expect(sampled[ticket.PartyID]).To(BeNumerically("<=", 3))
+	}
+	g.Expect(sampled[tickets[7].PartyID]).To(Equal(0))
+	g.Expect(sampled[tickets[0].PartyID]).To(BeNumerically(">", 0))
+}
+
+func TestPivotCooldowns(t *testing.T) {
+	g := testsetup.ParallelWithGomega(t)
+
+	cooldowns := models.NewPivotCooldowns()
+	cooldowns.NextTick()
+	cooldowns.Fail("party", 2)
+	g.Expect(cooldowns.IsCoolingDown("party")).To(BeFalse())
+
+	cooldowns.NextTick()
+	g.Expect(cooldowns.IsCoolingDown("party")).To(BeTrue())
+	cooldowns.NextTick()
+	g.Expect(cooldowns.IsCoolingDown("party")).To(BeTrue())
+	cooldowns.NextTick()
+	g.Expect(cooldowns.IsCoolingDown("party")).To(BeFalse())
+
+	var nilCooldowns *models.PivotCooldowns
+	nilCooldowns.Fail("party", 2)
+	g.Expect(nilCooldowns.IsCoolingDown("party")).To(BeFalse())
+}
+
+func TestMatchPlayers_PivotCooldown(t *testing.T) {
+	g := testsetup.ParallelWithGomega(t)
+
+	mm := NewMatchMaker(&config.Config{})
+	channel := pivotChannel("")
+	channel.Ruleset.MatchingRule[0].Reference = 100
+
+	// the oldest ticket fails as a pivot in the first tick
+	tickets := pivotTickets(100, 900)
+	channel.PivotCooldowns.NextTick()
+	results, _, err := mm.MatchPlayers(testsetup.NewTestScope(), "", "", tickets, channel)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(results).To(BeEmpty())
+
+	// it is matched by the pivot of a newer ticket while on cooldown
+	opponent := pivotTickets(100)[0]
+	channel.PivotCooldowns.NextTick()
+	results, _, err = mm.MatchPlayers(testsetup.NewTestScope(), "", "", []models.MatchmakingRequest{tickets[0], tickets[1], opponent}, channel)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(results).To(HaveLen(1))
+	g.Expect(results[0].PivotID).To(Equal(opponent.PartyID))
+	g.Expect(resultsContainParty(results, tickets[0].PartyID)).To(BeTrue())
+
+	// the ticket that failed as a pivot in the second tick is on cooldown too, no pivot is left
+	channel.PivotCooldowns.NextTick()
+	g.Expect(channel.PivotCooldowns.IsCoolingDown(tickets[1].PartyID)).To(BeTrue())
+	results, _, err = mm.MatchPlayers(testsetup.NewTestScope(), "", "", []models.MatchmakingRequest{tickets[0], tickets[1]}, channel)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(results).To(BeEmpty())
+}
+
+func TestMakeMatches_PivotCooldownPerPool(t *testing.T) {
+	g := testsetup.ParallelWithGomega(t)
+
+	mm := New(&config.Config{})
+	rules := get1v1Rules()
+	rules.MatchingRule[0].Reference = 100
+	rules.PivotSelection = models.PivotSelection{CooldownTicks: 2}
+
+	ticket := func(id, matchPool string, mmr float64, age time.Duration) matchmaker.Ticket {
+		return matchmaker.Ticket{
+			TicketID:  id,
+			Namespace: "test",
+			MatchPool: matchPool,
+			CreatedAt: time.Now().Add(-age),
+			Players:   []player.PlayerData{{PlayerID: player.IDFromString(id), Attributes: map[string]interface{}{"mmr": mmr}}},
+		}
+	}
+	makeMatches := func(tickets ...matchmaker.Ticket) []matchmaker.Match {
+		var results []matchmaker.Match
+		for match := range mm.MakeMatches(testsetup.NewTestScope(), testsetup.StubMatchTicketProvider{Tickets: tickets}, rules) {
+			results = append(results, match)
+		}
+		return results
+	}
+
+	// the oldest ticket of the pool fails as a pivot
+	oldest := ticket("oldest", "pool-a", 100, 3*time.Minute)
+	other := ticket("other", "pool-a", 900, 2*time.Minute)
+	g.Expect(makeMatches(oldest, other)).To(BeEmpty())
+
+	// the ticks of another pool don't count for its cooldown
+	for i := 0; i < 3; i++ {
+		makeMatches(ticket("b1", "pool-b", 100, time.Minute), ticket("b2", "pool-b", 900, time.Minute))
+	}
+
+	// it's still on cooldown in the next tick of its pool, a newer ticket is the pivot
+	results := makeMatches(oldest, other, ticket("newest", "pool-a", 100, time.Minute))
+	g.Expect(results).To(HaveLen(1))
+	g.Expect(results[0].PivotID).To(Equal("newest"))
+}
+
+func TestPoolPivotCooldowns(t *testing.T) {
+	g := testsetup.ParallelWithGomega(t)
+
+	pools := models.NewPoolPivotCooldowns(0)
+	g.Expect(pools.ForPool("test", "pool-a")).To(BeIdenticalTo(pools.ForPool("test", "pool-a")))
+	g.Expect(pools.ForPool("test", "pool-a")).ToNot(BeIdenticalTo(pools.ForPool("test", "pool-b")))
+	g.Expect(pools.ForPool("test", "pool-a")).ToNot(BeIdenticalTo(pools.ForPool("other", "pool-a")))
+}
+
+func TestPoolPivotCooldowns_ForgetsIdlePools(t *testing.T) {
+	g := testsetup.ParallelWithGomega(t)
+
+	pools := models.NewPoolPivotCooldowns(2)
+	pools.NextTick()
+	idle := pools.ForPool("test", "idle")
+	idle.Fail("party", 5)
+
+	for i := 0; i < 3; i++ {
+		pools.NextTick()
+		pools.ForPool("test", "active")
+	}
+	g.Expect(pools.Len()).To(Equal(1))
+
+	// the idle pool starts over without its cooldowns
+	g.Expect(pools.ForPool("test", "idle")).ToNot(BeIdenticalTo(idle))
+	g.Expect(pools.Len()).To(Equal(2))
+}
+
+func TestPivotSelection_Validate(t *testing.T) {
+	g := testsetup.ParallelWithGomega(t)
+
+	g.Expect(models.PivotSelection{Strategy: models.PivotStrategyMostConstrained, CooldownTicks: 3}.Validate()).To(Succeed())
+	g.Expect(models.PivotSelection{Strategy: "newest_first"}.Validate()).ToNot(Succeed())
+	g.Expect(models.PivotSelection{CooldownTicks: -1}.Validate()).ToNot(Succeed())
+
+	_, err := newMatchLogic().RulesFromJSON(testsetup.NewTestScope(),
+		`{"alliance":{"min_number":2,"max_number":2,"player_min_number":1,"player_max_number":1},"pivot_selection":{"strategy":"region_round_robin","cooldown_ticks":2}}`)
+	g.Expect(err).ToNot(HaveOccurred())
+}
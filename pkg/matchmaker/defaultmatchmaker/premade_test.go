@@ -0,0 +1,132 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package defaultmatchmaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AccelByte/extend-core-matchmaker/pkg/config"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/models"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/testsetup"
+	. "github.com/onsi/gomega"
+)
+
+// premadeRules returns the rules of a 5v5 where the largest party of the teams differ by at most maxDifference
+func premadeRules(maxDifference int) models.RuleSet {
+	ruleset := get1v1Rules()
+	ruleset.AllianceRule = models.AllianceRule{MinNumber: 2, MaxNumber: 2, PlayerMinNumber: 5, PlayerMaxNumber: 5, MaxPremadeSizeDifference: &maxDifference}
+	return ruleset
+}
+
+func TestAllianceRule_ValidatePremadeSize(t *testing.T) {
+	g := testsetup.ParallelWithGomega(t)
+
+	stack := generateRequestWithMMR("premade", 1, 3, 100)
+	duo := generateRequestWithMMR("premade", 1, 2, 100)
+	solos := generateRequestWithMMR("premade", 3, 1, 100)
+	allies := []models.MatchingAlly{createMatchingAlly(stack...), createMatchingAlly(append(duo, solos[0])...), {}}
+
+	rule := premadeRules(1).AllianceRule
+	g.Expect(rule.ValidatePremadeSize(allies)).To(Succeed())
+
+	allies[1] = createMatchingAlly(solos...)
+	g.Expect(rule.ValidatePremadeSize(allies)).ToNot(Succeed())
+
+	rule.MaxPremadeSizeDifference = nil
+	g.Expect(rule.ValidatePremadeSize(allies)).To(Succeed())
+}
+
+func TestMatchPlayers_PremadeSize(t *testing.T) {
+	t.Parallel()
+
+	t.Run("a full party is not matched against solos", func(t *testing.T) {
+		g := testsetup.ParallelWithGomega(t)
+
+		tickets := append(generateRequestWithMMR("premade", 1, 5, 100), generateRequestWithMMR("premade", 5, 1, 100)...)
+		results, _, err := NewMatchMaker(&config.Config{}).MatchPlayers(testsetup.NewTestScope(), "", "", tickets, models.Channel{Ruleset: premadeRules(2)})
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(results).To(BeEmpty())
+	})
+
+	t.Run("a full party is matched against another full party", func(t *testing.T) {
+		g := testsetup.ParallelWithGomega(t)
+
+		stacks := generateRequestWithMMR("premade", 2, 5, 100)
+		tickets := append([]models.MatchmakingRequest{stacks[0]}, generateRequestWithMMR("premade", 5, 1, 100)...)
+		tickets = append(tickets, stacks[1])
+		results, _, err := NewMatchMaker(&config.Config{}).MatchPlayers(testsetup.NewTestScope(), "", "", tickets, models.Channel{Ruleset: premadeRules(0)})
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(results).To(HaveLen(1))
+		g.Expect(resultsContainParty(results, stacks[0].PartyID)).To(BeTrue())
+		g.Expect(resultsContainParty(results, stacks[1].PartyID)).To(BeTrue())
+	})
+
+	t.Run("flexing relaxes the difference", func(t *testing.T) {
+		g := testsetup.ParallelWithGomega(t)
+
+		ruleset := premadeRules(0)
+		relaxed := 4
+		flexed := ruleset.AllianceRule
+		flexed.MaxPremadeSizeDifference = &relaxed
+		ruleset.AllianceFlexingRule = []models.AllianceFlexingRule{{Duration: 30, AllianceRule: flexed}}
+
+		tickets := append(generateRequestWithMMR("premade", 1, 5, 100), generateRequestWithMMR("premade", 5, 1, 100)...)
+		for i := range tickets {
+			tickets[i].CreatedAt = Now().Unix()
+		}
+		results, _, err := NewMatchMaker(&config.Config{}).MatchPlayers(testsetup.NewTestScope(), "", "", tickets, models.Channel{Ruleset: ruleset})
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(results).To(BeEmpty())
+
+		for i := range tickets {
+			tickets[i].CreatedAt = Now().Add(-time.Minute).Unix()
+		}
+		results, _, err = NewMatchMaker(&config.Config{}).MatchPlayers(testsetup.NewTestScope(), "", "", tickets, models.Channel{Ruleset: ruleset})
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(results).To(HaveLen(1))
+	})
+}
+
+func TestApplyAllianceFlexingRule_PremadeSize(t *testing.T) {
+	g := testsetup.ParallelWithGomega(t)
+
+	rule := premadeRules(0).AllianceRule
+	withoutLimit := rule
+	withoutLimit.MaxPremadeSizeDifference = nil
+
+	// a flexing rule without a premade size difference keeps the one of the rule
+	flexed, isFlexed := ApplyAllianceFlexingRule(rule, []models.AllianceFlexingRule{{Duration: 0, AllianceRule: withoutLimit}}, Now().Add(-time.Minute))
+	g.Expect(isFlexed).To(BeTrue())
+	g.Expect(flexed.MaxPremadeSizeDifference).To(HaveValue(Equal(0)))
+}
+
+func TestMatchSessions_PremadeSize(t *testing.T) {
+	g := testsetup.ParallelWithGomega(t)
+
+	ruleset := premadeRules(0)
+	ruleset.AllianceRule.PlayerMinNumber = 2
+	ruleset.AllianceRule.PlayerMaxNumber = 3
+	session := generateSession("premade", 2, []int{1, 1})
+	duo := generateRequestWithMMR("premade", 1, 2, 100)[0]
+	solo := generateRequestWithMMR("premade", 1, 1, 100)[0]
+
+	_, _, matchedTickets, err := NewMatchMaker(&config.Config{}).MatchSessions(testsetup.NewTestScope(), "", "",
+		[]models.MatchmakingRequest{duo, solo}, []*models.MatchmakingResult{session}, models.Channel{Ruleset: ruleset})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(matchedTickets).To(ConsistOf(HaveField("PartyID", solo.PartyID)))
+	g.Expect(containsTicket(session, &duo)).To(BeFalse())
+}
+
+func TestAllianceRule_Validate_PremadeSize(t *testing.T) {
+	g := testsetup.ParallelWithGomega(t)
+
+	rule := premadeRules(-1).AllianceRule
+	g.Expect(rule.Validate()).ToNot(Succeed())
+
+	_, err := newMatchLogic().RulesFromJSON(testsetup.NewTestScope(),
+		`{"alliance":{"min_number":2,"max_number":2,"player_min_number":5,"player_max_number":5,"max_premade_size_difference":1},"alliance_flexing_rule":[{"duration":60,"min_number":2,"max_number":2,"player_min_number":5,"player_max_number":5,"max_premade_size_difference":3}]}`)
+	g.Expect(err).ToNot(HaveOccurred())
+}
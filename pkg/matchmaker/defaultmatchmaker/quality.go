@@ -0,0 +1,143 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package defaultmatchmaker
+
+import (
+	"math"
+
+	"github.com/AccelByte/extend-core-matchmaker/pkg/models"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/utils"
+)
+
+// qualityLatencyScaleMs is the latency scored 0 when the ruleset has no region_latency_max_ms
+const qualityLatencyScaleMs = 500
+
+// matchQualityScore is the quality of one match, every score is between 0 and 1, the higher the better.
+type matchQualityScore struct {
+	score       float64
+	teamBalance float64
+	skillSpread float64
+	latency     float64
+	partyMix    float64
+}
+
+// attributes returns the scores as the match quality match attribute
+func (s matchQualityScore) attributes() map[string]interface{} {
+	return map[string]interface{}{
+		"score":        s.score,
+		"team_balance": s.teamBalance,
+		"skill_spread": s.skillSpread,
+		"latency":      s.latency,
+		"party_mix":    s.partyMix,
+	}
+}
+
+// scoreMatch scores the allies of a match in the region with the match quality of the ruleset.
+// The skill differences are relative to the distance or rating reference of the skill attribute, or its normalization max when set,
+// and the latency to the region_latency_max_ms of the ruleset. A score without the data to compute it,
+// e.g. without a skill attribute, latencies or a second team, is 1.
+func scoreMatch(ruleset *models.RuleSet, allies []models.MatchingAlly, requests []models.MatchmakingRequest, region string) matchQualityScore {
+	s := matchQualityScore{teamBalance: 1, skillSpread: 1, latency: 1, partyMix: 1}
+
+	// Skill: team balance and spread
+	if attribute, scale := qualitySkillAttribute(ruleset); scale > 0 {
+		lowest, highest := math.Inf(1), math.Inf(-1)
+		lowestTeam, highestTeam := math.Inf(1), math.Inf(-1)
+		teams := 0
+		for _, ally := range allies {
+			total, players := 0.0, 0
+			for _, party := range ally.MatchingParties {
+				memberAttributes, _ := party.PartyAttributes[memberAttributesKey].(map[string]interface{})
+				value, ok := utils.ToFloat64(memberAttributes[attribute])
+				if !ok {
+					continue
+				}
+				lowest = math.Min(lowest, value)
+				highest = math.Max(highest, value)
+				total += value * float64(party.CountPlayer())
+				players += party.CountPlayer()
+			}
+			if players == 0 {
+				continue
+			}
+			mean := total / float64(players)
+			lowestTeam = math.Min(lowestTeam, mean)
+			highestTeam = math.Max(highestTeam, mean)
+			teams++
+		}
+		if highest >= lowest {
+			s.skillSpread = qualityRatio(highest-lowest, scale)
+		}
+		if teams > 1 {
+			s.teamBalance = qualityRatio(highestTeam-lowestTeam, scale)
+		}
+	}
+
+	// Latency: the worst latency to the region
+	if region != "" {
+		scale := float64(qualityLatencyScaleMs)
+		if ruleset.RegionLatencyMaxMs > 0 {
+			scale = float64(ruleset.RegionLatencyMaxMs)
+		}
+		worst := -1
+		for _, request := range requests {
+			if latency, ok := request.LatencyMap[region]; ok && latency > worst {
+				worst = latency
+			}
+		}
+		if worst >= 0 {
+			s.latency = qualityRatio(float64(worst), scale)
+		}
+	}
+
+	// Party mix: the share of players in a party of 2 or more of every team
+	if len(allies) > 1 {
+		lowestShare, highestShare := 1.0, 0.0
+		for _, ally := range allies {
+			premade, players := 0, 0
+			for _, party := range ally.MatchingParties {
+				if party.CountPlayer() > 1 {
+					premade += party.CountPlayer()
+				}
+				players += party.CountPlayer()
+			}
+			if players == 0 {
+				continue
+			}
+			share := float64(premade) / float64(players)
+			lowestShare = math.Min(lowestShare, share)
+			highestShare = math.Max(highestShare, share)
+		}
+		if highestShare >= lowestShare {
+			s.partyMix = 1 - (highestShare - lowestShare)
+		}
+	}
+
+	weights := ruleset.MatchQuality.GetWeights()
+	totalWeight := weights.TeamBalance + weights.SkillSpread + weights.Latency + weights.PartyMix
+	s.score = (s.teamBalance*weights.TeamBalance + s.skillSpread*weights.SkillSpread +
+		s.latency*weights.Latency + s.partyMix*weights.PartyMix) / totalWeight
+	return s
+}
+
+// qualitySkillAttribute returns the skill attribute of the match quality and the difference of skill scored 0
+func qualitySkillAttribute(ruleset *models.RuleSet) (string, float64) {
+	attribute := ruleset.MatchQuality.Attribute
+	for _, rule := range ruleset.MatchingRule {
+		if !rule.IsDistanceLike() || (attribute != "" && rule.Attribute != attribute) {
+			continue
+		}
+		if rule.NormalizationMax > 0 {
+			return rule.Attribute, rule.NormalizationMax
+		}
+		return rule.Attribute, rule.Reference
+	}
+	return attribute, 0
+}
+
+// qualityRatio returns 1 for no difference down to 0 for a difference of the scale or more
+func qualityRatio(difference, scale float64) float64 {
+	return math.Max(0, 1-difference/scale)
+}
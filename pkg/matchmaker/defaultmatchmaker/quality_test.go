@@ -0,0 +1,154 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package defaultmatchmaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AccelByte/extend-core-matchmaker/pkg/config"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/models"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/testsetup"
+	. "github.com/onsi/gomega"
+)
+
+func TestScoreMatch(t *testing.T) {
+	t.Parallel()
+
+	ruleset := get1v1Rules()
+	ruleset.AllianceRule = models.AllianceRule{MinNumber: 2, MaxNumber: 2, PlayerMinNumber: 2, PlayerMaxNumber: 2}
+	ruleset.RegionLatencyMaxMs = 200
+
+	t.Run("balanced teams", func(t *testing.T) {
+		g := testsetup.ParallelWithGomega(t)
+
+		low := generateRequestWithMMR("quality", 2, 1, 400)
+		high := generateRequestWithMMR("quality", 2, 1, 600)
+		allies := []models.MatchingAlly{createMatchingAlly(low[0], high[0]), createMatchingAlly(low[1], high[1])}
+
+		score := scoreMatch(&ruleset, allies, nil, "")
+		g.Expect(score.teamBalance).To(BeNumerically("~", 1))
+		g.Expect(score.skillSpread).To(BeNumerically("~", 0.8))
+		g.Expect(score.latency).To(BeNumerically("~", 1))
+		g.Expect(score.partyMix).To(BeNumerically("~", 1))
+		g.Expect(score.score).To(BeNumerically("~", 0.95))
+	})
+
+	t.Run("unbalanced teams", func(t *testing.T) {
+		g := testsetup.ParallelWithGomega(t)
+
+		low := generateRequestWithMMR("quality", 2, 1, 400)
+		high := generateRequestWithMMR("quality", 2, 1, 600)
+		allies := []models.MatchingAlly{createMatchingAlly(low...), createMatchingAlly(high...)}
+
+		score := scoreMatch(&ruleset, allies, nil, "")
+		g.Expect(score.teamBalance).To(BeNumerically("~", 0.8))
+		g.Expect(score.skillSpread).To(BeNumerically("~", 0.8))
+	})
+
+	t.Run("latency and party mix", func(t *testing.T) {
+		g := testsetup.ParallelWithGomega(t)
+
+		duo := generateRequestWithMMR("quality", 1, 2, 500)
+		solos := generateRequestWithMMR("quality", 2, 1, 500)
+		duo[0].LatencyMap = map[string]int{"us": 50}
+		solos[0].LatencyMap = map[string]int{"us": 150}
+		requests := append(append([]models.MatchmakingRequest{}, duo...), solos...)
+		allies := []models.MatchingAlly{createMatchingAlly(duo...), createMatchingAlly(solos...)}
+
+		score := scoreMatch(&ruleset, allies, requests, "us")
+		g.Expect(score.latency).To(BeNumerically("~", 0.25))
+		g.Expect(score.partyMix).To(BeNumerically("~", 0))
+		g.Expect(score.teamBalance).To(BeNumerically("~", 1))
+	})
+
+	t.Run("weights", func(t *testing.T) {
+		g := testsetup.ParallelWithGomega(t)
+
+		weighted := ruleset
+		weighted.MatchQuality = models.MatchQuality{Weights: models.MatchQualityWeights{TeamBalance: 3, PartyMix: 1}}
+		low := generateRequestWithMMR("quality", 2, 1, 400)
+		high := generateRequestWithMMR("quality", 2, 1, 600)
+		allies := []models.MatchingAlly{createMatchingAlly(low...), createMatchingAlly(high...)}
+
+		score := scoreMatch(&weighted, allies, nil, "")
+		g.Expect(score.score).To(BeNumerically("~", 0.85))
+	})
+}
+
+func TestMatchQuality_GetMinScore(t *testing.T) {
+	g := testsetup.ParallelWithGomega(t)
+
+	quality := models.MatchQuality{
+		MinScore: 0.9,
+		FlexingRule: []models.MatchQualityFlexingRule{
+			{Duration: 60, MinScore: 0.5},
+			{Duration: 30, MinScore: 0.7},
+		},
+	}
+	g.Expect(quality.GetMinScore(10 * time.Second)).To(Equal(0.9))
+	g.Expect(quality.GetMinScore(45 * time.Second)).To(Equal(0.7))
+	g.Expect(quality.GetMinScore(2 * time.Minute)).To(Equal(0.5))
+	g.Expect(models.MatchQuality{}.IsEnabled()).To(BeFalse())
+	g.Expect(quality.IsEnabled()).To(BeTrue())
+}
+
+func TestMatchPlayers_MatchQuality(t *testing.T) {
+	t.Parallel()
+
+	// the score of a 100 against 900 match is (0.2 + 0.2 + 1 + 1) / 4
+	tickets := func() []models.MatchmakingRequest {
+		return append(generateRequestWithMMR("quality", 1, 1, 100), generateRequestWithMMR("quality", 1, 1, 900)...)
+	}
+
+	t.Run("under the minimum score", func(t *testing.T) {
+		g := testsetup.ParallelWithGomega(t)
+
+		channel := models.Channel{Ruleset: get1v1Rules()}
+		channel.Ruleset.MatchQuality = models.MatchQuality{MinScore: 0.8}
+
+		results, _, err := NewMatchMaker(&config.Config{}).MatchPlayers(testsetup.NewTestScope(), "", "", tickets(), channel)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(results).To(BeEmpty())
+	})
+
+	t.Run("score in the match attributes", func(t *testing.T) {
+		g := testsetup.ParallelWithGomega(t)
+
+		channel := models.Channel{Ruleset: get1v1Rules()}
+		channel.Ruleset.MatchQuality = models.MatchQuality{MinScore: 0.5}
+
+		results, _, err := NewMatchMaker(&config.Config{}).MatchPlayers(testsetup.NewTestScope(), "", "", tickets(), channel)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(results).To(HaveLen(1))
+		g.Expect(results[0].PartyAttributes).To(HaveKey(models.AttributeMatchQuality))
+		quality, _ := results[0].PartyAttributes[models.AttributeMatchQuality].(map[string]interface{})
+		g.Expect(quality["score"]).To(BeNumerically("~", 0.6))
+	})
+
+	t.Run("not scored without match quality", func(t *testing.T) {
+		g := testsetup.ParallelWithGomega(t)
+
+		channel := models.Channel{Ruleset: get1v1Rules()}
+
+		results, _, err := NewMatchMaker(&config.Config{}).MatchPlayers(testsetup.NewTestScope(), "", "", tickets(), channel)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(results).To(HaveLen(1))
+		g.Expect(results[0].PartyAttributes).ToNot(HaveKey(models.AttributeMatchQuality))
+	})
+}
+
+func TestMatchQuality_Validate(t *testing.T) {
+	g := testsetup.ParallelWithGomega(t)
+
+	g.Expect(models.MatchQuality{MinScore: 0.8, FlexingRule: []models.MatchQualityFlexingRule{{Duration: 30, MinScore: 0.5}}}.Validate()).To(Succeed())
+	g.Expect(models.MatchQuality{MinScore: 1.5}.Validate()).ToNot(Succeed())
+	g.Expect(models.MatchQuality{Weights: models.MatchQualityWeights{Latency: -1}}.Validate()).ToNot(Succeed())
+	g.Expect(models.MatchQuality{FlexingRule: []models.MatchQualityFlexingRule{{Duration: -1}}}.Validate()).ToNot(Succeed())
+
+	_, err := newMatchLogic().RulesFromJSON(testsetup.NewTestScope(),
+		`{"alliance":{"min_number":2,"max_number":2,"player_min_number":1,"player_max_number":1},"match_quality":{"weights":{"team_balance":2},"min_score":0.8,"flexing_rule":[{"duration":30,"min_score":0.6}]}}`)
+	g.Expect(err).ToNot(HaveOccurred())
+}
@@ -0,0 +1,156 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package defaultmatchmaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AccelByte/extend-core-matchmaker/pkg/config"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/models"
+	player "github.com/AccelByte/extend-core-matchmaker/pkg/playerdata"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/testsetup"
+	. "github.com/onsi/gomega"
+)
+
+func getRatingRules() models.RuleSet {
+	return models.RuleSet{
+		AllianceRule: models.AllianceRule{MinNumber: 2, MaxNumber: 2, PlayerMinNumber: 1, PlayerMaxNumber: 1},
+		MatchingRule: []models.MatchingRule{
+			{
+				Attribute:            "mu",
+				Criteria:             ratingCriteria,
+				Reference:            100,
+				DeviationAttribute:   "sigma",
+				PerformanceDeviation: 50,
+			},
+		},
+	}
+}
+
+// generateRatingRequest returns a ticket of one player with the rating
+func generateRatingRequest(mu, sigma float64) models.MatchmakingRequest {
+	request := generateRequestWithMMR("rating", 1, 1, 0)[0]
+	request.PartyAttributes[models.AttributeMemberAttr] = map[string]interface{}{"mu": mu, "sigma": sigma}
+	return request
+}
+
+func TestDrawProbability(t *testing.T) {
+	g := testsetup.ParallelWithGomega(t)
+
+	g.Expect(drawProbability(1000, 0, 1000, 0, 50)).To(BeNumerically("~", 1))
+	g.Expect(drawProbability(1000, 0, 1100, 0, 50)).To(BeNumerically("~", drawProbability(1100, 0, 1000, 0, 50)))
+	g.Expect(drawProbability(1000, 0, 1100, 0, 50)).To(BeNumerically("<", drawProbability(1000, 0, 1050, 0, 50)))
+	g.Expect(drawProbability(1000, 100, 1000, 100, 50)).To(BeNumerically("<", drawProbability(1000, 10, 1000, 10, 50)))
+	g.Expect(drawProbability(1000, 0, 1000, 0, 0)).To(Equal(1.0))
+	g.Expect(drawProbability(1000, 0, 1001, 0, 0)).To(Equal(0.0))
+}
+
+func TestMatchByDistance_Rating(t *testing.T) {
+	t.Parallel()
+
+	ruleset := getRatingRules()
+	matches := func(pivot, candidate models.MatchmakingRequest) (bool, float64) {
+		return matchByDistance(&candidate, &ruleset, getFilterByDistance(&ruleset, pivot.PartyAttributes))
+	}
+
+	t.Run("deviation widens the window", func(t *testing.T) {
+		g := testsetup.ParallelWithGomega(t)
+
+		distances := getFilterByDistance(&ruleset, generateRatingRequest(1000, 150).PartyAttributes)
+		g.Expect(distances).To(HaveLen(1))
+		g.Expect(distances[0].min).To(Equal(600.0))
+		g.Expect(distances[0].max).To(Equal(1400.0))
+	})
+
+	t.Run("both windows must hold the other rating", func(t *testing.T) {
+		g := testsetup.ParallelWithGomega(t)
+
+		veteran := generateRatingRequest(1000, 10)
+		newcomer := generateRatingRequest(1300, 150)
+		otherNewcomer := generateRatingRequest(1000, 150)
+		otherVeteran := generateRatingRequest(1300, 10)
+
+		isMatch, _ := matches(newcomer, otherNewcomer)
+		g.Expect(isMatch).To(BeTrue())
+		isMatch, _ = matches(newcomer, veteran)
+		g.Expect(isMatch).To(BeFalse())
+		isMatch, _ = matches(veteran, newcomer)
+		g.Expect(isMatch).To(BeFalse())
+		isMatch, _ = matches(veteran, otherVeteran)
+		g.Expect(isMatch).To(BeFalse())
+	})
+
+	t.Run("closer and more certain ratings score better", func(t *testing.T) {
+		g := testsetup.ParallelWithGomega(t)
+
+		pivot := generateRatingRequest(1000, 10)
+		_, same := matches(pivot, generateRatingRequest(1000, 10))
+		_, closer := matches(pivot, generateRatingRequest(1050, 10))
+		_, uncertain := matches(pivot, generateRatingRequest(1050, 60))
+		g.Expect(same).To(BeNumerically("<", closer))
+		g.Expect(closer).To(BeNumerically("<", uncertain))
+	})
+
+	t.Run("flexing widens the window", func(t *testing.T) {
+		g := testsetup.ParallelWithGomega(t)
+
+		flexed := ruleset
+		flexed.FlexingRule = []models.FlexingRule{{
+			Duration:     0,
+			MatchingRule: models.MatchingRule{Attribute: "mu", Criteria: ratingCriteria, Reference: 100, DeviationAttribute: "sigma", DeviationFactor: 5},
+		}}
+		pivot := generateRatingRequest(1000, 40)
+		flexedRuleset, isFlexed := applyRuleFlexing(flexed, Now().Add(-time.Minute))
+		g.Expect(isFlexed).To(BeTrue())
+
+		distances := getFilterByDistance(&flexedRuleset, pivot.PartyAttributes)
+		g.Expect(distances[0].max).To(Equal(1300.0))
+	})
+}
+
+func TestAverageMatchingRuleAttributes_Rating(t *testing.T) {
+	g := testsetup.ParallelWithGomega(t)
+
+	players := []player.PlayerData{
+		{PlayerID: "user1", Attributes: map[string]interface{}{"mu": 1000.0, "sigma": 30.0}},
+		{PlayerID: "user2", Attributes: map[string]interface{}{"mu": 1200.0, "sigma": 40.0}},
+	}
+	memberAttributes := avergaeMatchingRuleAttributes(players, getRatingRules())
+	g.Expect(memberAttributes["mu"]).To(Equal(1100.0))
+	g.Expect(memberAttributes["sigma"]).To(BeNumerically("~", 35.355, 0.001))
+
+	missing := getMissingMatchingRuleAttributes(player.PlayerData{Attributes: map[string]interface{}{"mu": 1000.0}}, getRatingRules())
+	g.Expect(missing).To(ConsistOf("sigma"))
+}
+
+func TestMatchPlayers_Rating(t *testing.T) {
+	g := testsetup.ParallelWithGomega(t)
+
+	pivot := generateRatingRequest(1000, 20)
+	pivot.CreatedAt = time.Now().Add(-5 * time.Minute).Unix()
+	closest := generateRatingRequest(1040, 20)
+	further := generateRatingRequest(960, 60)
+
+	channel := models.Channel{Ruleset: getRatingRules()}
+	tickets := []models.MatchmakingRequest{pivot, further, closest}
+	results, _, err := NewMatchMaker(&config.Config{}).MatchPlayers(testsetup.NewTestScope(), "", "", tickets, channel)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(results).To(HaveLen(1))
+	g.Expect(results[0].PivotID).To(Equal(pivot.PartyID))
+	g.Expect(resultsContainParty(results, closest.PartyID)).To(BeTrue())
+}
+
+func TestMatchingRule_Validate_Rating(t *testing.T) {
+	g := testsetup.ParallelWithGomega(t)
+
+	g.Expect(getRatingRules().MatchingRule[0].Validate()).To(Succeed())
+	g.Expect(models.MatchingRule{Attribute: "mu", Criteria: ratingCriteria, Reference: 100}.Validate()).ToNot(Succeed())
+	g.Expect(models.MatchingRule{Attribute: "mu", Criteria: ratingCriteria, DeviationAttribute: "sigma", DeviationFactor: -1}.Validate()).ToNot(Succeed())
+
+	_, err := newMatchLogic().RulesFromJSON(testsetup.NewTestScope(),
+		`{"alliance":{"min_number":2,"max_number":2,"player_min_number":1,"player_max_number":1},"matching_rule":[{"attribute":"mu","criteria":"rating","reference":3,"deviation_attribute":"sigma","deviation_factor":2,"performance_deviation":4.2}]}`)
+	g.Expect(err).ToNot(HaveOccurred())
+}
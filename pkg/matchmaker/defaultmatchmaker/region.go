@@ -0,0 +1,163 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package defaultmatchmaker
+
+import (
+	"math"
+	"sort"
+
+	"github.com/AccelByte/extend-core-matchmaker/pkg/models"
+)
+
+// selectMatchRegions ranks the regions of a match once its allies are chosen, best first.
+// Only the regions inside the filterRegionByStep window of every matched ticket with latencies are ranked,
+// by the region selection objective of the ruleset on the weighted latencies. Ties keep the order of the pivot's weighted latencies,
+// and the regions over their soft cap of matches in the tick go last.
+// The searched region is returned alone when the tickets have no region in common, and first with the pivot objective,
+// followed by the other regions in the order of the pivot's weighted latencies.
+func selectMatchRegions(pivot models.MatchmakingRequest, requests []models.MatchmakingRequest, channel *models.Channel, searchedRegion string) []string {
+	selection := channel.Ruleset.RegionSelection
+	policy := channel.Ruleset.RegionPolicy
+	if policy.IsDisabled(searchedRegion) {
+		searchedRegion = ""
+	}
+
+	// the pivot goes first so its latency order breaks the ties
+	tickets := make([]models.MatchmakingRequest, 0, len(requests)+1)
+	tickets = append(tickets, pivot)
+	for _, request := range requests {
+		if request.PartyID != pivot.PartyID {
+			tickets = append(tickets, request)
+		}
+	}
+
+	var candidates []string
+	allowed := make(map[string]int)
+	ticketsWithLatency := 0
+	for i := range tickets {
+		window := weightedRegionWindow(&tickets[i], channel)
+		if len(window) == 0 {
+			// no latencies, the ticket accepts any region
+			continue
+		}
+		ticketsWithLatency++
+		for _, region := range window {
+			if allowed[region.Region] == 0 && ticketsWithLatency == 1 {
+				candidates = append(candidates, region.Region)
+			}
+			allowed[region.Region]++
+		}
+	}
+
+	var regions []string
+	for _, region := range candidates {
+		if allowed[region] == ticketsWithLatency {
+			regions = append(regions, region)
+		}
+	}
+	if selection.GetObjective() == models.RegionObjectivePivot {
+		return withCappedRegionsLast(withRegionFirst(regions, searchedRegion), channel)
+	}
+	if len(regions) == 0 {
+		return withRegionFirst(nil, searchedRegion)
+	}
+
+	scores := make(map[string]float64, len(regions))
+	for _, region := range regions {
+		latencies := make([]float64, 0, len(tickets))
+		for _, ticket := range tickets {
+			if latency, ok := ticket.LatencyMap[region]; ok {
+				latencies = append(latencies, float64(latency)*policy.GetWeight(region))
+			}
+		}
+		scores[region] = regionScore(latencies, selection)
+	}
+	sort.SliceStable(regions, func(i, j int) bool {
+		return scores[regions[i]] < scores[regions[j]]
+	})
+
+	return withCappedRegionsLast(regions, channel)
+}
+
+// weightedRegionWindow returns the filterRegionByStep window of the ticket ordered by the latencies weighted by the region policy,
+// the window itself is still bounded on the measured latencies. It's the order the pivot searches its regions in.
+func weightedRegionWindow(ticket *models.MatchmakingRequest, channel *models.Channel) []models.Region {
+	window := filterRegionByStep(ticket, channel)
+	policy := channel.Ruleset.RegionPolicy
+	if len(policy.Weights) == 0 || len(window) < 2 {
+		return window
+	}
+
+	weighted := make([]models.Region, len(window))
+	copy(weighted, window)
+	sort.SliceStable(weighted, func(i, j int) bool {
+		return float64(weighted[i].Latency)*policy.GetWeight(weighted[i].Region) <
+			float64(weighted[j].Latency)*policy.GetWeight(weighted[j].Region)
+	})
+	return weighted
+}
+
+// withCappedRegionsLast moves the regions over their soft cap of matches in the tick after the others, keeping their order
+func withCappedRegionsLast(regions []string, channel *models.Channel) []string {
+	policy := channel.Ruleset.RegionPolicy
+	if len(policy.MaxMatchesPerTick) == 0 {
+		return regions
+	}
+
+	ranked := make([]string, 0, len(regions))
+	var capped []string
+	for _, region := range regions {
+		if policy.IsOverCap(region, channel.RegionMatches) {
+			capped = append(capped, region)
+			continue
+		}
+		ranked = append(ranked, region)
+	}
+	return append(ranked, capped...)
+}
+
+// regionScore combines the latencies of the tickets to a region, lower is better
+func regionScore(latencies []float64, selection models.RegionSelection) float64 {
+	if len(latencies) == 0 {
+		return 0
+	}
+
+	switch selection.GetObjective() {
+	case models.RegionObjectiveMean:
+		var total float64
+		for _, latency := range latencies {
+			total += latency
+		}
+		return total / float64(len(latencies))
+	case models.RegionObjectivePercentile:
+		sort.Float64s(latencies)
+		// nearest rank
+		rank := int(math.Ceil(selection.GetPercentile() / 100 * float64(len(latencies))))
+		if rank < 1 {
+			rank = 1
+		}
+		return latencies[rank-1]
+	default:
+		maxLatency := latencies[0]
+		for _, latency := range latencies[1:] {
+			maxLatency = math.Max(maxLatency, latency)
+		}
+		return maxLatency
+	}
+}
+
+// withRegionFirst puts the region, when not empty, in front of the other regions
+func withRegionFirst(regions []string, region string) []string {
+	ranked := make([]string, 0, len(regions)+1)
+	if region != "" {
+		ranked = append(ranked, region)
+	}
+	for _, r := range regions {
+		if r != region {
+			ranked = append(ranked, r)
+		}
+	}
+	return ranked
+}
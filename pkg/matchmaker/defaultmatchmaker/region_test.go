@@ -0,0 +1,301 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package defaultmatchmaker
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/AccelByte/extend-core-matchmaker/pkg/matchmaker"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/models"
+	player "github.com/AccelByte/extend-core-matchmaker/pkg/playerdata"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/testsetup"
+	. "github.com/onsi/gomega"
+)
+
+func regionRequest(partyID string, latencies map[string]int64) models.MatchmakingRequest {
+	return toMatchRequest(models.RuleSet{})(matchmaker.Ticket{
+		TicketID:  partyID,
+		Latencies: latencies,
+		Players:   []player.PlayerData{{PlayerID: player.ID(partyID)}},
+	})
+}
+
+func regionChannel(selection models.RegionSelection) *models.Channel {
+	return &models.Channel{Ruleset: models.RuleSet{
+		RegionLatencyInitialRangeMs: 200,
+		RegionLatencyMaxMs:          250,
+		RegionSelection:             selection,
+	}}
+}
+
+func TestSelectMatchRegions(t *testing.T) {
+	t.Parallel()
+
+	// the pivot is closest to us, every other ticket is much closer to eu
+	pivot := regionRequest("pivot", map[string]int64{"us": 20, "eu": 90, "ap": 240})
+	others := []models.MatchmakingRequest{
+		regionRequest("a", map[string]int64{"us": 150, "eu": 30, "ap": 200}),
+		regionRequest("b", map[string]int64{"us": 160, "eu": 20, "ap": 210}),
+		regionRequest("c", map[string]int64{"us": 170, "eu": 40}),
+	}
+
+	testCases := []struct {
+		name      string
+		selection models.RegionSelection
+		requests  []models.MatchmakingRequest
+		want      []string
+	}{
+		{
+			name:     "max latency by default, regions outside a window are left out",
+			requests: others,
+			want:     []string{"eu", "us"},
+		},
+		{
+			name:      "mean latency",
+			selection: models.RegionSelection{Objective: models.RegionObjectiveMean},
+			requests:  others,
+			want:      []string{"eu", "us"},
+		},
+		{
+			name:      "percentile",
+			selection: models.RegionSelection{Objective: models.RegionObjectivePercentile, Percentile: 50},
+			requests:  others,
+			want:      []string{"eu", "us"},
+		},
+		{
+			name:      "pivot keeps the searched region first",
+			selection: models.RegionSelection{Objective: models.RegionObjectivePivot},
+			requests:  others,
+			want:      []string{"us", "eu"},
+		},
+		{
+			name:     "tickets without latencies accept any region",
+			requests: []models.MatchmakingRequest{regionRequest("no-latency", nil)},
+			want:     []string{"us", "eu"},
+		},
+		{
+			name:     "no common region falls back to the searched region",
+			requests: []models.MatchmakingRequest{regionRequest("far", map[string]int64{"sa": 10})},
+			want:     []string{"us"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			g := testsetup.ParallelWithGomega(t)
+
+			regions := selectMatchRegions(pivot, testCase.requests, regionChannel(testCase.selection), "us")
+			g.Expect(regions).To(Equal(testCase.want))
+		})
+	}
+}
+
+func TestRegionScore_Percentile(t *testing.T) {
+	g := testsetup.ParallelWithGomega(t)
+
+	latencies := []float64{50, 10, 40, 20, 30}
+	g.Expect(regionScore(latencies, models.RegionSelection{Objective: models.RegionObjectivePercentile, Percentile: 50})).To(Equal(30.0))
+	g.Expect(regionScore(latencies, models.RegionSelection{Objective: models.RegionObjectivePercentile})).To(Equal(50.0))
+	g.Expect(regionScore(latencies, models.RegionSelection{Objective: models.RegionObjectiveMean})).To(Equal(30.0))
+	g.Expect(regionScore(latencies, models.RegionSelection{})).To(Equal(50.0))
+}
+
+func TestDefaultMatchMaker_ChoosesTheRegionFromAllMatchedTickets(t *testing.T) {
+	g := testsetup.ParallelWithGomega(t)
+	mm := newMatchLogic()
+
+	ruleset := get1v1Rules()
+	ruleset.RegionLatencyInitialRangeMs = 200
+	ruleset.RegionLatencyMaxMs = 250
+
+	now := time.Now()
+	ticketProvider := testsetup.StubMatchTicketProvider{
+		Tickets: []matchmaker.Ticket{
+			{
+				TicketID:  "pivot",
+				CreatedAt: now.Add(-time.Second),
+				Latencies: map[string]int64{"us": 20, "eu": 90},
+				Players:   []player.PlayerData{{PlayerID: "pivot", Attributes: map[string]interface{}{"mmr": 10}}},
+			},
+			{
+				TicketID:  "other",
+				CreatedAt: now,
+				Latencies: map[string]int64{"us": 180, "eu": 30},
+				Players:   []player.PlayerData{{PlayerID: "other", Attributes: map[string]interface{}{"mmr": 10}}},
+			},
+		},
+	}
+	matches := mm.MakeMatches(testsetup.NewTestScope(), ticketProvider, ruleset)
+
+	var results []matchmaker.Match
+	for match := range matches {
+		results = append(results, match)
+	}
+
+	g.Expect(results).To(HaveLen(1))
+	g.Expect(results[0].RegionPreference).To(Equal([]string{"eu", "us"}))
+}
+
+func TestSelectMatchRegions_RegionPolicy(t *testing.T) {
+	t.Parallel()
+
+	pivot := regionRequest("pivot", map[string]int64{"us": 20, "eu": 30, "ap": 40})
+	other := regionRequest("other", map[string]int64{"us": 25, "eu": 30, "ap": 40})
+
+	overCap := models.NewRegionMatchCounter()
+	overCap.Add("eu")
+
+	testCases := []struct {
+		name          string
+		policy        models.RegionPolicy
+		regionMatches *models.RegionMatchCounter
+		want          []string
+	}{
+		{
+			name: "latency only",
+			want: []string{"us", "eu", "ap"},
+		},
+		{
+			name:   "weight steers away from a region",
+			policy: models.RegionPolicy{Weights: map[string]float64{"us": 2}},
+			want:   []string{"eu", "ap", "us"},
+		},
+		{
+			name:   "disabled region is never chosen",
+			policy: models.RegionPolicy{Disabled: []string{"us"}},
+			want:   []string{"eu", "ap"},
+		},
+		{
+			name:          "region over its soft cap goes last",
+			policy:        models.RegionPolicy{Weights: map[string]float64{"us": 2}, MaxMatchesPerTick: map[string]int{"eu": 1}},
+			regionMatches: overCap,
+			want:          []string{"ap", "us", "eu"},
+		},
+		{
+			name:          "region under its soft cap keeps its rank",
+			policy:        models.RegionPolicy{MaxMatchesPerTick: map[string]int{"eu": 2}},
+			regionMatches: overCap,
+			want:          []string{"us", "eu", "ap"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			g := testsetup.ParallelWithGomega(t)
+
+			channel := regionChannel(models.RegionSelection{})
+			channel.Ruleset.RegionPolicy = testCase.policy
+			channel.RegionMatches = testCase.regionMatches
+
+			regions := selectMatchRegions(pivot, []models.MatchmakingRequest{other}, channel, "us")
+			g.Expect(regions).To(Equal(testCase.want))
+		})
+	}
+}
+
+func TestSelectMatchRegions_PivotObjectiveFollowsTheWeights(t *testing.T) {
+	g := testsetup.ParallelWithGomega(t)
+
+	pivot := regionRequest("pivot", map[string]int64{"us": 20, "eu": 30, "ap": 40})
+	other := regionRequest("other", map[string]int64{"us": 25, "eu": 30, "ap": 40})
+
+	channel := regionChannel(models.RegionSelection{Objective: models.RegionObjectivePivot})
+	channel.Ruleset.RegionPolicy = models.RegionPolicy{Weights: map[string]float64{"eu": 2}}
+
+	// the searched region goes first, the others follow the weighted latencies of the pivot
+	g.Expect(selectMatchRegions(pivot, []models.MatchmakingRequest{other}, channel, "us")).To(Equal([]string{"us", "ap", "eu"}))
+}
+
+func TestWeightedRegionWindow(t *testing.T) {
+	g := testsetup.ParallelWithGomega(t)
+
+	request := regionRequest("ticket", map[string]int64{"us": 20, "eu": 30, "ap": 40, "sa": 300})
+	channel := regionChannel(models.RegionSelection{})
+	g.Expect(weightedRegionWindow(&request, channel)).To(Equal([]models.Region{
+		{Region: "us", Latency: 20},
+		{Region: "eu", Latency: 30},
+		{Region: "ap", Latency: 40},
+	}))
+
+	// the pivot searches the weighted best region first, the window is still bounded on the measured latencies
+	channel.Ruleset.RegionPolicy = models.RegionPolicy{Weights: map[string]float64{"us": 3, "sa": 0.1}}
+	g.Expect(weightedRegionWindow(&request, channel)).To(Equal([]models.Region{
+		{Region: "eu", Latency: 30},
+		{Region: "ap", Latency: 40},
+		{Region: "us", Latency: 20},
+	}))
+	g.Expect(request.SortedLatency[0].Region).To(Equal("us"))
+}
+
+func TestFilterRegionByStep_SkipsDisabledRegions(t *testing.T) {
+	g := testsetup.ParallelWithGomega(t)
+
+	channel := &models.Channel{Ruleset: models.RuleSet{
+		RegionLatencyInitialRangeMs: 50,
+		RegionPolicy:                models.RegionPolicy{Disabled: []string{"us"}},
+	}}
+	request := regionRequest("ticket", map[string]int64{"us": 10, "eu": 100, "ap": 140, "sa": 200})
+
+	// the window starts from the best enabled region
+	g.Expect(filterRegionByStep(&request, channel)).To(Equal([]models.Region{
+		{Region: "eu", Latency: 100},
+		{Region: "ap", Latency: 140},
+	}))
+}
+
+func TestDefaultMatchMaker_DoesNotBackfillSessionsInDisabledRegions(t *testing.T) {
+	t.Parallel()
+
+	for _, disabled := range []bool{false, true} {
+		disabled := disabled
+		t.Run(fmt.Sprintf("disabled=%t", disabled), func(t *testing.T) {
+			g := testsetup.ParallelWithGomega(t)
+			mm := newMatchLogic()
+
+			ruleset := backfill1v1RUles
+			if disabled {
+				ruleset.RegionPolicy = models.RegionPolicy{Disabled: []string{"eu"}}
+			}
+
+			session := backfill1v1Session("in-session", 10)
+			session.PartialMatch.RegionPreference = []string{"eu"}
+			ticket := backfill1v1Ticket("joining", 10)
+			ticket.Latencies = map[string]int64{"eu": 30, "us": 40}
+
+			ticketProvider := testsetup.StubMatchTicketProvider{
+				Tickets:         []matchmaker.Ticket{ticket},
+				BackfillTickets: []matchmaker.BackfillTicket{session},
+			}
+			proposals := mm.BackfillMatches(testsetup.NewTestScope(), ticketProvider, ruleset)
+
+			var results []matchmaker.BackfillProposal
+			for proposal := range proposals {
+				results = append(results, proposal)
+			}
+
+			if disabled {
+				g.Expect(results).To(BeEmpty())
+			} else {
+				g.Expect(results).To(HaveLen(1))
+			}
+		})
+	}
+}
+
+func TestRegionPolicy_Validate(t *testing.T) {
+	g := testsetup.ParallelWithGomega(t)
+
+	g.Expect(models.RegionPolicy{Weights: map[string]float64{"us": 1.5}, MaxMatchesPerTick: map[string]int{"us": 0}}.Validate()).To(Succeed())
+	g.Expect(models.RegionPolicy{Weights: map[string]float64{"us": 0}}.Validate()).ToNot(Succeed())
+	g.Expect(models.RegionPolicy{MaxMatchesPerTick: map[string]int{"us": -1}}.Validate()).ToNot(Succeed())
+
+	_, err := newMatchLogic().RulesFromJSON(testsetup.NewTestScope(),
+		`{"alliance":{"min_number":2,"max_number":2,"player_min_number":1,"player_max_number":1},"region_policy":{"weights":{"us":2},"disabled":["eu"],"max_matches_per_tick":{"ap":10}}}`)
+	g.Expect(err).ToNot(HaveOccurred())
+}
@@ -0,0 +1,140 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package defaultmatchmaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AccelByte/extend-core-matchmaker/pkg/config"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/matchmaker"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/models"
+	player "github.com/AccelByte/extend-core-matchmaker/pkg/playerdata"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/testsetup"
+	. "github.com/onsi/gomega"
+)
+
+// huntRule is 1 hunter vs 4 survivors
+var huntRule = models.AllianceRule{
+	MinNumber: 2,
+	MaxNumber: 2,
+	Teams: []models.TeamRule{
+		{Name: "hunter", PlayerMinNumber: 1, PlayerMaxNumber: 1, Attribute: "role", Values: []string{"hunter"}},
+		{Name: "survivors", PlayerMinNumber: 4, PlayerMaxNumber: 4, Attribute: "role", Values: []string{"survivor"}},
+	},
+}
+
+func roleRequests(role string, count int) []models.MatchmakingRequest {
+	requests := generateRequestWithMMR("hunt", count, 1, 10)
+	for i := range requests {
+		requests[i].PartyAttributes["role"] = role
+	}
+	return requests
+}
+
+func allyPartyIDs(ally models.MatchingAlly) []string {
+	var partyIDs []string
+	for _, party := range ally.MatchingParties {
+		partyIDs = append(partyIDs, party.PartyID)
+	}
+	return partyIDs
+}
+
+func TestFindMatchingAlly_AsymmetricTeams(t *testing.T) {
+	g := testsetup.ParallelWithGomega(t)
+	scope := testsetup.NewTestScope()
+
+	survivors := roleRequests("survivor", 5)
+	hunters := roleRequests("hunter", 2)
+	tickets := append(append([]models.MatchmakingRequest{}, survivors...), hunters...)
+
+	allies, remaining, err := findMatchingAlly(scope, &config.Config{}, tickets, survivors[0], huntRule, nil, models.BlockedPlayerCannotMatch)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(allies).To(HaveLen(2))
+	g.Expect(allies[0].CountPlayer()).To(Equal(1))
+	g.Expect(hunters).To(ContainElement(HaveField("PartyID", allyPartyIDs(allies[0])[0])))
+	g.Expect(allies[1].CountPlayer()).To(Equal(4))
+	g.Expect(allyPartyIDs(allies[1])).To(ContainElement(survivors[0].PartyID))
+	g.Expect(remaining).To(HaveLen(2))
+
+	// without a hunter there is no match
+	_, _, err = findMatchingAlly(scope, &config.Config{}, survivors, survivors[0], huntRule, nil, models.BlockedPlayerCannotMatch)
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestMatchPlayers_AsymmetricTeams(t *testing.T) {
+	g := testsetup.ParallelWithGomega(t)
+
+	tickets := append(roleRequests("survivor", 4), roleRequests("hunter", 1)...)
+	results, _, err := NewMatchmaker().MatchPlayers(testsetup.NewTestScope(), "", "", tickets, models.Channel{Ruleset: models.RuleSet{AllianceRule: huntRule}})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(results).To(HaveLen(1))
+	g.Expect(results[0].MatchingAllies).To(HaveLen(2))
+	g.Expect(results[0].MatchingAllies[0].MatchingParties[0].PartyAttributes["role"]).To(Equal("hunter"))
+	g.Expect(results[0].MatchingAllies[1].CountPlayer()).To(Equal(4))
+}
+
+func TestMatchSessions_AsymmetricTeams(t *testing.T) {
+	g := testsetup.ParallelWithGomega(t)
+
+	session := generateSession("hunt", 2, []int{1, 3})
+	hunter := roleRequests("hunter", 1)[0]
+	survivor := roleRequests("survivor", 1)[0]
+
+	_, matchedSessions, matchedTickets, err := NewMatchmaker().MatchSessions(testsetup.NewTestScope(), "", "",
+		[]models.MatchmakingRequest{hunter, survivor}, []*models.MatchmakingResult{session}, models.Channel{Ruleset: models.RuleSet{AllianceRule: huntRule}})
+	g.Expect(err).ToNot(HaveOccurred())
+
+	// the hunter team is already full, the survivor fills the last survivor slot
+	g.Expect(matchedTickets).To(HaveLen(1))
+	g.Expect(matchedTickets[0].PartyID).To(Equal(survivor.PartyID))
+	g.Expect(matchedSessions).To(HaveLen(1))
+	g.Expect(matchedSessions[0].MatchingAllies[0].CountPlayer()).To(Equal(1))
+	g.Expect(allyPartyIDs(matchedSessions[0].MatchingAllies[1])).To(ContainElement(survivor.PartyID))
+}
+
+func TestIsMatchFull_AsymmetricTeams(t *testing.T) {
+	g := testsetup.ParallelWithGomega(t)
+
+	ruleset := models.RuleSet{AllianceRule: huntRule}
+	tickets := make([]matchmaker.Ticket, 0, 5)
+	for i := 0; i < 4; i++ {
+		tickets = append(tickets, matchmaker.Ticket{CreatedAt: time.Now(), Players: []player.PlayerData{{PlayerID: "player"}}})
+	}
+	g.Expect(isMatchFull(tickets, &models.MatchmakingResult{}, ruleset)).To(BeFalse())
+
+	tickets = append(tickets, matchmaker.Ticket{CreatedAt: time.Now(), Players: []player.PlayerData{{PlayerID: "player"}}})
+	g.Expect(isMatchFull(tickets, &models.MatchmakingResult{}, ruleset)).To(BeTrue())
+}
+
+func TestApplyAllianceFlexingRule_AsymmetricTeams(t *testing.T) {
+	g := testsetup.ParallelWithGomega(t)
+
+	flexed := huntRule
+	flexed.Teams = []models.TeamRule{huntRule.Teams[0], {Name: "survivors", PlayerMinNumber: 2, PlayerMaxNumber: 4}}
+
+	rule, isFlexed := ApplyAllianceFlexingRule(huntRule, []models.AllianceFlexingRule{{Duration: 10, AllianceRule: flexed}}, time.Now().Add(-time.Minute))
+	g.Expect(isFlexed).To(BeTrue())
+	g.Expect(rule.GetTeamRule(1).PlayerMinNumber).To(Equal(2))
+	g.Expect(rule.GetMaxPlayer()).To(Equal(5))
+}
+
+func TestAllianceRule_ValidateTeams(t *testing.T) {
+	g := testsetup.ParallelWithGomega(t)
+
+	valid := huntRule
+	g.Expect(valid.Validate()).To(Succeed())
+
+	tooManyTeams := huntRule
+	tooManyTeams.MaxNumber = 3
+	g.Expect(tooManyTeams.Validate()).ToNot(Succeed())
+
+	noValues := models.AllianceRule{MinNumber: 1, MaxNumber: 1, Teams: []models.TeamRule{{PlayerMinNumber: 1, PlayerMaxNumber: 1, Attribute: "role"}}}
+	g.Expect(noValues.Validate()).ToNot(Succeed())
+
+	_, err := newMatchLogic().RulesFromJSON(testsetup.NewTestScope(),
+		`{"alliance":{"min_number":2,"max_number":2,"teams":[{"name":"hunter","player_min_number":1,"player_max_number":1,"attribute":"role","values":["hunter"]},{"name":"survivors","player_min_number":4,"player_max_number":4}]}}`)
+	g.Expect(err).ToNot(HaveOccurred())
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package defaultmatchmaker
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/AccelByte/extend-core-matchmaker/pkg/matchmaker"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/models"
+	player "github.com/AccelByte/extend-core-matchmaker/pkg/playerdata"
+)
+
+// validateTicket runs the ticket validation policies of the ruleset against a ticket.
+// It returns a *models.TicketValidationError describing the first failed check.
+func validateTicket(ticket matchmaker.Ticket, ruleSet models.RuleSet) error {
+	policy := ruleSet.TicketValidation
+
+	// Check the party fits in the largest alliance it can be matched into
+	if policy.IsValidatePartySize() {
+		maxPlayer := ruleSet.GetLargestPlayerMaxNumber()
+		if maxPlayer > 0 && len(ticket.Players) > maxPlayer {
+			return models.NewTicketValidationError(models.TicketValidationPartyTooLarge,
+				"party size %d exceeds max player number %d", len(ticket.Players), maxPlayer)
+		}
+	}
+
+	// Check the same player is not in the ticket twice
+	if policy.IsRejectDuplicatePlayers() {
+		seen := make(map[player.ID]struct{}, len(ticket.Players))
+		for _, playerData := range ticket.Players {
+			if _, ok := seen[playerData.PlayerID]; ok {
+				return models.NewTicketValidationError(models.TicketValidationDuplicatePlayer,
+					"player %s appears more than once", playerData.PlayerID)
+			}
+			seen[playerData.PlayerID] = struct{}{}
+		}
+	}
+
+	// Check every player has the attributes used by the matching rules
+	if policy.IsRequireMatchingAttributes() {
+		for _, playerData := range ticket.Players {
+			if missing := getMissingMatchingRuleAttributes(playerData, ruleSet); len(missing) > 0 {
+				return models.NewTicketValidationError(models.TicketValidationMissingAttribute,
+					"player %s is missing matching rule attributes: %s", playerData.PlayerID, strings.Join(missing, ", "))
+			}
+		}
+	}
+
+	// Check match option values are allowed
+	for _, option := range ruleSet.MatchOptions.Options {
+		value, ok := ticket.TicketAttributes[option.Name]
+		if !ok || len(option.AllowedValues) == 0 {
+			continue
+		}
+		values, ok := value.([]interface{})
+		if !ok {
+			values = []interface{}{value}
+		}
+		for _, v := range values {
+			valStr, ok := v.(string)
+			if !ok {
+				valStr = fmt.Sprint(v)
+			}
+			if !option.IsValueAllowed(valStr) {
+				return models.NewTicketValidationError(models.TicketValidationMatchOptionNotAllowed,
+					"value %q is not allowed for match option %s", valStr, option.Name)
+			}
+		}
+	}
+
+	// Check the blocked player list length
+	if policy.MaxBlockedPlayers > 0 {
+		var count int
+		models.RangeBlockedPlayerUserIDs(ticket.TicketAttributes)(func(string) bool {
+			count++
+			return true
+		})
+		if count > policy.MaxBlockedPlayers {
+			return models.NewTicketValidationError(models.TicketValidationTooManyBlockedPlayers,
+				"ticket has %d blocked players, max is %d", count, policy.MaxBlockedPlayers)
+		}
+	}
+
+	// Check region latencies
+	if len(ticket.Latencies) == 0 {
+		if policy.RejectEmptyLatency(ruleSet.RegionLatencyMaxMs) {
+			return models.NewTicketValidationError(models.TicketValidationEmptyLatency, "ticket has no region latency")
+		}
+		return nil
+	}
+	if ruleSet.RegionLatencyMaxMs > 0 {
+		hasValidLatency := false
+		for _, latency := range ticket.Latencies {
+			if latency <= int64(ruleSet.RegionLatencyMaxMs) {
+				hasValidLatency = true
+				break
+			}
+		}
+		if !hasValidLatency {
+			return models.NewTicketValidationError(models.TicketValidationNoRegionLatencyBelowMax,
+				"no region latency below max %dms", ruleSet.RegionLatencyMaxMs)
+		}
+	}
+
+	return nil
+}
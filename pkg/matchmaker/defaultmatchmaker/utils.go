@@ -155,7 +155,14 @@ func isContainBlockedPlayers(tickets []models.MatchmakingRequest, ticket *models
 // filterRegionByStep filters regions based on the ticket's expansion step and latency requirements.
 // This function determines which regions are acceptable for matchmaking based on latency constraints.
 func filterRegionByStep(ticket *models.MatchmakingRequest, channel *models.Channel) []models.Region {
-	if len(ticket.SortedLatency) == 0 {
+	sortedLatency := ticket.SortedLatency
+	if len(channel.Ruleset.RegionPolicy.Disabled) > 0 {
+		// Disabled regions are never matched into, the window starts from the best enabled region
+		sortedLatency = slices.Filter(sortedLatency, func(item models.Region) bool {
+			return !channel.Ruleset.RegionPolicy.IsDisabled(item.Region)
+		})
+	}
+	if len(sortedLatency) == 0 {
 		return nil
 	}
 	expansionStep := mathutil.Max(getTicketRegionExpansionStep(ticket, channel)-1, 0)
@@ -163,9 +170,9 @@ func filterRegionByStep(ticket *models.MatchmakingRequest, channel *models.Chann
 	if channel.Ruleset.RegionExpansionRangeMs > 0 {
 		additionalLatency = channel.Ruleset.RegionExpansionRangeMs
 	}
-	maxLatency := ticket.SortedLatency[0].Latency + channel.Ruleset.RegionLatencyInitialRangeMs + (expansionStep * additionalLatency)
+	maxLatency := sortedLatency[0].Latency + channel.Ruleset.RegionLatencyInitialRangeMs + (expansionStep * additionalLatency)
 	hardMaxLatency := channel.Ruleset.RegionLatencyMaxMs
-	return slices.Filter(ticket.SortedLatency, func(item models.Region) bool {
+	return slices.Filter(sortedLatency, func(item models.Region) bool {
 		return item.Latency <= maxLatency && (hardMaxLatency <= 0 || (hardMaxLatency > 0 && item.Latency <= hardMaxLatency))
 	})
 }
@@ -195,13 +202,25 @@ func RemoveEmptyMatchingParties(allies []models.MatchingAlly) []models.MatchingA
 	return allies
 }
 
+// removeEmptyAllies removes the empty allies of a session. The teams of an asymmetric rule are matched to
+// the team rules by index, so only the empty allies at the end are removed.
+func removeEmptyAllies(allies []models.MatchingAlly, allianceRule models.AllianceRule) []models.MatchingAlly {
+	if !allianceRule.IsAsymmetric() {
+		return RemoveEmptyMatchingParties(allies)
+	}
+	for len(allies) > 0 && allies[len(allies)-1].CountPlayer() == 0 {
+		allies = allies[:len(allies)-1]
+	}
+	return allies
+}
+
 // DetermineAllianceComposition extracts alliance composition from a ruleset.
 // This function creates an AllianceComposition structure from the alliance rule configuration.
 func DetermineAllianceComposition(ruleSet models.RuleSet) models.AllianceComposition {
 	minTeam := ruleSet.AllianceRule.MinNumber
 	maxTeam := ruleSet.AllianceRule.MaxNumber
-	maxPlayer := ruleSet.AllianceRule.PlayerMaxNumber
-	minPlayer := ruleSet.AllianceRule.PlayerMinNumber
+	maxPlayer := ruleSet.AllianceRule.GetLargestPlayerMaxNumber()
+	minPlayer := ruleSet.AllianceRule.GetSmallestPlayerMinNumber()
 
 	return models.AllianceComposition{
 		MinTeam:   minTeam,
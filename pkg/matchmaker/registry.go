@@ -0,0 +1,148 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package matchmaker
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/AccelByte/extend-core-matchmaker/pkg/config"
+)
+
+// DefaultMatchLogicName is the name the default match logic is registered under.
+const DefaultMatchLogicName = "default"
+
+// Factory creates a MatchLogic from the service config.
+type Factory func(cfg *config.Config) MatchLogic
+
+// Registry holds the MatchLogic implementations available to the server by name.
+// A request selects a MatchLogic by the ruleset, then by the match pool mapping from config,
+// and falls back to the default match logic from config.
+type Registry struct {
+	cfg *config.Config
+
+	mu     sync.RWMutex
+	logics map[string]MatchLogic
+	pools  map[string]string
+}
+
+// NewRegistry creates an empty registry, the config is passed to every registered factory.
+func NewRegistry(cfg *config.Config) *Registry {
+	return &Registry{
+		cfg:    cfg,
+		logics: make(map[string]MatchLogic),
+		pools:  cfg.GetMatchLogicPools(),
+	}
+}
+
+// Register creates a MatchLogic with the factory and registers it under the name.
+func (r *Registry) Register(name string, factory Factory) error {
+	if name == "" {
+		return fmt.Errorf("match logic name cannot be empty")
+	}
+	if factory == nil {
+		return fmt.Errorf("match logic %s factory cannot be nil", name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.logics[name]; ok {
+		return fmt.Errorf("match logic %s is already registered", name)
+	}
+	r.logics[name] = factory(r.cfg)
+
+	return nil
+}
+
+// Get returns the MatchLogic registered under the name.
+func (r *Registry) Get(name string) (MatchLogic, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	logic, ok := r.logics[name]
+	return logic, ok
+}
+
+// Names returns the sorted names of the registered match logics.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.logics))
+	for name := range r.logics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Len returns the number of registered match logics.
+func (r *Registry) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return len(r.logics)
+}
+
+// HasPoolMapping returns true if match logics are mapped to match pools in config.
+func (r *Registry) HasPoolMapping() bool {
+	return len(r.pools) > 0
+}
+
+// Validate returns an error if the default match logic or a match logic mapped to a match pool in config is not registered.
+// It's called once every match logic is registered, so a typo in config fails the startup instead of every request.
+func (r *Registry) Validate() error {
+	if _, ok := r.Get(r.defaultName()); !ok {
+		return fmt.Errorf("default match logic %s is not registered, registered match logics: %v", r.defaultName(), r.Names())
+	}
+
+	matchPools := make([]string, 0, len(r.pools))
+	for matchPool := range r.pools {
+		matchPools = append(matchPools, matchPool)
+	}
+	sort.Strings(matchPools)
+	for _, matchPool := range matchPools {
+		if _, ok := r.Get(r.pools[matchPool]); !ok {
+			return fmt.Errorf("match logic %s of match pool %s is not registered, registered match logics: %v", r.pools[matchPool], matchPool, r.Names())
+		}
+	}
+
+	return nil
+}
+
+// Selected returns the name of the match logic selected for a request before any fallback,
+// the ruleset match logic or else the match logic mapped to the match pool, empty if neither names one.
+func (r *Registry) Selected(ruleSetLogic, matchPool string) string {
+	if ruleSetLogic != "" {
+		return ruleSetLogic
+	}
+	return r.pools[matchPool]
+}
+
+// Resolve returns the name and MatchLogic for a request.
+// ruleSetLogic is the match logic named in the ruleset and takes precedence over the match pool mapping.
+// When the selected match logic is not registered it falls back to the default match logic,
+// the caller can tell by comparing the returned name with Selected.
+// It returns false if neither the selected nor the default match logic is registered.
+func (r *Registry) Resolve(ruleSetLogic, matchPool string) (string, MatchLogic, bool) {
+	if name := r.Selected(ruleSetLogic, matchPool); name != "" {
+		if logic, ok := r.Get(name); ok {
+			return name, logic, true
+		}
+	}
+
+	name := r.defaultName()
+	logic, ok := r.Get(name)
+	return name, logic, ok
+}
+
+func (r *Registry) defaultName() string {
+	if r.cfg.DefaultMatchLogic == "" {
+		return DefaultMatchLogicName
+	}
+	return r.cfg.DefaultMatchLogic
+}
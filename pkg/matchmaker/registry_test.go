@@ -0,0 +1,109 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package matchmaker_test
+
+import (
+	"testing"
+
+	"github.com/AccelByte/extend-core-matchmaker/pkg/config"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/matchmaker"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/testsetup"
+	. "github.com/onsi/gomega"
+)
+
+// namedLogic is a MatchLogic stub that only carries a name
+type namedLogic struct {
+	matchmaker.MatchLogic
+	name string
+}
+
+func namedFactory(name string) matchmaker.Factory {
+	return func(cfg *config.Config) matchmaker.MatchLogic {
+		return namedLogic{name: name}
+	}
+}
+
+func TestRegistry_Resolve(t *testing.T) {
+	g := testsetup.ParallelWithGomega(t)
+
+	registry := matchmaker.NewRegistry(&config.Config{
+		DefaultMatchLogic: matchmaker.DefaultMatchLogicName,
+		MatchLogicPools:   "pool-a:logic-a, pool-b:logic-b, pool-c:unknown",
+	})
+	g.Expect(registry.Register(matchmaker.DefaultMatchLogicName, namedFactory(matchmaker.DefaultMatchLogicName))).To(Succeed())
+	g.Expect(registry.Register("logic-a", namedFactory("logic-a"))).To(Succeed())
+	g.Expect(registry.Register("logic-b", namedFactory("logic-b"))).To(Succeed())
+	g.Expect(registry.Register("logic-a", namedFactory("logic-a"))).ToNot(Succeed())
+	g.Expect(registry.Names()).To(Equal([]string{matchmaker.DefaultMatchLogicName, "logic-a", "logic-b"}))
+
+	testCases := []struct {
+		ruleSetLogic string
+		matchPool    string
+		expected     string
+	}{
+		{ruleSetLogic: "logic-b", matchPool: "pool-a", expected: "logic-b"},
+		{matchPool: "pool-a", expected: "logic-a"},
+		{matchPool: "pool-c", expected: matchmaker.DefaultMatchLogicName},
+		{ruleSetLogic: "unknown", expected: matchmaker.DefaultMatchLogicName},
+		{expected: matchmaker.DefaultMatchLogicName},
+	}
+	for _, testCase := range testCases {
+		name, logic, ok := registry.Resolve(testCase.ruleSetLogic, testCase.matchPool)
+		g.Expect(ok).To(BeTrue())
+		g.Expect(name).To(Equal(testCase.expected))
+		g.Expect(logic.(namedLogic).name).To(Equal(testCase.expected))
+	}
+}
+
+func TestRegistry_ResolveWithoutDefault(t *testing.T) {
+	g := testsetup.ParallelWithGomega(t)
+
+	registry := matchmaker.NewRegistry(&config.Config{DefaultMatchLogic: "missing"})
+	g.Expect(registry.Register("logic-a", namedFactory("logic-a"))).To(Succeed())
+
+	_, _, ok := registry.Resolve("", "")
+	g.Expect(ok).To(BeFalse())
+}
+
+func TestRegistry_Validate(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name    string
+		cfg     config.Config
+		wantErr string
+	}{
+		{name: "defaults", cfg: config.Config{}},
+		{name: "mapped pools", cfg: config.Config{DefaultMatchLogic: "logic-a", MatchLogicPools: "pool-a:logic-a,pool-b:default"}},
+		{name: "unknown default", cfg: config.Config{DefaultMatchLogic: "missing"}, wantErr: "default match logic missing"},
+		{name: "unknown pool logic", cfg: config.Config{MatchLogicPools: "pool-a:logic-a,pool-b:missing"}, wantErr: "match logic missing of match pool pool-b"},
+	}
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			g := testsetup.ParallelWithGomega(t)
+
+			registry := matchmaker.NewRegistry(&testCase.cfg)
+			g.Expect(registry.Register(matchmaker.DefaultMatchLogicName, namedFactory(matchmaker.DefaultMatchLogicName))).To(Succeed())
+			g.Expect(registry.Register("logic-a", namedFactory("logic-a"))).To(Succeed())
+
+			err := registry.Validate()
+			if testCase.wantErr == "" {
+				g.Expect(err).ToNot(HaveOccurred())
+				return
+			}
+			g.Expect(err).To(MatchError(ContainSubstring(testCase.wantErr)))
+		})
+	}
+}
+
+func TestRegistry_Selected(t *testing.T) {
+	g := testsetup.ParallelWithGomega(t)
+
+	registry := matchmaker.NewRegistry(&config.Config{MatchLogicPools: "pool-a:logic-a"})
+	g.Expect(registry.Selected("logic-b", "pool-a")).To(Equal("logic-b"))
+	g.Expect(registry.Selected("", "pool-a")).To(Equal("logic-a"))
+	g.Expect(registry.Selected("", "pool-b")).To(BeEmpty())
+}
@@ -0,0 +1,198 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+// Package metrics provides the matchmaking Prometheus collectors.
+// Every collector is labelled by namespace and match pool, and the collectors are registered by Register.
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const metricsNamespace = "matchmaker"
+
+// Label names
+const (
+	LabelNamespace = "namespace"
+	LabelMatchPool = "match_pool"
+	LabelRule      = "rule"
+	LabelReason    = "reason"
+	LabelMeasure   = "measure"
+	LabelStage     = "stage"
+	LabelMove      = "move"
+)
+
+// Flexing rule label values
+const (
+	FlexRuleMatching = "matching_rule"
+	FlexRuleAlliance = "alliance_rule"
+)
+
+// Match optimization label values
+const (
+	QualityDistance      = "distance"
+	QualityLatencySpread = "latency_spread"
+
+	StageBefore = "before"
+	StageAfter  = "after"
+
+	MoveSwap       = "swap"
+	MoveExtraMatch = "extra_match"
+)
+
+var poolLabels = []string{LabelNamespace, LabelMatchPool}
+
+var (
+	// TicketsReceived counts the tickets received by MakeMatches.
+	TicketsReceived = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "tickets_received_total",
+		Help:      "The total number of tickets received by make matches",
+	}, poolLabels)
+
+	// TicketsPerTick observes the number of tickets received in one MakeMatches call.
+	TicketsPerTick = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "tickets_per_tick",
+		Help:      "The number of tickets received in one make matches call",
+		Buckets:   prometheus.ExponentialBuckets(1, 4, 9),
+	}, poolLabels)
+
+	// MatchesMade counts the matches emitted by MakeMatches.
+	MatchesMade = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "matches_total",
+		Help:      "The total number of matches made",
+	}, poolLabels)
+
+	// BackfillProposals counts the backfill proposals emitted by BackfillMatches.
+	BackfillProposals = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "backfill_proposals_total",
+		Help:      "The total number of backfill proposals made",
+	}, poolLabels)
+
+	// PlayersPerMatch observes the number of players in a match.
+	PlayersPerMatch = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "players_per_match",
+		Help:      "The number of players in a match",
+		Buckets:   []float64{1, 2, 4, 6, 8, 10, 16, 24, 32, 50, 64, 100},
+	}, poolLabels)
+
+	// TimeToMatch observes how long a ticket waited from its creation until it was matched.
+	TimeToMatch = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "time_to_match_seconds",
+		Help:      "The time from ticket creation until the ticket is matched",
+		Buckets:   []float64{1, 5, 10, 20, 30, 45, 60, 90, 120, 180, 300, 600},
+	}, poolLabels)
+
+	// PivotIterations counts the pivot matching iterations in MatchPlayers.
+	PivotIterations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "pivot_iterations_total",
+		Help:      "The total number of pivot matching iterations",
+	}, poolLabels)
+
+	// PivotCooldownSkips counts the tickets not picked as a pivot in MatchPlayers while on cooldown.
+	PivotCooldownSkips = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "pivot_cooldown_skips_total",
+		Help:      "The total number of tickets skipped as a pivot because they failed as a pivot in a recent tick",
+	}, poolLabels)
+
+	// FindMatchingAllyAttempts counts the findMatchingAlly calls in MatchPlayers.
+	FindMatchingAllyAttempts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "find_matching_ally_attempts_total",
+		Help:      "The total number of attempts to find matching allies",
+	}, poolLabels)
+
+	// FlexActivations counts the matches made with an active flexing rule.
+	FlexActivations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "flex_activations_total",
+		Help:      "The total number of matches made with an active flexing rule",
+	}, []string{LabelNamespace, LabelMatchPool, LabelRule})
+
+	// MatchTimeouts counts the MatchPlayers calls that stopped because of the match time limit.
+	MatchTimeouts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "match_timeouts_total",
+		Help:      "The total number of match players calls stopped by the match time limit",
+	}, poolLabels)
+
+	// UnmatchedTickets counts the tickets left unmatched after a tick.
+	UnmatchedTickets = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "unmatched_tickets_total",
+		Help:      "The total number of tickets left unmatched after a tick",
+	}, poolLabels)
+
+	// RejectedInput counts the requests rejected by the input limits, labelled by the exceeded limit only.
+	// The namespace and match pool of a rejected input are not trusted as labels, they would grow the series unbounded.
+	RejectedInput = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "rejected_input_total",
+		Help:      "The total number of requests rejected by the input limits",
+	}, []string{LabelReason})
+
+	// MatchQuality is the average distance score and latency spread per match of the last optimized MatchPlayers call,
+	// before and after the match optimization.
+	MatchQuality = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "match_quality",
+		Help:      "The average distance score and latency spread per match of the last optimized chunk, before and after the optimization",
+	}, []string{LabelNamespace, LabelMatchPool, LabelMeasure, LabelStage})
+
+	// OptimizationMoves counts the party swaps and the extra matches made by the match optimization.
+	OptimizationMoves = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "optimization_moves_total",
+		Help:      "The total number of party swaps and extra matches made by the match optimization",
+	}, []string{LabelNamespace, LabelMatchPool, LabelMove})
+)
+
+// Collectors returns all the matchmaking collectors.
+func Collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		TicketsReceived,
+		TicketsPerTick,
+		MatchesMade,
+		BackfillProposals,
+		PlayersPerMatch,
+		TimeToMatch,
+		PivotIterations,
+		PivotCooldownSkips,
+		FindMatchingAllyAttempts,
+		FlexActivations,
+		MatchTimeouts,
+		UnmatchedTickets,
+		RejectedInput,
+		MatchQuality,
+		OptimizationMoves,
+	}
+}
+
+// Register registers all the matchmaking collectors to the registerer.
+func Register(registerer prometheus.Registerer) error {
+	for _, collector := range Collectors() {
+		if err := registerer.Register(collector); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ObserveTimeToMatch observes the time to match of a ticket created at createdAt.
+// Tickets without a creation time are ignored.
+func ObserveTimeToMatch(namespace, matchPool string, createdAt time.Time) {
+	if createdAt.IsZero() {
+		return
+	}
+	TimeToMatch.WithLabelValues(namespace, matchPool).Observe(time.Since(createdAt).Seconds())
+}
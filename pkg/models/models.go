@@ -12,9 +12,11 @@ import (
 	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/AccelByte/extend-core-matchmaker/pkg/constants"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/redact"
 	"github.com/AccelByte/extend-core-matchmaker/pkg/utils"
 
 	//"accelbyte.net/justice-matchmaking/pkg/constants"
@@ -412,6 +414,7 @@ const (
 	AttributeCrossPlatform        = "cross_platform"
 	AttributeCurrentPlatform      = "current_platform"
 	AttributeRole                 = "role"
+	AttributeMatchQuality         = "match_quality"
 )
 
 func GetBlockedPlayerUserIDs(partyAttributes map[string]interface{}) []string {
@@ -428,14 +431,51 @@ func GetBlockedPlayerUserIDs(partyAttributes map[string]interface{}) []string {
 	return blockedPlayers
 }
 
+// RangeBlockedPlayerUserIDs ranges over the blocked players of the blocked_players list, then over the blocked players
+// of the blocked_players_detail entries that the list doesn't have already.
 func RangeBlockedPlayerUserIDs(partyAttributes map[string]interface{}) func(func(userID string) bool) {
 	return func(yield func(string) bool) {
-		if list, ok := utils.GetMapValueAs[[]interface{}](partyAttributes, AttributeBlocked); ok {
-			for _, id := range list {
-				if userID, ok2 := id.(string); ok2 {
-					if !yield(userID) {
-						return
-					}
+		var listed map[string]struct{}
+		_, hasDetail := partyAttributes[AttributeBlockedPlayersDetail]
+		if hasDetail {
+			listed = make(map[string]struct{})
+		}
+
+		var list []interface{}
+		switch v := partyAttributes[AttributeBlocked].(type) {
+		case []interface{}:
+			list = v
+		case []string:
+			list = make([]interface{}, len(v))
+			for i := range v {
+				list[i] = v[i]
+			}
+		}
+		for _, id := range list {
+			if userID, ok := id.(string); ok {
+				if listed != nil {
+					listed[userID] = struct{}{}
+				}
+				if !yield(userID) {
+					return
+				}
+			}
+		}
+
+		details, _ := partyAttributes[AttributeBlockedPlayersDetail].([]interface{})
+		for _, detail := range details {
+			_, blockedPlayers := extractBlockedPlayersDetail(detail)
+			for _, id := range blockedPlayers {
+				userID, ok := id.(string)
+				if !ok {
+					continue
+				}
+				if _, ok = listed[userID]; ok {
+					continue
+				}
+				listed[userID] = struct{}{}
+				if !yield(userID) {
+					return
 				}
 			}
 		}
@@ -606,16 +646,13 @@ func (m *PartyMember) SetRole(role string) {
 	m.ExtraAttributes = extraAttributes
 }
 
+// GetAttrFloat64 get the attribute as float64, attributeName can be a dotted path into nested attributes.
 func (p PartyMember) GetAttrFloat64(attributeName string) float64 {
-	attributeValue, ok := p.ExtraAttributes[attributeName]
+	attributeValue, ok := utils.GetMapValueByPath(p.ExtraAttributes, attributeName)
 	if !ok {
 		return 0.0
 	}
-	if float64Val, isFloat := attributeValue.(float64); isFloat {
-		return float64Val
-	}
-	// the conversion is slow operation should avoid this
-	val, _ := strconv.ParseFloat(fmt.Sprint(attributeValue), 64)
+	val, _ := utils.ToFloat64(attributeValue)
 	return val
 }
 
@@ -651,6 +688,8 @@ type MatchmakingResult struct {
 	MatchSessionID  string                 `json:"match_session_id"` // need match session id for sending it to session history
 	PartyAttributes map[string]interface{} `json:"party_attributes"` // this will be populated when receiving joinable session queue request
 	PivotID         string                 `json:"pivot_id"`
+
+	RegionPreference []string `json:"region_preference,omitempty"` // ranked regions of the match, the first one is Region
 }
 
 func (r MatchmakingResult) Validate() error {
@@ -750,6 +789,13 @@ func (r MatchmakingResult) GetMemberUserIDSet() map[string]struct{} {
 	return userIDSet
 }
 
+func (r MatchmakingResult) CountPlayer() (count int) {
+	for _, ally := range r.MatchingAllies {
+		count += ally.CountPlayer()
+	}
+	return count
+}
+
 func (r MatchmakingResult) GetBlockedPlayerUserIDs() []string {
 	return GetBlockedPlayerUserIDs(r.PartyAttributes)
 }
@@ -828,6 +874,17 @@ func (a MatchingAlly) GetMemberUserIDs() []string {
 	return userIDs
 }
 
+// GetLargestPartySize returns the player count of the largest party of the ally.
+func (a MatchingAlly) GetLargestPartySize() int {
+	largest := 0
+	for _, p := range a.MatchingParties {
+		if p.CountPlayer() > largest {
+			largest = p.CountPlayer()
+		}
+	}
+	return largest
+}
+
 func (a MatchingAlly) GetBlockedPlayerUserIDs() []string {
 	blockedIDs := make([]string, 0)
 	for _, p := range a.MatchingParties {
@@ -878,6 +935,12 @@ type RuleSet struct {
 	MaxDelayMs                         int                   `bson:"max_delay_ms"                           json:"max_delay_ms,omitempty"                 optional:"true"             valid:"range(0|2147483647)"`
 	DisableBidirectionalLatencyAfterMs int                   `bson:"disable_bidirectional_latency_after_ms" json:"disable_bidirectional_latency_after_ms" optional:"true"             valid:"range(0|2147483647)"`
 	RegionLatencyRuleWeight            *float64              `bson:"region_latency_rule_weight"             json:"region_latency_rule_weight,omitempty"   optional:"true"             valid:"range(0|1000)"`
+	TicketValidation                   TicketValidation      `bson:"ticket_validation"                      json:"ticket_validation,omitempty"            optional:"true"`
+	MatchLogic                         string                `bson:"match_logic"                            json:"match_logic,omitempty"                  optional:"true"` // name of the registered match logic handling this ruleset
+	RegionSelection                    RegionSelection       `bson:"region_selection"                       json:"region_selection,omitempty"             optional:"true"`
+	RegionPolicy                       RegionPolicy          `bson:"region_policy"                          json:"region_policy,omitempty"                optional:"true"`
+	PivotSelection                     PivotSelection        `bson:"pivot_selection"                        json:"pivot_selection,omitempty"              optional:"true"`
+	MatchQuality                       MatchQuality          `bson:"match_quality"                          json:"match_quality,omitempty"                optional:"true"`
 
 	ExtraAttributes ExtraAttributes `bson:"-" json:"extra_attributes,omitempty" optional:"true"`
 
@@ -926,6 +989,26 @@ func (ruleSet *RuleSet) Validate() error {
 		return err
 	}
 
+	if err := ruleSet.TicketValidation.Validate(); err != nil {
+		return err
+	}
+
+	if err := ruleSet.RegionSelection.Validate(); err != nil {
+		return err
+	}
+
+	if err := ruleSet.RegionPolicy.Validate(); err != nil {
+		return err
+	}
+
+	if err := ruleSet.PivotSelection.Validate(); err != nil {
+		return err
+	}
+
+	if err := ruleSet.MatchQuality.Validate(); err != nil {
+		return err
+	}
+
 	if ruleSet.RegionExpansionRangeMs < 0 {
 		return errors.New("region expansion range ms cannot lower than 0")
 	}
@@ -947,7 +1030,7 @@ func (ruleSet *RuleSet) Validate() error {
 	}
 
 	for _, rule := range ruleSet.MatchingRule {
-		if rule.Criteria == constants.DistanceCriteria {
+		if rule.IsDistanceLike() {
 			maxDistance := rule.Reference
 			for _, flexingRule := range ruleSet.FlexingRule {
 				if rule.Attribute == flexingRule.Attribute {
@@ -1016,8 +1099,19 @@ func (r RuleSet) BlockedPlayerAllowedToMatch() bool {
 	return r.BlockedPlayerOption == BlockedPlayerCanMatch
 }
 
+// GetLargestPlayerMaxNumber returns the highest player max number of the alliance rule and the alliance flexing rules.
+func (r RuleSet) GetLargestPlayerMaxNumber() int {
+	largest := r.AllianceRule.GetLargestPlayerMaxNumber()
+	for _, flexingRule := range r.AllianceFlexingRule {
+		if flexingRule.GetLargestPlayerMaxNumber() > largest {
+			largest = flexingRule.GetLargestPlayerMaxNumber()
+		}
+	}
+	return largest
+}
+
 func (r RuleSet) IsSinglePlay() bool {
-	return r.AllianceRule.MinNumber == 1 && r.AllianceRule.MaxNumber == 1 && r.AllianceRule.PlayerMinNumber == 1 && r.AllianceRule.PlayerMaxNumber == 1
+	return !r.AllianceRule.IsAsymmetric() && r.AllianceRule.MinNumber == 1 && r.AllianceRule.MaxNumber == 1 && r.AllianceRule.PlayerMinNumber == 1 && r.AllianceRule.PlayerMaxNumber == 1
 }
 
 func (r RuleSet) GetRegionLatencyRuleWeight() float64 {
@@ -1032,6 +1126,117 @@ type AllianceRule struct {
 	MaxNumber       int `json:"max_number"        valid:"range(0|2147483647)"`
 	PlayerMinNumber int `json:"player_min_number" valid:"range(0|2147483647)"`
 	PlayerMaxNumber int `json:"player_max_number" valid:"range(0|2147483647)"`
+
+	// Teams makes the rule asymmetric, e.g. 1 hunter vs 4 survivors, the team at index i of a match follows Teams[i].
+	// Every team is required, so min_number and max_number must be the number of teams,
+	// and player_min_number and player_max_number are not used.
+	Teams []TeamRule `json:"teams,omitempty"`
+
+	// MaxPremadeSizeDifference limits the difference between the largest party of each team of a match,
+	// e.g. 0 only matches a 5-stack against another 5-stack and 2 a 3-stack against a team of solos at worst.
+	// The teams are not limited when it is omitted, an alliance flexing rule can relax it.
+	MaxPremadeSizeDifference *int `json:"max_premade_size_difference,omitempty"`
+}
+
+// TeamRule is the size, and optionally the ticket requirement, of one team of an asymmetric alliance rule.
+// When Attribute is set, only the tickets with one of the Values in that ticket attribute can join the team.
+type TeamRule struct {
+	Name            string   `json:"name,omitempty"`
+	PlayerMinNumber int      `json:"player_min_number" valid:"range(0|2147483647)"`
+	PlayerMaxNumber int      `json:"player_max_number" valid:"range(0|2147483647)"`
+	Attribute       string   `json:"attribute,omitempty"`
+	Values          []string `json:"values,omitempty"`
+}
+
+func (t TeamRule) Validate() error {
+	if t.PlayerMinNumber > t.PlayerMaxNumber {
+		return fmt.Errorf("team %q maximum player number must be greater than or equal with minimum player number", t.Name)
+	}
+
+	if t.PlayerMinNumber <= 0 {
+		return fmt.Errorf("team %q should have minimum 1 player", t.Name)
+	}
+
+	if t.Attribute != "" && len(t.Values) == 0 {
+		return fmt.Errorf("team %q requires attribute %q without any value", t.Name, t.Attribute)
+	}
+
+	return nil
+}
+
+// Accepts returns true when a ticket with the ticket attributes can join the team.
+func (t TeamRule) Accepts(ticketAttributes map[string]interface{}) bool {
+	if t.Attribute == "" {
+		return true
+	}
+
+	value, ok := ticketAttributes[t.Attribute]
+	if !ok {
+		return false
+	}
+	values, isArr := value.([]interface{})
+	if !isArr {
+		values = []interface{}{value}
+	}
+	for _, v := range values {
+		for _, accepted := range t.Values {
+			if fmt.Sprint(v) == accepted {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// IsAsymmetric returns true when the teams of the rule have their own rule.
+func (rule AllianceRule) IsAsymmetric() bool {
+	return len(rule.Teams) > 0
+}
+
+// GetTeamRule returns the rule of the team at the index, every team of a symmetric rule shares the player numbers of the rule.
+func (rule AllianceRule) GetTeamRule(teamIndex int) TeamRule {
+	if teamIndex >= 0 && teamIndex < len(rule.Teams) {
+		return rule.Teams[teamIndex]
+	}
+	return TeamRule{PlayerMinNumber: rule.PlayerMinNumber, PlayerMaxNumber: rule.PlayerMaxNumber}
+}
+
+// GetMaxPlayer returns the number of players of a full match.
+func (rule AllianceRule) GetMaxPlayer() int {
+	if !rule.IsAsymmetric() {
+		return rule.MaxNumber * rule.PlayerMaxNumber
+	}
+
+	var total int
+	for _, team := range rule.Teams {
+		total += team.PlayerMaxNumber
+	}
+	return total
+}
+
+// GetLargestPlayerMaxNumber returns the highest player max number of the teams.
+func (rule AllianceRule) GetLargestPlayerMaxNumber() int {
+	largest := rule.PlayerMaxNumber
+	if rule.IsAsymmetric() {
+		largest = 0
+	}
+	for _, team := range rule.Teams {
+		if team.PlayerMaxNumber > largest {
+			largest = team.PlayerMaxNumber
+		}
+	}
+	return largest
+}
+
+// GetSmallestPlayerMinNumber returns the lowest player min number of the teams.
+func (rule AllianceRule) GetSmallestPlayerMinNumber() int {
+	smallest := rule.PlayerMinNumber
+	for i, team := range rule.Teams {
+		if i == 0 || team.PlayerMinNumber < smallest {
+			smallest = team.PlayerMinNumber
+		}
+	}
+	return smallest
 }
 
 func (reqData *AllianceRule) Validate() error {
@@ -1048,6 +1253,22 @@ func (reqData *AllianceRule) Validate() error {
 		return errors.New("rule should have minimum 1 alliance")
 	}
 
+	if reqData.MaxPremadeSizeDifference != nil && *reqData.MaxPremadeSizeDifference < 0 {
+		return errors.New("max premade size difference cannot be minus")
+	}
+
+	if reqData.IsAsymmetric() {
+		if reqData.MinNumber != len(reqData.Teams) || reqData.MaxNumber != len(reqData.Teams) {
+			return fmt.Errorf("minimum and maximum alliance number must be %d, the number of teams", len(reqData.Teams))
+		}
+		for _, team := range reqData.Teams {
+			if err := team.Validate(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
 	if reqData.PlayerMinNumber > reqData.PlayerMaxNumber {
 		return errors.New("maximum player number must be greater than or equal with minimum player number")
 	}
@@ -1070,9 +1291,10 @@ func (rule AllianceRule) ValidateAllyMaxOnly(ally MatchingAlly, allyIndex int) e
 	for _, party := range ally.MatchingParties {
 		members = append(members, party.PartyMembers...)
 	}
+	team := rule.GetTeamRule(allyIndex)
 	playerCount := ally.CountPlayer()
-	if playerCount > rule.PlayerMaxNumber {
-		return fmt.Errorf("player count %d more than max %d", playerCount, rule.PlayerMaxNumber)
+	if playerCount > team.PlayerMaxNumber {
+		return fmt.Errorf("player count %d more than max %d", playerCount, team.PlayerMaxNumber)
 	}
 	return nil
 }
@@ -1088,12 +1310,38 @@ func (rule AllianceRule) ValidateAlly(ally MatchingAlly, allyIndex int) error {
 	for _, party := range ally.MatchingParties {
 		members = append(members, party.PartyMembers...)
 	}
+	team := rule.GetTeamRule(allyIndex)
 	playerCount := ally.CountPlayer()
-	if playerCount < rule.PlayerMinNumber {
-		return fmt.Errorf("player count %d less than min %d", playerCount, rule.PlayerMinNumber)
+	if playerCount < team.PlayerMinNumber {
+		return fmt.Errorf("player count %d less than min %d", playerCount, team.PlayerMinNumber)
+	}
+	if playerCount > team.PlayerMaxNumber {
+		return fmt.Errorf("player count %d more than max %d", playerCount, team.PlayerMaxNumber)
+	}
+	return nil
+}
+
+// ValidatePremadeSize validates the largest party of each ally against the max premade size difference of the rule,
+// the allies without any player are not compared
+func (rule AllianceRule) ValidatePremadeSize(allies []MatchingAlly) error {
+	if rule.MaxPremadeSizeDifference == nil {
+		return nil
+	}
+	smallest, largest := -1, -1
+	for _, ally := range allies {
+		if ally.CountPlayer() == 0 {
+			continue
+		}
+		size := ally.GetLargestPartySize()
+		if smallest < 0 || size < smallest {
+			smallest = size
+		}
+		if size > largest {
+			largest = size
+		}
 	}
-	if playerCount > rule.PlayerMaxNumber {
-		return fmt.Errorf("player count %d more than max %d", playerCount, rule.PlayerMaxNumber)
+	if largest-smallest > *rule.MaxPremadeSizeDifference {
+		return fmt.Errorf("largest party size %d and %d differ more than max %d", largest, smallest, *rule.MaxPremadeSizeDifference)
 	}
 	return nil
 }
@@ -1111,21 +1359,30 @@ func (rule AllianceRule) ValidateAllies(allies []MatchingAlly, blockedPlayerOpti
 	minAlly := rule.MinNumber
 	var countAllyWithMinMember int
 	// validate each ally
-	for _, ally := range allies {
+	for allyIndex, ally := range allies {
+		team := rule.GetTeamRule(allyIndex)
 		playerCount := ally.CountPlayer()
 		if playerCount == 0 {
 			continue
 		}
-		if playerCount < rule.PlayerMinNumber {
-			return fmt.Errorf("player count %d less than min %d", playerCount, rule.PlayerMinNumber)
+		if playerCount < team.PlayerMinNumber {
+			return fmt.Errorf("player count %d less than min %d", playerCount, team.PlayerMinNumber)
 		}
 		countAllyWithMinMember++
-		if playerCount > rule.PlayerMaxNumber {
-			return fmt.Errorf("player count %d more than max %d", playerCount, rule.PlayerMaxNumber)
+		if playerCount > team.PlayerMaxNumber {
+			return fmt.Errorf("player count %d more than max %d", playerCount, team.PlayerMaxNumber)
+		}
+		for _, party := range ally.MatchingParties {
+			if !team.Accepts(party.PartyAttributes) {
+				return fmt.Errorf("party %s does not meet the requirement of team %q", party.PartyID, team.Name)
+			}
 		}
 	}
 	if countAllyWithMinMember < minAlly {
-		return fmt.Errorf("player count less than min, should have min %d ally with %d player", minAlly, rule.PlayerMinNumber)
+		return fmt.Errorf("player count less than min, should have min %d ally with %d player", minAlly, rule.GetSmallestPlayerMinNumber())
+	}
+	if err := rule.ValidatePremadeSize(allies); err != nil {
+		return err
 	}
 	/*
 		[AR-7033] check blocked players for:
@@ -1143,7 +1400,7 @@ func (rule AllianceRule) ValidateAllies(allies []MatchingAlly, blockedPlayerOpti
 
 			for _, userID := range memberIDs {
 				if _, exist := blockedIDs[userID]; exist {
-					return fmt.Errorf("there is blocked player as a team, player %s was blocked by other player in the team", userID)
+					return fmt.Errorf("there is blocked player as a team, player %s was blocked by other player in the team", redact.ID(userID))
 				}
 			}
 		}
@@ -1174,6 +1431,10 @@ func (a AllianceFlexingRule) Validate() error {
 // Channel contains channel information.
 type Channel struct {
 	Ruleset RuleSet `bson:"ruleset" json:"ruleset"`
+
+	// internal use, the matches made per region in the current tick and the tickets that failed as a pivot in the previous ticks
+	RegionMatches  *RegionMatchCounter `bson:"-" json:"-"`
+	PivotCooldowns *PivotCooldowns     `bson:"-" json:"-"`
 }
 
 // GetAllianceRules return alliance rule whether it is from game mode or sub game mode.
@@ -1198,10 +1459,10 @@ func (c Channel) GetAllianceRules() []AllianceRule {
 // max="1500"
 // isForBalancing=false.
 type MatchingRule struct {
-	Attribute        string  `bson:"attribute"        json:"attribute"        valid:"stringlength(1|64),lowercase"         x-nullable:"false"`
-	Criteria         string  `bson:"criteria"         json:"criteria"         valid:"in(distance|average|smaller|greater)" x-nullable:"false"`
-	Reference        float64 `bson:"reference"        json:"reference"        valid:"range(0|2147483647)"                  x-nullable:"false"`
-	NormalizationMax float64 `bson:"normalizationMax" json:"normalizationMax" valid:"range(0|2147483647)"                  x-nullable:"false"`
+	Attribute        string  `bson:"attribute"        json:"attribute"        valid:"stringlength(1|64),lowercase"                x-nullable:"false"`
+	Criteria         string  `bson:"criteria"         json:"criteria"         valid:"in(distance|rating|average|smaller|greater)" x-nullable:"false"`
+	Reference        float64 `bson:"reference"        json:"reference"        valid:"range(0|2147483647)"                         x-nullable:"false"`
+	NormalizationMax float64 `bson:"normalizationMax" json:"normalizationMax" valid:"range(0|2147483647)"                         x-nullable:"false"`
 	/*
 		IsForBalancing is nullable because we need to keep for backward compatible with below behaviour:
 		- if all distance rule for isForBalancing is null, then use the first rule as the balancing rule (backward compatibility)
@@ -1210,6 +1471,12 @@ type MatchingRule struct {
 	*/
 	IsForBalancing *bool    `bson:"isForBalancing" json:"isForBalancing"   x-nullable:"true"`
 	Weight         *float64 `bson:"weight"         json:"weight,omitempty" valid:"range(0|1000)" x-nullable:"true"`
+
+	// rating criteria: the attribute is the rating mean, e.g. the mu of TrueSkill or the rating of Glicko,
+	// the window of a ticket is its mean plus or minus reference + deviation_factor * its deviation
+	DeviationAttribute   string  `bson:"deviation_attribute"   json:"deviation_attribute,omitempty"   optional:"true"` // attribute of the rating deviation, e.g. sigma or RD
+	DeviationFactor      float64 `bson:"deviation_factor"      json:"deviation_factor,omitempty"      optional:"true"` // 0 means 2
+	PerformanceDeviation float64 `bson:"performance_deviation" json:"performance_deviation,omitempty" optional:"true"` // deviation of a performance around the rating in the draw probability, e.g. the beta of TrueSkill, 0 means half the reference
 }
 
 func (m MatchingRule) Validate() error {
@@ -1225,6 +1492,14 @@ func (m MatchingRule) Validate() error {
 		return errors.New("matching rule reference cannot be minus")
 	}
 
+	if m.Criteria == constants.RatingCriteria && m.DeviationAttribute == "" {
+		return fmt.Errorf("matching rule for attribute '%s' needs a deviation attribute with the rating criteria", m.Attribute)
+	}
+
+	if m.DeviationFactor < 0 || m.PerformanceDeviation < 0 {
+		return errors.New("matching rule deviation factor and performance deviation cannot be minus")
+	}
+
 	if _, err := validator.ValidateStruct(m); err != nil {
 		return err
 	}
@@ -1232,6 +1507,30 @@ func (m MatchingRule) Validate() error {
 	return nil
 }
 
+// DefaultDeviationFactor is how many deviations widen the window of a rating rule when deviation_factor is not set.
+const DefaultDeviationFactor = 2
+
+// IsDistanceLike returns true if the rule compares the distance of a number, with the distance or rating criteria.
+func (m MatchingRule) IsDistanceLike() bool {
+	return m.Criteria == constants.DistanceCriteria || m.Criteria == constants.RatingCriteria
+}
+
+// GetDeviationFactor returns the deviation factor of a rating rule.
+func (m MatchingRule) GetDeviationFactor() float64 {
+	if m.DeviationFactor == 0 {
+		return DefaultDeviationFactor
+	}
+	return m.DeviationFactor
+}
+
+// GetPerformanceDeviation returns the performance deviation of a rating rule.
+func (m MatchingRule) GetPerformanceDeviation() float64 {
+	if m.PerformanceDeviation == 0 {
+		return m.Reference / 2
+	}
+	return m.PerformanceDeviation
+}
+
 func (m MatchingRule) GetWeight() float64 {
 	if m.Weight == nil {
 		return DefaultWeightValue
@@ -1261,13 +1560,448 @@ func (f FlexingRule) Validate() error {
 	return nil
 }
 
+// EmptyLatencyPolicy decides what to do with a ticket that has no region latencies.
+type EmptyLatencyPolicy string
+
+const (
+	// EmptyLatencyAuto rejects the ticket only when the ruleset uses region latency (region_latency_max_ms > 0). default
+	EmptyLatencyAuto EmptyLatencyPolicy = "auto"
+
+	// EmptyLatencyAllow always accepts the ticket.
+	EmptyLatencyAllow EmptyLatencyPolicy = "allow"
+
+	// EmptyLatencyReject always rejects the ticket.
+	EmptyLatencyReject EmptyLatencyPolicy = "reject"
+)
+
+var AvailableEmptyLatencyPolicies = []EmptyLatencyPolicy{EmptyLatencyAuto, EmptyLatencyAllow, EmptyLatencyReject}
+
+func (e EmptyLatencyPolicy) Validate() error {
+	if e == "" {
+		return nil
+	}
+	if !slices.Contains(AvailableEmptyLatencyPolicies, e) {
+		return fmt.Errorf("invalid empty latency policy %q, available options: %v", e, AvailableEmptyLatencyPolicies)
+	}
+	return nil
+}
+
+// TicketValidation configures the checks done when a ticket is validated before it is queued.
+// Nil booleans are treated as enabled.
+type TicketValidation struct {
+	ValidatePartySize         *bool              `bson:"validate_party_size"         json:"validate_party_size,omitempty"         optional:"true"`                             // party size must fit the largest (flexed) alliance player max number
+	RejectDuplicatePlayers    *bool              `bson:"reject_duplicate_players"    json:"reject_duplicate_players,omitempty"    optional:"true"`                             // the same player ID cannot appear twice in a ticket
+	RequireMatchingAttributes *bool              `bson:"require_matching_attributes" json:"require_matching_attributes,omitempty" optional:"true"`                             // every player must have the attributes used by the matching rules
+	MaxBlockedPlayers         int                `bson:"max_blocked_players"         json:"max_blocked_players,omitempty"         optional:"true" valid:"range(0|2147483647)"` // 0 means unlimited
+	EmptyLatencyPolicy        EmptyLatencyPolicy `bson:"empty_latency_policy"        json:"empty_latency_policy,omitempty"        optional:"true"`
+}
+
+func (t TicketValidation) Validate() error {
+	if t.MaxBlockedPlayers < 0 {
+		return errors.New("ticket validation max blocked players cannot lower than 0")
+	}
+	return t.EmptyLatencyPolicy.Validate()
+}
+
+func (t TicketValidation) IsValidatePartySize() bool {
+	return t.ValidatePartySize == nil || *t.ValidatePartySize
+}
+
+func (t TicketValidation) IsRejectDuplicatePlayers() bool {
+	return t.RejectDuplicatePlayers == nil || *t.RejectDuplicatePlayers
+}
+
+func (t TicketValidation) IsRequireMatchingAttributes() bool {
+	return t.RequireMatchingAttributes == nil || *t.RequireMatchingAttributes
+}
+
+// RejectEmptyLatency returns true if a ticket without latencies should be rejected.
+func (t TicketValidation) RejectEmptyLatency(regionLatencyMaxMs int) bool {
+	switch t.EmptyLatencyPolicy {
+	case EmptyLatencyAllow:
+		return false
+	case EmptyLatencyReject:
+		return true
+	default:
+		return regionLatencyMaxMs > 0
+	}
+}
+
+// RegionObjective is how the latencies of the matched tickets to a region are combined to rank the match regions.
+type RegionObjective string
+
+const (
+	// RegionObjectiveMax ranks the regions by the worst latency of the matched tickets (default value if empty)
+	RegionObjectiveMax RegionObjective = "max"
+
+	// RegionObjectiveMean ranks the regions by the mean latency of the matched tickets
+	RegionObjectiveMean RegionObjective = "mean"
+
+	// RegionObjectivePercentile ranks the regions by a percentile of the latencies of the matched tickets
+	RegionObjectivePercentile RegionObjective = "percentile"
+
+	// RegionObjectivePivot keeps the region searched from the pivot ticket latencies
+	RegionObjectivePivot RegionObjective = "pivot"
+)
+
+var AvailableRegionObjectives = []RegionObjective{RegionObjectiveMax, RegionObjectiveMean, RegionObjectivePercentile, RegionObjectivePivot}
+
+// DefaultRegionPercentile is the percentile used by the percentile objective when none is set.
+const DefaultRegionPercentile = 90
+
+// RegionSelection configures how the match region is chosen once the allies of a match are found.
+type RegionSelection struct {
+	Objective  RegionObjective `bson:"objective"  json:"objective,omitempty"  optional:"true"`
+	Percentile float64         `bson:"percentile" json:"percentile,omitempty" optional:"true"` // percentile of the percentile objective, between 0 and 100, 0 means 90
+}
+
+func (r RegionSelection) Validate() error {
+	if r.Objective != "" && !slices.Contains(AvailableRegionObjectives, r.Objective) {
+		return fmt.Errorf("invalid region selection objective %q, available options: %v", r.Objective, AvailableRegionObjectives)
+	}
+	if r.Percentile < 0 || r.Percentile > 100 {
+		return errors.New("region selection percentile must be between 0 and 100")
+	}
+	return nil
+}
+
+// GetObjective returns the objective, max when it is not set.
+func (r RegionSelection) GetObjective() RegionObjective {
+	if r.Objective == "" {
+		return RegionObjectiveMax
+	}
+	return r.Objective
+}
+
+// GetPercentile returns the percentile of the percentile objective.
+func (r RegionSelection) GetPercentile() float64 {
+	if r.Percentile == 0 {
+		return DefaultRegionPercentile
+	}
+	return r.Percentile
+}
+
+// RegionPolicy steers the matches between the regions on top of the ticket latencies,
+// e.g. away from regions short on server capacity or in a maintenance window.
+type RegionPolicy struct {
+	Weights           map[string]float64 `bson:"weights"              json:"weights,omitempty"              optional:"true"` // latency multiplier when ranking the regions, above 1 steers away from the region, 1 when not set
+	Disabled          []string           `bson:"disabled"             json:"disabled,omitempty"             optional:"true"` // regions never matched into, their sessions are not backfilled either
+	MaxMatchesPerTick map[string]int     `bson:"max_matches_per_tick" json:"max_matches_per_tick,omitempty" optional:"true"` // soft cap, a region over its cap is ranked after the regions under their cap
+}
+
+func (p RegionPolicy) Validate() error {
+	for region, weight := range p.Weights {
+		if weight <= 0 {
+			return fmt.Errorf("region policy weight of region '%s' must be more than 0", region)
+		}
+	}
+	for region, maxMatches := range p.MaxMatchesPerTick {
+		if maxMatches < 0 {
+			return fmt.Errorf("region policy max matches per tick of region '%s' cannot lower than 0", region)
+		}
+	}
+	return nil
+}
+
+// IsDisabled returns true if no match can be made in the region.
+func (p RegionPolicy) IsDisabled(region string) bool {
+	return slices.Contains(p.Disabled, region)
+}
+
+// GetWeight returns the latency multiplier of the region.
+func (p RegionPolicy) GetWeight(region string) float64 {
+	if weight, ok := p.Weights[region]; ok {
+		return weight
+	}
+	return 1
+}
+
+// IsOverCap returns true if the region already has its soft cap of matches in the tick.
+func (p RegionPolicy) IsOverCap(region string, counter *RegionMatchCounter) bool {
+	maxMatches, ok := p.MaxMatchesPerTick[region]
+	return ok && counter.Count(region) >= maxMatches
+}
+
+// RegionMatchCounter counts the matches made per region in one tick, it is safe for concurrent use.
+// A nil counter counts nothing.
+type RegionMatchCounter struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func NewRegionMatchCounter() *RegionMatchCounter {
+	return &RegionMatchCounter{counts: make(map[string]int)}
+}
+
+// Add counts a match made in the region.
+func (c *RegionMatchCounter) Add(region string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.counts[region]++
+}
+
+// Count returns the matches made in the region.
+func (c *RegionMatchCounter) Count(region string) int {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.counts[region]
+}
+
+// MatchQuality scores the new matches between 0 and 1, the higher the better, and rejects the matches scored under
+// the minimum score of the pivot age. The score is the weighted mean of the team balance, skill spread, latency
+// and party mix scores, it is written in the match attributes.
+type MatchQuality struct {
+	Weights     MatchQualityWeights       `bson:"weights"       json:"weights,omitempty"       optional:"true"`
+	Attribute   string                    `bson:"attribute"     json:"attribute,omitempty"     optional:"true"` // member attribute of the skill, the attribute of the first distance matching rule when not set
+	MinScore    float64                   `bson:"min_score"     json:"min_score,omitempty"     optional:"true"` // 0 accepts every match
+	FlexingRule []MatchQualityFlexingRule `bson:"flexing_rule"  json:"flexing_rule,omitempty"  optional:"true"`
+}
+
+// MatchQualityWeights weighs the scores of a match quality, every score weighs 1 when none is set.
+type MatchQualityWeights struct {
+	TeamBalance float64 `bson:"team_balance" json:"team_balance,omitempty" optional:"true"` // difference between the mean skill of the teams
+	SkillSpread float64 `bson:"skill_spread" json:"skill_spread,omitempty" optional:"true"` // difference between the lowest and highest skill
+	Latency     float64 `bson:"latency"      json:"latency,omitempty"      optional:"true"` // highest latency to the match region
+	PartyMix    float64 `bson:"party_mix"    json:"party_mix,omitempty"    optional:"true"` // difference between the share of players in a party of the teams
+}
+
+// MatchQualityFlexingRule lowers the minimum score once the pivot is older than the duration in seconds.
+type MatchQualityFlexingRule struct {
+	Duration int64   `bson:"duration"  json:"duration"`
+	MinScore float64 `bson:"min_score" json:"min_score"`
+}
+
+func (q MatchQuality) Validate() error {
+	if q.Weights.TeamBalance < 0 || q.Weights.SkillSpread < 0 || q.Weights.Latency < 0 || q.Weights.PartyMix < 0 {
+		return errors.New("match quality weights cannot lower than 0")
+	}
+	if q.MinScore < 0 || q.MinScore > 1 {
+		return errors.New("match quality min score must be between 0 and 1")
+	}
+	for _, flexingRule := range q.FlexingRule {
+		if flexingRule.Duration < 0 {
+			return errors.New("match quality flexing rule duration cannot be minus")
+		}
+		if flexingRule.MinScore < 0 || flexingRule.MinScore > 1 {
+			return errors.New("match quality flexing rule min score must be between 0 and 1")
+		}
+	}
+	return nil
+}
+
+// IsEnabled returns true when the matches are scored.
+func (q MatchQuality) IsEnabled() bool {
+	return q.Weights != (MatchQualityWeights{}) || q.Attribute != "" || q.MinScore > 0 || len(q.FlexingRule) > 0
+}
+
+// GetWeights returns the weights, 1 for every score when none is set.
+func (q MatchQuality) GetWeights() MatchQualityWeights {
+	if q.Weights == (MatchQualityWeights{}) {
+		return MatchQualityWeights{TeamBalance: 1, SkillSpread: 1, Latency: 1, PartyMix: 1}
+	}
+	return q.Weights
+}
+
+// GetMinScore returns the minimum score of a pivot of the age, from the flexing rule with the longest duration it passed.
+func (q MatchQuality) GetMinScore(pivotAge time.Duration) float64 {
+	minScore := q.MinScore
+	var longest int64 = -1
+	for _, flexingRule := range q.FlexingRule {
+		if pivotAge > time.Duration(flexingRule.Duration)*time.Second && flexingRule.Duration > longest {
+			longest = flexingRule.Duration
+			minScore = flexingRule.MinScore
+		}
+	}
+	return minScore
+}
+
+// PivotStrategy is the order in which MatchPlayers picks the pivot tickets of a chunk.
+type PivotStrategy string
+
+const (
+	// PivotStrategyOldestFirst picks the oldest ticket (default value if empty)
+	PivotStrategyOldestFirst PivotStrategy = "oldest_first"
+
+	// PivotStrategyMostConstrained picks the ticket with the fewest tickets it can be matched with
+	PivotStrategyMostConstrained PivotStrategy = "most_constrained"
+
+	// PivotStrategyLargestParty picks the ticket with the most players
+	PivotStrategyLargestParty PivotStrategy = "largest_party"
+
+	// PivotStrategyRegionRoundRobin picks the oldest ticket of each preferred region in turn
+	PivotStrategyRegionRoundRobin PivotStrategy = "region_round_robin"
+
+	// PivotStrategyWeightedRandomAge picks a random ticket, the older the ticket the likelier
+	PivotStrategyWeightedRandomAge PivotStrategy = "weighted_random_age"
+)
+
+var AvailablePivotStrategies = []PivotStrategy{
+	PivotStrategyOldestFirst, PivotStrategyMostConstrained, PivotStrategyLargestParty, PivotStrategyRegionRoundRobin, PivotStrategyWeightedRandomAge,
+}
+
+// PivotSelection configures how the pivot tickets are picked. Every strategy picks among the tickets of the highest priority first.
+type PivotSelection struct {
+	Strategy      PivotStrategy `bson:"strategy"       json:"strategy,omitempty"       optional:"true"`
+	CooldownTicks int           `bson:"cooldown_ticks" json:"cooldown_ticks,omitempty" optional:"true"` // ticks of its match pool a ticket is not picked as a pivot after it failed to make a match as one, it can still be matched by other pivots
+}
+
+func (p PivotSelection) Validate() error {
+	if p.Strategy != "" && !slices.Contains(AvailablePivotStrategies, p.Strategy) {
+		return fmt.Errorf("invalid pivot strategy %q, available options: %v", p.Strategy, AvailablePivotStrategies)
+	}
+	if p.CooldownTicks < 0 {
+		return errors.New("pivot cooldown ticks cannot lower than 0")
+	}
+	return nil
+}
+
+// GetStrategy returns the strategy, oldest first when it is not set.
+func (p PivotSelection) GetStrategy() PivotStrategy {
+	if p.Strategy == "" {
+		return PivotStrategyOldestFirst
+	}
+	return p.Strategy
+}
+
+// PivotCooldowns remembers the tickets that failed as a pivot across the ticks, it is safe for concurrent use.
+// A nil registry remembers nothing.
+type PivotCooldowns struct {
+	mu       sync.Mutex
+	tick     int
+	cooldown map[string]pivotCooldown
+}
+
+// pivotCooldown is the tick a ticket failed as a pivot and the last tick of its cooldown
+type pivotCooldown struct {
+	failed int
+	until  int
+}
+
+func NewPivotCooldowns() *PivotCooldowns {
+	return &PivotCooldowns{cooldown: make(map[string]pivotCooldown)}
+}
+
+// NextTick starts a tick and forgets the cooldowns that ended.
+func (c *PivotCooldowns) NextTick() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tick++
+	for partyID, cooldown := range c.cooldown {
+		if cooldown.until < c.tick {
+			delete(c.cooldown, partyID)
+		}
+	}
+}
+
+// Fail puts the party on cooldown for the next ticks.
+func (c *PivotCooldowns) Fail(partyID string, ticks int) {
+	if c == nil || ticks <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cooldown[partyID] = pivotCooldown{failed: c.tick, until: c.tick + ticks}
+}
+
+// IsCoolingDown returns true if the party failed as a pivot in one of the previous ticks of its cooldown.
+func (c *PivotCooldowns) IsCoolingDown(partyID string) bool {
+	if c == nil {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cooldown, ok := c.cooldown[partyID]
+	return ok && cooldown.failed < c.tick && c.tick <= cooldown.until
+}
+
+// PoolPivotCooldowns keeps the pivot cooldowns of each namespace and match pool, so the ticks of a pool
+// are only counted by the matchmaking of that pool. A pool idle for more than idleTicks ticks of the registry
+// is forgotten with its cooldowns. It is safe for concurrent use.
+type PoolPivotCooldowns struct {
+	mu        sync.Mutex
+	tick      int
+	idleTicks int
+	pools     map[poolKey]*poolPivotCooldowns
+}
+
+// poolPivotCooldowns are the pivot cooldowns of a pool and the last tick of the registry that used them
+type poolPivotCooldowns struct {
+	cooldowns *PivotCooldowns
+	lastTick  int
+}
+
+// poolKey is a namespace and match pool
+type poolKey struct {
+	namespace string
+	matchPool string
+}
+
+// NewPoolPivotCooldowns creates the pivot cooldowns of the pools, an idleTicks of 0 or lower never forgets a pool.
+func NewPoolPivotCooldowns(idleTicks int) *PoolPivotCooldowns {
+	return &PoolPivotCooldowns{idleTicks: idleTicks, pools: make(map[poolKey]*poolPivotCooldowns)}
+}
+
+// NextTick starts a tick of the registry, a MakeMatches call of any pool, and forgets the pools idle for more than idleTicks.
+func (p *PoolPivotCooldowns) NextTick() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.tick++
+	if p.idleTicks <= 0 {
+		return
+	}
+	for key, pool := range p.pools {
+		if p.tick-pool.lastTick > p.idleTicks {
+			delete(p.pools, key)
+		}
+	}
+}
+
+// ForPool returns the pivot cooldowns of the namespace and match pool.
+func (p *PoolPivotCooldowns) ForPool(namespace, matchPool string) *PivotCooldowns {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	key := poolKey{namespace: namespace, matchPool: matchPool}
+	pool, ok := p.pools[key]
+	if !ok {
+		pool = &poolPivotCooldowns{cooldowns: NewPivotCooldowns()}
+		p.pools[key] = pool
+	}
+	pool.lastTick = p.tick
+	return pool.cooldowns
+}
+
+// Len returns the number of pools with pivot cooldowns.
+func (p *PoolPivotCooldowns) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.pools)
+}
+
 type MatchOptionRule struct {
 	Options []MatchOption `bson:"options" json:"options"`
 }
 
 type MatchOption struct {
-	Name string `bson:"name" json:"name" valid:"stringlength(1|64)"         x-nullable:"false"`
-	Type string `bson:"type" json:"type" valid:"in(all|any|unique|disable)" x-nullable:"false"`
+	Name          string   `bson:"name"           json:"name"                     valid:"stringlength(1|64)"         x-nullable:"false"`
+	Type          string   `bson:"type"           json:"type"                     valid:"in(all|any|unique|disable)" x-nullable:"false"`
+	AllowedValues []string `bson:"allowed_values" json:"allowed_values,omitempty" optional:"true"` // when set, ticket values for this option must be one of these
+}
+
+// IsValueAllowed returns true if the value is allowed for this match option.
+func (m MatchOption) IsValueAllowed(value string) bool {
+	if len(m.AllowedValues) == 0 {
+		return true
+	}
+	return slices.Contains(m.AllowedValues, value)
 }
 
 func (m MatchOption) Validate() error {
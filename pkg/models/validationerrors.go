@@ -6,6 +6,7 @@ package models
 
 import (
 	"errors"
+	"fmt"
 )
 
 var (
@@ -33,3 +34,34 @@ func ValidationErrorCode(err error) int {
 	}
 	return code
 }
+
+// TicketValidationReason is a machine-readable reason of a ticket validation failure.
+type TicketValidationReason string
+
+const (
+	TicketValidationPartyTooLarge           TicketValidationReason = "party_too_large"
+	TicketValidationDuplicatePlayer         TicketValidationReason = "duplicate_player"
+	TicketValidationMissingAttribute        TicketValidationReason = "missing_player_attribute"
+	TicketValidationMatchOptionNotAllowed   TicketValidationReason = "match_option_not_allowed"
+	TicketValidationTooManyBlockedPlayers   TicketValidationReason = "too_many_blocked_players"
+	TicketValidationEmptyLatency            TicketValidationReason = "empty_latency"
+	TicketValidationNoRegionLatencyBelowMax TicketValidationReason = "no_region_latency_below_max"
+)
+
+// TicketValidationError is returned when a ticket fails validation.
+type TicketValidationError struct {
+	Reason TicketValidationReason
+	Detail string
+}
+
+// NewTicketValidationError creates a TicketValidationError with a formatted detail message.
+func NewTicketValidationError(reason TicketValidationReason, format string, args ...interface{}) *TicketValidationError {
+	return &TicketValidationError{
+		Reason: reason,
+		Detail: fmt.Sprintf(format, args...),
+	}
+}
+
+func (e *TicketValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Reason, e.Detail)
+}
@@ -0,0 +1,168 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+// Package redact redacts the user IDs and blocked player lists written to the logs.
+// It has no dependencies, so every package can redact the IDs it formats into a message.
+package redact
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+// Redaction modes selectable with LOG_REDACTION.
+const (
+	ModeNone   = "none"
+	ModeHash   = "hash" // default
+	ModeRedact = "redact"
+)
+
+// RedactedValue replaces every redacted value in the redact mode.
+const RedactedValue = "[REDACTED]"
+
+// fields are the normalized field names holding user IDs or blocked player lists
+var fields = map[string]bool{
+	"userid":               true,
+	"userids":              true,
+	"playerid":             true,
+	"partyleaderid":        true,
+	"memberids":            true,
+	"blockedplayers":       true,
+	"blockedplayersdetail": true,
+}
+
+var mode atomic.Value
+
+// key keys the hash of the redacted values, so they can't be recovered by hashing known user IDs
+var key atomic.Value
+
+func init() {
+	randomKey := make([]byte, 32)
+	if _, err := rand.Read(randomKey); err != nil {
+		panic(fmt.Sprintf("failed to generate the log redaction key: %v", err))
+	}
+	key.Store(randomKey)
+}
+
+// SetMode sets how user IDs and blocked player lists are written to the logs.
+func SetMode(redaction string) error {
+	redaction = strings.ToLower(strings.TrimSpace(redaction))
+	switch redaction {
+	case ModeNone, ModeHash, ModeRedact:
+		mode.Store(redaction)
+		return nil
+	case "":
+		mode.Store(ModeNone)
+		return nil
+	default:
+		return fmt.Errorf("unsupported log redaction %q", redaction)
+	}
+}
+
+// Mode returns the redaction mode, none until it is set.
+func Mode() string {
+	redaction, _ := mode.Load().(string)
+	if redaction == "" {
+		return ModeNone
+	}
+	return redaction
+}
+
+// SetKey sets the secret key of the hashed values. Replicas sharing the key log the same hash for a user ID,
+// without it every process hashes with its own random key.
+func SetKey(secret string) {
+	if secret == "" {
+		return
+	}
+	key.Store([]byte(secret))
+}
+
+// Value returns the value as it should be logged for a user ID or a blocked player list.
+func Value(value interface{}) interface{} {
+	return redactValue(Mode(), value)
+}
+
+func redactValue(redaction string, value interface{}) interface{} {
+	if redaction == ModeNone || value == nil {
+		return value
+	}
+
+	switch v := value.(type) {
+	case []interface{}:
+		redacted := make([]interface{}, len(v))
+		for i := range v {
+			redacted[i] = redactValue(redaction, v[i])
+		}
+		return redacted
+	case []string:
+		redacted := make([]string, len(v))
+		for i := range v {
+			redacted[i] = fmt.Sprint(redactValue(redaction, v[i]))
+		}
+		return redacted
+	case map[string]interface{}:
+		// e.g. blocked_players_detail, keep the structure but redact every value
+		redacted := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			redacted[key] = redactValue(redaction, item)
+		}
+		return redacted
+	}
+
+	if redaction == ModeRedact {
+		return RedactedValue
+	}
+
+	str := fmt.Sprint(value)
+	if str == "" {
+		return str
+	}
+	mac := hmac.New(sha256.New, key.Load().([]byte))
+	mac.Write([]byte(str))
+	return "h:" + hex.EncodeToString(mac.Sum(nil)[:6])
+}
+
+// JSON redacts the user ID and blocked player fields of a decoded json value in place.
+func JSON(value interface{}) interface{} {
+	redaction := Mode()
+	if redaction == ModeNone {
+		return value
+	}
+	return redactJSON(redaction, value)
+}
+
+func redactJSON(redaction string, value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if IsField(key) {
+				v[key] = redactValue(redaction, item)
+			} else {
+				v[key] = redactJSON(redaction, item)
+			}
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = redactJSON(redaction, v[i])
+		}
+	}
+	return value
+}
+
+// IsField returns true when the field holds user IDs or blocked player lists, whatever its case and underscores.
+func IsField(name string) bool {
+	return fields[strings.ToLower(strings.ReplaceAll(name, "_", ""))]
+}
+
+// ID is a user ID formatted into a log message or an error, it is redacted like the user ID fields.
+type ID string
+
+func (id ID) String() string {
+	return fmt.Sprint(Value(string(id)))
+}
@@ -0,0 +1,73 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package redact
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// the redaction is global, so these tests are not parallel
+func TestValue_KeyedHash(t *testing.T) {
+	secret := key.Load()
+	t.Cleanup(func() {
+		key.Store(secret)
+		require.NoError(t, SetMode(ModeNone))
+	})
+	require.NoError(t, SetMode(ModeHash))
+
+	SetKey("key-1")
+	hashed := Value("user-1")
+	assert.Equal(t, hashed, Value("user-1"))
+	assert.NotEqual(t, hashed, Value("user-2"))
+
+	// an unkeyed sha256 of a known user ID does not match the logged hash
+	unkeyed := sha256.Sum256([]byte("user-1"))
+	assert.NotEqual(t, "h:"+hex.EncodeToString(unkeyed[:6]), hashed)
+
+	SetKey("key-2")
+	assert.NotEqual(t, hashed, Value("user-1"))
+
+	// an empty key keeps the current one
+	SetKey("")
+	assert.NotEqual(t, hashed, Value("user-1"))
+}
+
+func TestJSON(t *testing.T) {
+	t.Cleanup(func() { require.NoError(t, SetMode(ModeNone)) })
+	require.NoError(t, SetMode(ModeRedact))
+
+	decoded := map[string]interface{}{
+		"ticket_id": "ticket-1",
+		"players":   []interface{}{map[string]interface{}{"player_id": "user-1"}},
+		"attributes": map[string]interface{}{
+			"blocked_players": []interface{}{"user-2"},
+		},
+	}
+	JSON(decoded)
+	assert.Equal(t, "ticket-1", decoded["ticket_id"])
+	assert.Equal(t, RedactedValue, decoded["players"].([]interface{})[0].(map[string]interface{})["player_id"])
+	assert.Equal(t, []interface{}{RedactedValue}, decoded["attributes"].(map[string]interface{})["blocked_players"])
+	assert.Error(t, SetMode("scramble"))
+}
+
+func TestID(t *testing.T) {
+	t.Cleanup(func() { require.NoError(t, SetMode(ModeNone)) })
+
+	require.NoError(t, SetMode(ModeNone))
+	assert.Equal(t, "player user-1 was blocked", fmt.Sprintf("player %s was blocked", ID("user-1")))
+
+	require.NoError(t, SetMode(ModeHash))
+	assert.Equal(t, fmt.Sprintf("player %s was blocked", Value("user-1")), fmt.Sprintf("player %s was blocked", ID("user-1")))
+	assert.NotContains(t, fmt.Sprintf("player %s was blocked", ID("user-1")), "user-1")
+
+	require.NoError(t, SetMode(ModeRedact))
+	assert.Equal(t, RedactedValue, ID("user-1").String())
+}
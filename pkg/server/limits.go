@@ -0,0 +1,187 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package server
+
+import (
+	"fmt"
+
+	"github.com/AccelByte/extend-core-matchmaker/pkg/config"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/matchmaker"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/mathutil"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/metrics"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/models"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// inputLimitErrorDomain is the ErrorInfo domain of input limit errors.
+const inputLimitErrorDomain = "matchmaker.input_limits"
+
+// Input limit reasons, also used as the rejected input metric reason label
+const (
+	limitReasonTicketsPerStream = "too_many_tickets"
+	limitReasonPlayersPerTicket = "too_many_players"
+	limitReasonTicketAttributes = "too_many_attributes"
+	limitReasonBlockedPlayers   = "too_many_blocked_players"
+)
+
+// Limits bounds the input accepted from the callers, a zero limit is unlimited.
+type Limits struct {
+	MaxTicketsPerStream int
+	MaxPlayersPerTicket int
+	MaxTicketAttributes int
+	MaxBlockedPlayers   int
+
+	// TicketBufferSize is the buffer between receiving the tickets and the match logic, 0 is unbuffered
+	TicketBufferSize int
+}
+
+// LimitsFromConfig returns the input limits from the service config.
+func LimitsFromConfig(cfg *config.Config) Limits {
+	return Limits{
+		MaxTicketsPerStream: cfg.MaxTicketsPerStream,
+		MaxPlayersPerTicket: cfg.MaxPlayersPerTicket,
+		MaxTicketAttributes: cfg.MaxTicketAttributes,
+		MaxBlockedPlayers:   cfg.MaxBlockedPlayers,
+		TicketBufferSize:    cfg.TicketBufferSize,
+	}
+}
+
+// checkTicketCount returns a ResourceExhausted error when a stream sent more tickets than allowed.
+func (l Limits) checkTicketCount(count int) error {
+	if l.MaxTicketsPerStream <= 0 || count <= l.MaxTicketsPerStream {
+		return nil
+	}
+
+	return rejectInput(codes.ResourceExhausted, limitReasonTicketsPerStream,
+		fmt.Sprintf("stream sent more than %d tickets", l.MaxTicketsPerStream))
+}
+
+// checkTicket returns an InvalidArgument error when the ticket is larger than allowed.
+// The attributes are counted with every value nested in them, except the blocked players bounded on their own.
+func (l Limits) checkTicket(ticket matchmaker.Ticket) error {
+	if l.MaxPlayersPerTicket > 0 && len(ticket.Players) > l.MaxPlayersPerTicket {
+		return rejectInput(codes.InvalidArgument, limitReasonPlayersPerTicket,
+			fmt.Sprintf("ticket %s has %d players, at most %d are allowed", ticket.TicketID, len(ticket.Players), l.MaxPlayersPerTicket))
+	}
+
+	if l.MaxTicketAttributes > 0 {
+		attributeCount := countAttributes(ticket.TicketAttributes, l.MaxTicketAttributes)
+		for _, player := range ticket.Players {
+			if count := countAttributes(player.Attributes, l.MaxTicketAttributes); count > attributeCount {
+				attributeCount = count
+			}
+		}
+		if attributeCount > l.MaxTicketAttributes {
+			return rejectInput(codes.InvalidArgument, limitReasonTicketAttributes,
+				fmt.Sprintf("ticket %s has more than %d attributes", ticket.TicketID, l.MaxTicketAttributes))
+		}
+	}
+
+	if l.MaxBlockedPlayers > 0 {
+		blockedCount := 0
+		models.RangeBlockedPlayerUserIDs(ticket.TicketAttributes)(func(string) bool {
+			blockedCount++
+			return blockedCount <= l.MaxBlockedPlayers
+		})
+		if blockedCount > l.MaxBlockedPlayers {
+			return rejectInput(codes.InvalidArgument, limitReasonBlockedPlayers,
+				fmt.Sprintf("ticket %s has more than %d blocked players", ticket.TicketID, l.MaxBlockedPlayers))
+		}
+	}
+
+	return nil
+}
+
+// checkBackfillTicket checks the tickets of the partial match like the other tickets,
+// and bounds its parties like the tickets and its match attributes like the ticket attributes.
+func (l Limits) checkBackfillTicket(backfillTicket matchmaker.BackfillTicket) error {
+	match := backfillTicket.PartialMatch
+	for _, ticket := range match.Tickets {
+		if err := l.checkTicket(ticket); err != nil {
+			return err
+		}
+	}
+
+	if l.MaxPlayersPerTicket > 0 {
+		for _, team := range match.Teams {
+			maxTeamPlayers := l.MaxPlayersPerTicket * mathutil.Max(len(team.Parties), 1)
+			if len(team.UserIDs) > maxTeamPlayers {
+				return rejectInput(codes.InvalidArgument, limitReasonPlayersPerTicket,
+					fmt.Sprintf("backfill ticket %s has a team of %d players, at most %d are allowed", backfillTicket.TicketID, len(team.UserIDs), maxTeamPlayers))
+			}
+			for _, party := range team.Parties {
+				if len(party.UserIDs) > l.MaxPlayersPerTicket {
+					return rejectInput(codes.InvalidArgument, limitReasonPlayersPerTicket,
+						fmt.Sprintf("backfill ticket %s has a party of %d players, at most %d are allowed", backfillTicket.TicketID, len(party.UserIDs), l.MaxPlayersPerTicket))
+				}
+			}
+		}
+	}
+
+	if l.MaxTicketAttributes > 0 && countAttributes(match.MatchAttributes, l.MaxTicketAttributes) > l.MaxTicketAttributes {
+		return rejectInput(codes.InvalidArgument, limitReasonTicketAttributes,
+			fmt.Sprintf("backfill ticket %s has more than %d match attributes", backfillTicket.TicketID, l.MaxTicketAttributes))
+	}
+
+	return nil
+}
+
+// countAttributes counts the attributes and the values nested in their maps and lists, it stops counting past the limit.
+// The blocked player attributes count as one, their players are bounded by the blocked players limit.
+func countAttributes(attributes map[string]interface{}, limit int) int {
+	count := 0
+	for key, value := range attributes {
+		count++
+		if count > limit {
+			return count
+		}
+		if key == models.AttributeBlocked || key == models.AttributeBlockedPlayersDetail {
+			continue
+		}
+		count = countNestedValues(value, count, limit)
+	}
+	return count
+}
+
+func countNestedValues(value interface{}, count, limit int) int {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, item := range v {
+			count++
+			if count > limit {
+				return count
+			}
+			count = countNestedValues(item, count, limit)
+		}
+	case []interface{}:
+		for _, item := range v {
+			count++
+			if count > limit {
+				return count
+			}
+			count = countNestedValues(item, count, limit)
+		}
+	}
+	return count
+}
+
+// rejectInput counts the rejected input by reason and returns the status error carrying the machine-readable reason.
+// The namespace and match pool of the rejected input come from the caller, they are logged but not used as labels.
+func rejectInput(code codes.Code, reason, message string) error {
+	metrics.RejectedInput.WithLabelValues(reason).Inc()
+
+	st := status.New(code, message)
+	stWithDetails, err := st.WithDetails(&errdetails.ErrorInfo{
+		Reason: reason,
+		Domain: inputLimitErrorDomain,
+	})
+	if err != nil {
+		return st.Err()
+	}
+	return stWithDetails.Err()
+}
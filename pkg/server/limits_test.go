@@ -0,0 +1,206 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package server
+
+import (
+	"testing"
+
+	"github.com/AccelByte/extend-core-matchmaker/pkg/matchmaker"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/metrics"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/models"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/playerdata"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/testsetup"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func limitReason(err error) string {
+	for _, detail := range status.Convert(err).Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok && info.Domain == inputLimitErrorDomain {
+			return info.Reason
+		}
+	}
+	return ""
+}
+
+func TestLimits_CheckTicket(t *testing.T) {
+	t.Parallel()
+
+	limits := Limits{MaxPlayersPerTicket: 2, MaxTicketAttributes: 2, MaxBlockedPlayers: 1}
+
+	testCases := []struct {
+		name       string
+		ticket     matchmaker.Ticket
+		wantReason string
+	}{
+		{
+			name: "within limits",
+			ticket: matchmaker.Ticket{
+				Players:          []playerdata.PlayerData{{PlayerID: "a"}, {PlayerID: "b"}},
+				TicketAttributes: map[string]interface{}{models.AttributeBlocked: []interface{}{"c"}},
+			},
+		},
+		{
+			name:       "too many players",
+			ticket:     matchmaker.Ticket{Players: []playerdata.PlayerData{{PlayerID: "a"}, {PlayerID: "b"}, {PlayerID: "c"}}},
+			wantReason: limitReasonPlayersPerTicket,
+		},
+		{
+			name:       "too many ticket attributes",
+			ticket:     matchmaker.Ticket{TicketAttributes: map[string]interface{}{"a": 1, "b": 2, "c": 3}},
+			wantReason: limitReasonTicketAttributes,
+		},
+		{
+			name: "too many player attributes",
+			ticket: matchmaker.Ticket{Players: []playerdata.PlayerData{
+				{PlayerID: "a", Attributes: map[string]interface{}{"a": 1, "b": 2, "c": 3}},
+			}},
+			wantReason: limitReasonTicketAttributes,
+		},
+		{
+			name: "too many nested ticket attributes",
+			ticket: matchmaker.Ticket{TicketAttributes: map[string]interface{}{
+				"a": map[string]interface{}{"b": map[string]interface{}{"c": 1}},
+			}},
+			wantReason: limitReasonTicketAttributes,
+		},
+		{
+			name: "too many listed ticket attributes",
+			ticket: matchmaker.Ticket{TicketAttributes: map[string]interface{}{
+				"a": []interface{}{1, 2},
+			}},
+			wantReason: limitReasonTicketAttributes,
+		},
+		{
+			name:       "too many blocked players",
+			ticket:     matchmaker.Ticket{TicketAttributes: map[string]interface{}{models.AttributeBlocked: []interface{}{"c", "d"}}},
+			wantReason: limitReasonBlockedPlayers,
+		},
+		{
+			name: "too many blocked players in the detail",
+			ticket: matchmaker.Ticket{TicketAttributes: map[string]interface{}{
+				models.AttributeBlockedPlayersDetail: []interface{}{
+					map[string]interface{}{models.AttributeBlocker: "a", models.AttributeBlocked: []interface{}{"c", "d"}},
+				},
+			}},
+			wantReason: limitReasonBlockedPlayers,
+		},
+		{
+			name: "blocked players in both forms count once",
+			ticket: matchmaker.Ticket{TicketAttributes: map[string]interface{}{
+				models.AttributeBlocked: []interface{}{"c"},
+				models.AttributeBlockedPlayersDetail: []interface{}{
+					map[string]interface{}{models.AttributeBlocker: "a", models.AttributeBlocked: []interface{}{"c"}},
+				},
+			}},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			g := testsetup.ParallelWithGomega(t)
+
+			// the rejected input counters are shared by the cases, they only grow
+			counter := metrics.RejectedInput.WithLabelValues(testCase.wantReason)
+			before := testutil.ToFloat64(counter)
+			err := limits.checkTicket(testCase.ticket)
+
+			if testCase.wantReason == "" {
+				g.Expect(err).ToNot(HaveOccurred())
+				return
+			}
+			g.Expect(status.Code(err)).To(Equal(codes.InvalidArgument))
+			g.Expect(limitReason(err)).To(Equal(testCase.wantReason))
+			g.Expect(testutil.ToFloat64(counter)).To(BeNumerically(">", before))
+		})
+	}
+}
+
+func TestLimits_CheckTicketCount(t *testing.T) {
+	g := testsetup.ParallelWithGomega(t)
+
+	limits := Limits{MaxTicketsPerStream: 2}
+	g.Expect(limits.checkTicketCount(2)).To(Succeed())
+
+	counter := metrics.RejectedInput.WithLabelValues(limitReasonTicketsPerStream)
+	before := testutil.ToFloat64(counter)
+	err := limits.checkTicketCount(3)
+	g.Expect(status.Code(err)).To(Equal(codes.ResourceExhausted))
+	g.Expect(limitReason(err)).To(Equal(limitReasonTicketsPerStream))
+	g.Expect(testutil.ToFloat64(counter)).To(BeNumerically(">", before))
+
+	unlimited := Limits{}
+	g.Expect(unlimited.checkTicketCount(1000)).To(Succeed())
+}
+
+func TestLimits_CheckBackfillTicket(t *testing.T) {
+	t.Parallel()
+
+	limits := Limits{MaxPlayersPerTicket: 2, MaxTicketAttributes: 2, MaxBlockedPlayers: 1}
+
+	testCases := []struct {
+		name       string
+		match      matchmaker.Match
+		wantReason string
+	}{
+		{
+			name: "within limits",
+			match: matchmaker.Match{
+				Tickets: []matchmaker.Ticket{{Players: []playerdata.PlayerData{{PlayerID: "a"}, {PlayerID: "b"}}}},
+				Teams: []matchmaker.Team{{
+					UserIDs: []playerdata.ID{"a", "b", "c"},
+					Parties: []matchmaker.Party{{UserIDs: []string{"a", "b"}}, {UserIDs: []string{"c"}}},
+				}},
+				MatchAttributes: map[string]interface{}{"a": 1},
+			},
+		},
+		{
+			name:       "ticket with too many players",
+			match:      matchmaker.Match{Tickets: []matchmaker.Ticket{{Players: []playerdata.PlayerData{{PlayerID: "a"}, {PlayerID: "b"}, {PlayerID: "c"}}}}},
+			wantReason: limitReasonPlayersPerTicket,
+		},
+		{
+			name: "ticket with too many blocked players",
+			match: matchmaker.Match{Tickets: []matchmaker.Ticket{{
+				TicketAttributes: map[string]interface{}{models.AttributeBlocked: []interface{}{"c", "d"}},
+			}}},
+			wantReason: limitReasonBlockedPlayers,
+		},
+		{
+			name:       "party with too many players",
+			match:      matchmaker.Match{Teams: []matchmaker.Team{{Parties: []matchmaker.Party{{UserIDs: []string{"a", "b", "c"}}}}}},
+			wantReason: limitReasonPlayersPerTicket,
+		},
+		{
+			name:       "team with too many players",
+			match:      matchmaker.Match{Teams: []matchmaker.Team{{UserIDs: []playerdata.ID{"a", "b", "c"}}}},
+			wantReason: limitReasonPlayersPerTicket,
+		},
+		{
+			name:       "too many match attributes",
+			match:      matchmaker.Match{MatchAttributes: map[string]interface{}{"a": []interface{}{1, 2}}},
+			wantReason: limitReasonTicketAttributes,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			g := testsetup.ParallelWithGomega(t)
+
+			err := limits.checkBackfillTicket(matchmaker.BackfillTicket{TicketID: "backfill", PartialMatch: testCase.match})
+			if testCase.wantReason == "" {
+				g.Expect(err).ToNot(HaveOccurred())
+				return
+			}
+			g.Expect(status.Code(err)).To(Equal(codes.InvalidArgument))
+			g.Expect(limitReason(err)).To(Equal(testCase.wantReason))
+		})
+	}
+}
@@ -13,16 +13,36 @@ import (
 	"github.com/AccelByte/extend-core-matchmaker/pkg/common"
 	"github.com/AccelByte/extend-core-matchmaker/pkg/envelope"
 	"github.com/AccelByte/extend-core-matchmaker/pkg/matchmaker"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/models"
 	matchfunctiongrpc "github.com/AccelByte/extend-core-matchmaker/pkg/pb"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/tickhistory"
 
 	"github.com/sirupsen/logrus"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
+// ticketValidationErrorDomain is the ErrorInfo domain of ticket validation errors.
+const ticketValidationErrorDomain = "matchmaker.ticket_validation"
+
 // MatchFunctionServer is for the handler (upper level of match logic)
 type MatchFunctionServer struct {
 	matchfunctiongrpc.UnimplementedMatchFunctionServer
 	MM matchmaker.MatchLogic
 
+	// Registry selects the match logic per request, nil always uses MM
+	Registry *matchmaker.Registry
+
+	// RulesCache caches the parsed rulesets, nil disables caching
+	RulesCache *RulesCache
+
+	// TickHistory records the recent ticks for the debug endpoints, nil disables recording
+	TickHistory *tickhistory.History
+
+	// Limits bounds the tickets accepted from the callers, the zero value is unlimited
+	Limits Limits
+
 	shipCountMin     int
 	shipCountMax     int
 	unmatchedTickets []*matchmaker.Ticket
@@ -34,10 +54,10 @@ type matchTicketProvider struct {
 	channelBackfillTickets chan matchmaker.BackfillTicket
 }
 
-func newMatchTicketProvider() matchTicketProvider {
+func newMatchTicketProvider(bufferSize int) matchTicketProvider {
 	return matchTicketProvider{
-		channelTickets:         make(chan matchmaker.Ticket),
-		channelBackfillTickets: make(chan matchmaker.BackfillTicket),
+		channelTickets:         make(chan matchmaker.Ticket, bufferSize),
+		channelBackfillTickets: make(chan matchmaker.BackfillTicket, bufferSize),
 	}
 }
 
@@ -51,19 +71,42 @@ func (m matchTicketProvider) GetBackfillTickets() chan matchmaker.BackfillTicket
 	return m.channelBackfillTickets
 }
 
+// drain discards the tickets left in the channels until they are closed
+func (m matchTicketProvider) drain() {
+	tickets, backfillTickets := m.channelTickets, m.channelBackfillTickets
+	for tickets != nil || backfillTickets != nil {
+		select {
+		case _, ok := <-tickets:
+			if !ok {
+				tickets = nil
+			}
+		case _, ok := <-backfillTickets:
+			if !ok {
+				backfillTickets = nil
+			}
+		}
+	}
+}
+
+// rulesFromJSON parses the ruleset json with the match logic using the rules cache when it's enabled
+func (m *MatchFunctionServer) rulesFromJSON(scope *envelope.Scope, name string, mm matchmaker.MatchLogic, json string) (interface{}, error) {
+	return m.RulesCache.RulesFromJSON(scope, name, mm, json)
+}
+
 // GetStatCodes uses the assigned MatchMaker to get the stat codes of the ruleset
 func (m *MatchFunctionServer) GetStatCodes(ctx context.Context, req *matchfunctiongrpc.GetStatCodesRequest) (*matchfunctiongrpc.StatCodesResponse, error) {
 	scope := envelope.ChildScopeFromRemoteScope(ctx, "MatchFunctionServer.GetStatCodes")
 	defer scope.Finish()
 
-	rules, err := m.MM.RulesFromJSON(scope, req.Rules.Json)
+	name, mm := m.matchLogic(scope, req.Rules.Json, "")
+	rules, err := m.rulesFromJSON(scope, name, mm, req.Rules.Json)
 	if err != nil {
 		scope.Log.Errorf("could not get rules from json: %s", err)
 
 		return nil, err
 	}
 
-	codes := m.MM.GetStatCodes(scope, rules)
+	codes := mm.GetStatCodes(scope, rules)
 
 	return &matchfunctiongrpc.StatCodesResponse{Codes: codes}, nil
 }
@@ -75,16 +118,44 @@ func (m *MatchFunctionServer) ValidateTicket(ctx context.Context, req *matchfunc
 
 	scope.Log.Info("GRPC SERVICE: validate ticket")
 
-	rules, err := m.MM.RulesFromJSON(scope, req.Rules.Json)
+	name, mm := m.matchLogic(scope, req.Rules.Json, req.Ticket.GetMatchPool())
+	rules, err := m.rulesFromJSON(scope, name, mm, req.Rules.Json)
 	if err != nil {
 		scope.Log.Errorf("could not get rules from json: %s", err)
 	}
 
 	matchTicket := matchfunctiongrpc.ProtoTicketToMatchfunctionTicket(req.Ticket)
+	if err = m.Limits.checkTicket(matchTicket); err != nil {
+		logRejectedTicket(scope, matchTicket, err)
+		return &matchfunctiongrpc.ValidateTicketResponse{ValidTicket: false}, err
+	}
+
+	validTicket, err := mm.ValidateTicket(scope, matchTicket, rules)
+	if err != nil {
+		return &matchfunctiongrpc.ValidateTicketResponse{ValidTicket: validTicket}, toValidateTicketStatusError(err)
+	}
+
+	return &matchfunctiongrpc.ValidateTicketResponse{ValidTicket: validTicket}, nil
+}
 
-	validTicket, err := m.MM.ValidateTicket(scope, matchTicket, rules)
+// toValidateTicketStatusError converts a ticket validation error into an InvalidArgument status
+// carrying the machine-readable reason, other errors are returned as is.
+func toValidateTicketStatusError(err error) error {
+	var validationErr *models.TicketValidationError
+	if !errors.As(err, &validationErr) {
+		return err
+	}
 
-	return &matchfunctiongrpc.ValidateTicketResponse{ValidTicket: validTicket}, err
+	st := status.New(codes.InvalidArgument, validationErr.Error())
+	stWithDetails, detailErr := st.WithDetails(&errdetails.ErrorInfo{
+		Reason:   string(validationErr.Reason),
+		Domain:   ticketValidationErrorDomain,
+		Metadata: map[string]string{"detail": validationErr.Detail},
+	})
+	if detailErr != nil {
+		return st.Err()
+	}
+	return stWithDetails.Err()
 }
 
 // EnrichTicket uses the assigned MatchMaker to enrich the ticket
@@ -92,21 +163,30 @@ func (m *MatchFunctionServer) EnrichTicket(ctx context.Context, req *matchfuncti
 	scope := envelope.ChildScopeFromRemoteScope(ctx, "MatchFunctionServer.EnrichTicket")
 	defer scope.Finish()
 
-	scope.Log.Infof("GRPC SERVICE: enrich ticket: %s \n", common.LogJSONFormatter(req.Ticket))
+	scope.Log.Debugf("GRPC SERVICE: enrich ticket: %s", common.LogJSON(req.Ticket))
+	_, mm := m.matchLogic(scope, req.Rules.Json, req.Ticket.GetMatchPool())
+
 	matchTicket := matchfunctiongrpc.ProtoTicketToMatchfunctionTicket(req.Ticket)
-	enrichedTicket, err := m.MM.EnrichTicket(scope, matchTicket, req.Rules)
+	if err := m.Limits.checkTicket(matchTicket); err != nil {
+		logRejectedTicket(scope, matchTicket, err)
+		return nil, err
+	}
+	// the match logic gets the rules of the request as they are, it decodes them itself if it needs them
+	enrichedTicket, err := mm.EnrichTicket(scope, matchTicket, req.Rules)
 	if err != nil {
 		return nil, err
 	}
 	newTicket := matchfunctiongrpc.MatchfunctionTicketToProtoTicket(enrichedTicket)
 
 	response := &matchfunctiongrpc.EnrichTicketResponse{Ticket: newTicket}
-	scope.Log.Infof("Response enrich ticket: %s \n", common.LogJSONFormatter(response))
+	scope.Log.Debugf("Response enrich ticket: %s", common.LogJSON(response))
 
 	return response, nil
 }
 
-// MakeMatches uses the assigned MatchMaker to build matches and sends them back to the client
+// MakeMatches uses the assigned MatchMaker to build matches and sends them back to the client.
+// A ticket rejected by the limits ends the stream with its error and stops sending matches,
+// the matches already sent before the rejection, built from the earlier tickets, are not taken back.
 func (m *MatchFunctionServer) MakeMatches(server matchfunctiongrpc.MatchFunction_MakeMatchesServer) error {
 	scope := envelope.ChildScopeFromRemoteScope(context.Background(), "MatchFunctionServer.MakeMatches")
 	defer scope.Finish()
@@ -130,7 +210,17 @@ func (m *MatchFunctionServer) MakeMatches(server matchfunctiongrpc.MatchFunction
 	// scope := envelope.NewRootScope(context.Background(), "GRPC.MakeMatches", mrpT.Parameters.Scope.AbTraceId)
 	//defer scope.Finish()
 
-	rules, err := m.MM.RulesFromJSON(scope, mrpT.Parameters.Rules.Json)
+	// the match pool is only known from the tickets, peek the first one when the match logic is selected by pool
+	stream := &peekedStream[matchfunctiongrpc.MakeMatchesRequest]{recv: server.Recv}
+	var matchPool string
+	if m.needsMatchPool(mrpT.Parameters.Rules.Json) {
+		if next, err := stream.Peek(); err == nil {
+			matchPool = next.GetTicket().GetMatchPool()
+		}
+	}
+
+	name, mm := m.matchLogic(scope, mrpT.Parameters.Rules.Json, matchPool)
+	rules, err := m.rulesFromJSON(scope, name, mm, mrpT.Parameters.Rules.Json)
 	if err != nil {
 		scope.Log.WithError(err).Error("could not get rules from json")
 
@@ -139,20 +229,33 @@ func (m *MatchFunctionServer) MakeMatches(server matchfunctiongrpc.MatchFunction
 
 	scope.Log.WithField("rules", common.LogJSONFormatter(rules)).Infof("Retrieved rules")
 
-	ticketProvider := newMatchTicketProvider()
-	resultChan := m.MM.MakeMatches(scope, ticketProvider, rules)
+	recorder := m.TickHistory.Start(tickhistory.KindMakeMatches, mrpT.Parameters.Rules.Json)
+	defer recorder.Finish()
+	scope.Ctx = tickhistory.WithRecorder(scope.Ctx, recorder)
+
+	ticketProvider := newMatchTicketProvider(m.Limits.TicketBufferSize)
+	resultChan := mm.MakeMatches(scope, ticketProvider, rules)
 	wg := sync.WaitGroup{}
 
+	// recvErr is only written by the receiving goroutine and read after it's done,
+	// rejected is closed before the ticket channels when the stream ends with an error
+	var recvErr error
+	rejected := make(chan struct{})
+
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
 		defer func() {
+			if recvErr != nil {
+				close(rejected)
+			}
 			close(ticketProvider.channelTickets)
 			close(ticketProvider.channelBackfillTickets)
 		}()
 
+		ticketCount := 0
 		for {
-			req, err := server.Recv()
+			req, err := stream.Recv()
 			if err == io.EOF {
 				scope.Log.Debug("Recv ended")
 
@@ -160,19 +263,29 @@ func (m *MatchFunctionServer) MakeMatches(server matchfunctiongrpc.MatchFunction
 			}
 			if err != nil {
 				scope.Log.WithError(err).Debug("Recv error")
+				recvErr = err
 
 				return
 			}
 			t, ok := req.GetRequestType().(*matchfunctiongrpc.MakeMatchesRequest_Ticket)
 			if !ok {
-				scope.Log.Errorf("not a MakeMatchesRequest_Ticket: %s", t.Ticket)
+				scope.Log.Errorf("not a MakeMatchesRequest_Ticket: %T", req.GetRequestType())
+				recvErr = status.Error(codes.InvalidArgument, "expected a ticket after the parameters")
 
 				return
 			}
 
-			scope.Log.Info("crafting a matchfunctions.Ticket")
 			matchTicket := matchfunctiongrpc.ProtoTicketToMatchfunctionTicket(t.Ticket)
-			scope.Log.Infof("writing match ticket: %s", common.LogJSONFormatter(matchTicket))
+			ticketCount++
+			if err = m.checkStreamTicket(ticketCount, matchTicket); err != nil {
+				logRejectedTicket(scope, matchTicket, err)
+				recvErr = err
+
+				return
+			}
+
+			scope.Sampled(logrus.DebugLevel).Debugf("writing match ticket: %s", common.LogJSON(matchTicket))
+			recorder.Ticket(matchTicket.Namespace, matchTicket.MatchPool, matchTicket.TicketID)
 			ticketProvider.channelTickets <- matchTicket
 		}
 	}()
@@ -181,23 +294,30 @@ func (m *MatchFunctionServer) MakeMatches(server matchfunctiongrpc.MatchFunction
 	go func() {
 		defer wg.Done()
 		for result := range resultChan {
-			scope.Log.Info("crafting a MatchResponse")
+			select {
+			case <-rejected:
+				// keep reading so the match logic can finish
+				continue
+			default:
+			}
+
 			resp := matchfunctiongrpc.MatchResponse{Match: matchfunctiongrpc.MatchfunctionMatchToProtoMatch(result)}
-			scope.Log.Infof("Response: %s", common.LogJSONFormatter(resp))
-			scope.Log.Infof("match made and being sent back to the client: %+v", &resp)
+			scope.Sampled(logrus.InfoLevel).Infof("match made and being sent back to the client: %s", common.LogJSON(&resp))
 			if err := server.Send(&resp); err != nil {
 				scope.Log.WithError(err).Errorf("error on server send")
 
 				return
 			}
 			matchesMade++
+			namespace, matchPool := ticketPartition(result.Tickets)
+			recorder.Matched(namespace, matchPool, result.PivotID, ticketIDs(result.Tickets)...)
 		}
 	}()
 	wg.Wait()
 
 	scope.Log.Infof("make matches finished and %d matches were made", matchesMade)
 
-	return nil
+	return recvErr
 }
 
 // BackfillMatches uses the assigned MatchMaker to run backfill
@@ -226,7 +346,20 @@ func (m *MatchFunctionServer) BackfillMatches(server matchfunctiongrpc.MatchFunc
 		return errors.New("expected parameters in the first message were not met")
 	}
 
-	rules, err := m.MM.RulesFromJSON(scope, mrpT.Parameters.Rules.Json)
+	// the match pool is only known from the tickets, peek the first one when the match logic is selected by pool
+	stream := &peekedStream[matchfunctiongrpc.BackfillMakeMatchesRequest]{recv: server.Recv}
+	var matchPool string
+	if m.needsMatchPool(mrpT.Parameters.Rules.Json) {
+		if next, err := stream.Peek(); err == nil {
+			matchPool = next.GetTicket().GetMatchPool()
+			if matchPool == "" {
+				matchPool = next.GetBackfillTicket().GetMatchPool()
+			}
+		}
+	}
+
+	name, mm := m.matchLogic(scope, mrpT.Parameters.Rules.Json, matchPool)
+	rules, err := m.rulesFromJSON(scope, name, mm, mrpT.Parameters.Rules.Json)
 	if err != nil {
 		scope.Log.WithError(err).Errorf("could not get rules from json")
 
@@ -235,24 +368,43 @@ func (m *MatchFunctionServer) BackfillMatches(server matchfunctiongrpc.MatchFunc
 
 	scope.Log.WithField("rules", common.LogJSONFormatter(rules)).Infof("Retrieved rules")
 
-	ticketProvider := newMatchTicketProvider()
+	recorder := m.TickHistory.Start(tickhistory.KindBackfillMatches, mrpT.Parameters.Rules.Json)
+	defer recorder.Finish()
+	scope.Ctx = tickhistory.WithRecorder(scope.Ctx, recorder)
 
-	go m.fetchBackfillTickets(scope.Ctx, ticketProvider, server)
+	ticketProvider := newMatchTicketProvider(m.Limits.TicketBufferSize)
 
-	backfillProposal := m.MM.BackfillMatches(scope, ticketProvider, rules)
-	for {
-		proposal, ok := <-backfillProposal
-		if !ok {
-			scope.Log.Info("no more proposal")
+	// the receiving error is sent before the ticket channels are closed,
+	// so a rejected stream is known before the match logic can see the end of the tickets
+	recvErr := make(chan error, 1)
+	go func() {
+		defer func() {
+			close(ticketProvider.channelTickets)
+			close(ticketProvider.channelBackfillTickets)
+		}()
+		recvErr <- m.fetchBackfillTickets(scope, ticketProvider, stream)
+	}()
 
-			return nil
+	var fetchErr error
+	fetched := false
+	for proposal := range mm.BackfillMatches(scope, ticketProvider, rules) {
+		if !fetched {
+			select {
+			case fetchErr = <-recvErr:
+				fetched = true
+			default:
+			}
+		}
+		if fetchErr != nil {
+			// a rejected stream sends no more proposals, keep reading so the match logic can finish
+			continue
 		}
 
 		resp := matchfunctiongrpc.BackfillResponse{
 			BackfillProposal: matchfunctiongrpc.MatchfunctionBackfillProposalToProtoBackfillProposal(proposal),
 		}
 
-		scope.Log.WithField("proposal", common.LogJSONFormatter(proposal)).Info("send proposal")
+		scope.Sampled(logrus.InfoLevel).WithField("proposal", common.LogJSON(proposal)).Info("send proposal")
 
 		err = server.Send(&resp)
 		if err != nil {
@@ -260,40 +412,105 @@ func (m *MatchFunctionServer) BackfillMatches(server matchfunctiongrpc.MatchFunc
 
 			return err
 		}
+		namespace, _ := ticketPartition(proposal.AddedTickets)
+		recorder.Backfilled(namespace, proposal.MatchPool, proposal.ProposalID, ticketIDs(proposal.AddedTickets)...)
 	}
-}
+	scope.Log.Info("no more proposal")
 
-func (m *MatchFunctionServer) fetchBackfillTickets(ctx context.Context, ticketProvider matchTicketProvider, server matchfunctiongrpc.MatchFunction_BackfillMatchesServer) {
-	log := logrus.WithContext(ctx)
+	// the match logic can finish before reading every ticket, drain the rest so the receiver isn't blocked
+	go ticketProvider.drain()
+	if !fetched {
+		fetchErr = <-recvErr
+	}
+	return fetchErr
+}
 
-	defer func() {
-		close(ticketProvider.channelTickets)
-		close(ticketProvider.channelBackfillTickets)
-	}()
+// fetchBackfillTickets receives the tickets and backfill tickets until the stream ends, a ticket exceeding the limits ends it with an error.
+// The caller closes the ticket channels.
+func (m *MatchFunctionServer) fetchBackfillTickets(scope *envelope.Scope, ticketProvider matchTicketProvider, server *peekedStream[matchfunctiongrpc.BackfillMakeMatchesRequest]) error {
+	log := scope.Log
+	recorder := tickhistory.FromContext(scope.Ctx)
 
+	ticketCount := 0
 	for {
 		in, err := server.Recv()
 		if err == io.EOF {
 			log.Info("Ticket Recv ended")
 
-			return
+			return nil
 		}
 		if err != nil {
 			log.WithError(err).Error("Recv error")
 
-			return
+			return err
 		}
 
 		if ticket := in.GetTicket(); ticket != nil {
 			t := matchfunctiongrpc.ProtoTicketToMatchfunctionTicket(ticket)
-			log.WithField("matchpool", t.MatchPool).
-				WithField("ticketId", t.TicketID).Info("Received match ticket")
+			ticketCount++
+			if err = m.checkStreamTicket(ticketCount, t); err != nil {
+				logRejectedTicket(scope, t, err)
+
+				return err
+			}
+			scope.Sampled(logrus.DebugLevel).WithField("matchpool", t.MatchPool).
+				WithField("ticketId", t.TicketID).Debug("Received match ticket")
+			recorder.Ticket(t.Namespace, t.MatchPool, t.TicketID)
 			ticketProvider.channelTickets <- t
 		} else if backfillTicket := in.GetBackfillTicket(); backfillTicket != nil {
 			t := matchfunctiongrpc.ProtoBackfillTicketToMatchfunctionBackfillTicket(backfillTicket)
-			log.WithField("matchpool", t.MatchPool).
-				WithField("ticketId", t.TicketID).Info("Received backfill ticket")
+			ticketCount++
+			if err = m.checkStreamBackfillTicket(ticketCount, t); err != nil {
+				log.WithError(err).WithField("matchPool", t.MatchPool).WithField("ticketId", t.TicketID).Warn("backfill ticket rejected")
+
+				return err
+			}
+			scope.Sampled(logrus.DebugLevel).WithField("matchpool", t.MatchPool).
+				WithField("ticketId", t.TicketID).Debug("Received backfill ticket")
+			namespace, _ := ticketPartition(t.PartialMatch.Tickets)
+			recorder.BackfillTicket(namespace, t.MatchPool)
 			ticketProvider.channelBackfillTickets <- t
 		}
 	}
 }
+
+// checkStreamTicket checks the ticket and the amount of tickets received so far against the limits
+func (m *MatchFunctionServer) checkStreamTicket(count int, ticket matchmaker.Ticket) error {
+	if err := m.Limits.checkTicketCount(count); err != nil {
+		return err
+	}
+	return m.Limits.checkTicket(ticket)
+}
+
+// checkStreamBackfillTicket checks the backfill ticket and the amount of tickets received so far against the limits
+func (m *MatchFunctionServer) checkStreamBackfillTicket(count int, backfillTicket matchmaker.BackfillTicket) error {
+	if err := m.Limits.checkTicketCount(count); err != nil {
+		return err
+	}
+	return m.Limits.checkBackfillTicket(backfillTicket)
+}
+
+// logRejectedTicket logs the ticket rejected by the limits, the rejected input metric doesn't carry its namespace and match pool
+func logRejectedTicket(scope *envelope.Scope, ticket matchmaker.Ticket, err error) {
+	scope.Log.WithError(err).
+		WithField("namespace", ticket.Namespace).
+		WithField("matchPool", ticket.MatchPool).
+		WithField("ticketId", ticket.TicketID).
+		Warn("ticket rejected")
+}
+
+// ticketPartition returns the namespace and match pool of the tickets of a match, they all share them
+func ticketPartition(tickets []matchmaker.Ticket) (namespace, matchPool string) {
+	if len(tickets) == 0 {
+		return "", ""
+	}
+	return tickets[0].Namespace, tickets[0].MatchPool
+}
+
+func ticketIDs(tickets []matchmaker.Ticket) []string {
+	ids := make([]string, 0, len(tickets))
+	for _, ticket := range tickets {
+		ids = append(ids, ticket.TicketID)
+	}
+	return ids
+}
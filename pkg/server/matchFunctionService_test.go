@@ -0,0 +1,162 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package server
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/AccelByte/extend-core-matchmaker/pkg/config"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/envelope"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/matchmaker"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/matchmaker/defaultmatchmaker"
+	matchfunctiongrpc "github.com/AccelByte/extend-core-matchmaker/pkg/pb"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/testsetup"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// fakeStream replays the requests then ends with io.EOF, and keeps the responses sent
+type fakeStream[Req, Resp any] struct {
+	grpc.ServerStream
+	requests []*Req
+	sent     []*Resp
+}
+
+func (s *fakeStream[Req, Resp]) Recv() (*Req, error) {
+	if len(s.requests) == 0 {
+		return nil, io.EOF
+	}
+	req := s.requests[0]
+	s.requests = s.requests[1:]
+	return req, nil
+}
+
+func (s *fakeStream[Req, Resp]) Send(resp *Resp) error {
+	s.sent = append(s.sent, resp)
+	return nil
+}
+
+// protoTicket returns a ticket of the players
+func protoTicket(id string, players int) *matchfunctiongrpc.Ticket {
+	ticket := &matchfunctiongrpc.Ticket{TicketId: id, MatchPool: "pool", Namespace: "test", CreatedAt: timestamppb.New(time.Now())}
+	for i := 0; i < players; i++ {
+		ticket.Players = append(ticket.Players, &matchfunctiongrpc.Ticket_PlayerData{PlayerId: id + string(rune('a'+i))})
+	}
+	return ticket
+}
+
+// proposingMatchLogic proposes one backfill after it read every ticket
+type proposingMatchLogic struct {
+	matchmaker.MatchLogic
+}
+
+func (proposingMatchLogic) BackfillMatches(_ *envelope.Scope, ticketProvider matchmaker.TicketProvider, _ interface{}) <-chan matchmaker.BackfillProposal {
+	results := make(chan matchmaker.BackfillProposal)
+	go func() {
+		defer close(results)
+		for range ticketProvider.GetTickets() {
+		}
+		for range ticketProvider.GetBackfillTickets() {
+		}
+		results <- matchmaker.BackfillProposal{ProposalID: "proposal"}
+	}()
+	return results
+}
+
+func TestMakeMatches_RejectedTicket(t *testing.T) {
+	g := testsetup.ParallelWithGomega(t)
+
+	server := &MatchFunctionServer{MM: defaultmatchmaker.New(&config.Config{}), Limits: Limits{MaxPlayersPerTicket: 1}}
+	stream := &fakeStream[matchfunctiongrpc.MakeMatchesRequest, matchfunctiongrpc.MatchResponse]{requests: []*matchfunctiongrpc.MakeMatchesRequest{
+		{RequestType: &matchfunctiongrpc.MakeMatchesRequest_Parameters{Parameters: &matchfunctiongrpc.MakeMatchesRequest_MakeMatchesParameters{
+			Rules: &matchfunctiongrpc.Rules{Json: rulesJSON(2)},
+		}}},
+		{RequestType: &matchfunctiongrpc.MakeMatchesRequest_Ticket{Ticket: protoTicket("first", 1)}},
+		{RequestType: &matchfunctiongrpc.MakeMatchesRequest_Ticket{Ticket: protoTicket("second", 1)}},
+		{RequestType: &matchfunctiongrpc.MakeMatchesRequest_Ticket{Ticket: protoTicket("rejected", 2)}},
+	}}
+
+	err := server.MakeMatches(stream)
+	g.Expect(status.Code(err)).To(Equal(codes.InvalidArgument))
+	// the first two tickets make a match, it's not sent after the rejection
+	g.Expect(stream.sent).To(BeEmpty())
+}
+
+func TestBackfillMatches_RejectedTicket(t *testing.T) {
+	t.Parallel()
+
+	requests := func(tickets ...*matchfunctiongrpc.Ticket) []*matchfunctiongrpc.BackfillMakeMatchesRequest {
+		out := []*matchfunctiongrpc.BackfillMakeMatchesRequest{
+			{RequestType: &matchfunctiongrpc.BackfillMakeMatchesRequest_Parameters{Parameters: &matchfunctiongrpc.BackfillMakeMatchesRequest_MakeMatchesParameters{
+				Rules: &matchfunctiongrpc.Rules{Json: rulesJSON(2)},
+			}}},
+		}
+		for _, ticket := range tickets {
+			out = append(out, &matchfunctiongrpc.BackfillMakeMatchesRequest{RequestType: &matchfunctiongrpc.BackfillMakeMatchesRequest_Ticket{Ticket: ticket}})
+		}
+		return out
+	}
+
+	t.Run("returns the rejection", func(t *testing.T) {
+		g := testsetup.ParallelWithGomega(t)
+
+		server := &MatchFunctionServer{MM: defaultmatchmaker.New(&config.Config{}), Limits: Limits{MaxTicketsPerStream: 1}}
+		for i := 0; i < 20; i++ {
+			stream := &fakeStream[matchfunctiongrpc.BackfillMakeMatchesRequest, matchfunctiongrpc.BackfillResponse]{
+				requests: requests(protoTicket("first", 1), protoTicket("rejected", 1)),
+			}
+			err := server.BackfillMatches(stream)
+			g.Expect(status.Code(err)).To(Equal(codes.ResourceExhausted))
+		}
+	})
+
+	t.Run("sends no proposal after the rejection", func(t *testing.T) {
+		g := testsetup.ParallelWithGomega(t)
+
+		server := &MatchFunctionServer{MM: proposingMatchLogic{defaultmatchmaker.New(&config.Config{})}, Limits: Limits{MaxPlayersPerTicket: 1}}
+		stream := &fakeStream[matchfunctiongrpc.BackfillMakeMatchesRequest, matchfunctiongrpc.BackfillResponse]{
+			requests: requests(protoTicket("first", 1), protoTicket("rejected", 2)),
+		}
+		err := server.BackfillMatches(stream)
+		g.Expect(status.Code(err)).To(Equal(codes.InvalidArgument))
+		g.Expect(stream.sent).To(BeEmpty())
+
+		stream = &fakeStream[matchfunctiongrpc.BackfillMakeMatchesRequest, matchfunctiongrpc.BackfillResponse]{
+			requests: requests(protoTicket("first", 1)),
+		}
+		g.Expect(server.BackfillMatches(stream)).To(Succeed())
+		g.Expect(stream.sent).To(HaveLen(1))
+	})
+}
+
+// enrichingMatchLogic keeps the rules given to EnrichTicket
+type enrichingMatchLogic struct {
+	matchmaker.MatchLogic
+	ruleSet *interface{}
+}
+
+func (l enrichingMatchLogic) EnrichTicket(_ *envelope.Scope, matchTicket matchmaker.Ticket, ruleSet interface{}) (matchmaker.Ticket, error) {
+	*l.ruleSet = ruleSet
+	return matchTicket, nil
+}
+
+func TestEnrichTicket_PassesRequestRules(t *testing.T) {
+	g := testsetup.ParallelWithGomega(t)
+
+	var ruleSet interface{}
+	server := &MatchFunctionServer{MM: enrichingMatchLogic{MatchLogic: defaultmatchmaker.New(&config.Config{}), ruleSet: &ruleSet}}
+	rules := &matchfunctiongrpc.Rules{Json: "not a ruleset"}
+
+	resp, err := server.EnrichTicket(context.Background(), &matchfunctiongrpc.EnrichTicketRequest{Ticket: protoTicket("ticket", 1), Rules: rules})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(resp.Ticket.TicketId).To(Equal("ticket"))
+	g.Expect(ruleSet).To(BeIdenticalTo(rules))
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package server
+
+import (
+	"encoding/json"
+
+	"github.com/AccelByte/extend-core-matchmaker/pkg/envelope"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/matchmaker"
+)
+
+// ruleSetMatchLogic is the part of the ruleset json used to select the match logic
+type ruleSetMatchLogic struct {
+	MatchLogic string `json:"match_logic"`
+}
+
+// matchLogic returns the name and MatchLogic that should handle a request.
+// Without a registry, or when nothing in the registry matches, it falls back to MM.
+func (m *MatchFunctionServer) matchLogic(scope *envelope.Scope, rulesJSON, matchPool string) (string, matchmaker.MatchLogic) {
+	if m.Registry == nil {
+		return "", m.MM
+	}
+
+	ruleSetLogic := m.ruleSetMatchLogicName(rulesJSON)
+	name, logic, ok := m.Registry.Resolve(ruleSetLogic, matchPool)
+	if !ok {
+		scope.Log.WithField("matchLogic", name).Warn("match logic is not registered, using the server match logic")
+		return "", m.MM
+	}
+	if selected := m.Registry.Selected(ruleSetLogic, matchPool); selected != "" && selected != name {
+		scope.Log.WithField("matchLogic", selected).
+			WithField("matchPool", matchPool).
+			WithField("fallback", name).
+			Warn("selected match logic is not registered, using the fallback")
+	}
+
+	return name, logic
+}
+
+// ruleSetMatchLogicName reads the match_logic field of the ruleset json, through the rules cache when it's enabled.
+// The json is only decoded when there is more than one registered match logic to choose from.
+func (m *MatchFunctionServer) ruleSetMatchLogicName(rulesJSON string) string {
+	if m.Registry.Len() <= 1 || rulesJSON == "" {
+		return ""
+	}
+
+	return m.RulesCache.MatchLogicName(rulesJSON)
+}
+
+// decodeMatchLogicName decodes the match_logic field of the ruleset json, an invalid json names no match logic
+func decodeMatchLogicName(rulesJSON string) string {
+	var ruleSet ruleSetMatchLogic
+	if err := json.Unmarshal([]byte(rulesJSON), &ruleSet); err != nil {
+		return ""
+	}
+	return ruleSet.MatchLogic
+}
+
+// needsMatchPool returns true if the match logic of a stream can only be selected after its first ticket is read.
+func (m *MatchFunctionServer) needsMatchPool(rulesJSON string) bool {
+	return m.Registry != nil && m.Registry.HasPoolMapping() && m.ruleSetMatchLogicName(rulesJSON) == ""
+}
+
+// peekedStream replays a message that was read ahead before receiving from the stream again.
+type peekedStream[T any] struct {
+	recv   func() (*T, error)
+	next   *T
+	err    error
+	peeked bool
+}
+
+// Peek reads the next message from the stream without consuming it.
+func (p *peekedStream[T]) Peek() (*T, error) {
+	if !p.peeked {
+		p.next, p.err = p.recv()
+		p.peeked = true
+	}
+	return p.next, p.err
+}
+
+// Recv returns the peeked message first, then reads from the stream.
+func (p *peekedStream[T]) Recv() (*T, error) {
+	if p.peeked {
+		p.peeked = false
+		return p.next, p.err
+	}
+	return p.recv()
+}
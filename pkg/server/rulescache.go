@@ -0,0 +1,183 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package server
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+
+	"github.com/AccelByte/extend-core-matchmaker/pkg/envelope"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/matchmaker"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/models"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// RulesCache is a bounded LRU cache of parsed rulesets keyed by a hash of the ruleset JSON.
+// Only models.RuleSet values are cached, and a copy is returned on every hit
+// so callers can't mutate each other's rules. The match logic named by a ruleset JSON is cached
+// in its own LRU of the same size, it doesn't evict the rulesets nor count in the ruleset metrics.
+type RulesCache struct {
+	rules       *lruCache[models.RuleSet]
+	matchLogics *lruCache[string]
+
+	hits   prometheus.Counter
+	misses prometheus.Counter
+}
+
+// NewRulesCache creates a cache holding at most size rulesets.
+// It returns nil when size is 0 or lower, a nil cache always parses the rules.
+func NewRulesCache(size int) *RulesCache {
+	if size <= 0 {
+		return nil
+	}
+
+	return &RulesCache{
+		rules:       newLRUCache[models.RuleSet](size),
+		matchLogics: newLRUCache[string](size),
+		hits: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "matchmaker_rules_cache_hits_total",
+			Help: "The total number of ruleset cache hits",
+		}),
+		misses: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "matchmaker_rules_cache_misses_total",
+			Help: "The total number of ruleset cache misses",
+		}),
+	}
+}
+
+// RulesFromJSON returns the ruleset for the json from the cache, or parses it with the match logic on a miss.
+// Rulesets are cached per match logic name since each match logic parses the json on its own.
+func (c *RulesCache) RulesFromJSON(scope *envelope.Scope, name string, mm matchmaker.MatchLogic, json string) (interface{}, error) {
+	if c == nil {
+		return mm.RulesFromJSON(scope, json)
+	}
+
+	key := rulesCacheKey(name, json)
+	if ruleSet, ok := c.rules.get(key); ok {
+		c.hits.Inc()
+		return ruleSet.Copy(), nil
+	}
+	c.misses.Inc()
+
+	rules, err := mm.RulesFromJSON(scope, json)
+	if err != nil {
+		return nil, err
+	}
+
+	ruleSet, ok := rules.(models.RuleSet)
+	if !ok {
+		// unknown rules type, we can't guarantee a copy so don't cache it
+		return rules, nil
+	}
+	c.rules.add(key, ruleSet.Copy())
+
+	return ruleSet, nil
+}
+
+// MatchLogicName returns the match_logic field of the ruleset json from the cache, or decodes it on a miss.
+// The json is decoded before the match logic is known, so the name is cached apart from the parsed rulesets.
+func (c *RulesCache) MatchLogicName(json string) string {
+	if c == nil {
+		return decodeMatchLogicName(json)
+	}
+
+	key := rulesCacheKey("", json)
+	if name, ok := c.matchLogics.get(key); ok {
+		return name
+	}
+
+	name := decodeMatchLogicName(json)
+	c.matchLogics.add(key, name)
+
+	return name
+}
+
+// Len returns the number of cached rulesets.
+func (c *RulesCache) Len() int {
+	return c.rules.len()
+}
+
+// Describe implements prometheus.Collector.
+func (c *RulesCache) Describe(ch chan<- *prometheus.Desc) {
+	c.hits.Describe(ch)
+	c.misses.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (c *RulesCache) Collect(ch chan<- prometheus.Metric) {
+	c.hits.Collect(ch)
+	c.misses.Collect(ch)
+}
+
+// lruCache is a bounded least recently used cache safe for concurrent use
+type lruCache[V any] struct {
+	mu       sync.Mutex
+	size     int
+	entries  map[string]*list.Element
+	eviction *list.List
+}
+
+type lruCacheEntry[V any] struct {
+	key   string
+	value V
+}
+
+func newLRUCache[V any](size int) *lruCache[V] {
+	return &lruCache[V]{
+		size:     size,
+		entries:  make(map[string]*list.Element, size),
+		eviction: list.New(),
+	}
+}
+
+func (c *lruCache[V]) get(key string) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	c.eviction.MoveToFront(element)
+
+	return element.Value.(*lruCacheEntry[V]).value, true
+}
+
+func (c *lruCache[V]) add(key string, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		element.Value.(*lruCacheEntry[V]).value = value
+		c.eviction.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.eviction.PushFront(&lruCacheEntry[V]{key: key, value: value})
+	for c.eviction.Len() > c.size {
+		oldest := c.eviction.Back()
+		c.eviction.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruCacheEntry[V]).key)
+	}
+}
+
+func (c *lruCache[V]) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.eviction.Len()
+}
+
+func rulesCacheKey(name, json string) string {
+	hash := sha256.New()
+	hash.Write([]byte(name))
+	hash.Write([]byte{0})
+	hash.Write([]byte(json))
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package server
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/AccelByte/extend-core-matchmaker/pkg/config"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/matchmaker/defaultmatchmaker"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/models"
+	"github.com/AccelByte/extend-core-matchmaker/pkg/testsetup"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func rulesJSON(maxNumber int) string {
+	return fmt.Sprintf(`{"alliance":{"min_number":2,"max_number":%d,"player_min_number":1,"player_max_number":1}}`, maxNumber)
+}
+
+func TestRulesCache_ReturnsCopies(t *testing.T) {
+	g := testsetup.ParallelWithGomega(t)
+	mm := defaultmatchmaker.New(&config.Config{})
+	cache := NewRulesCache(2)
+
+	first, err := cache.RulesFromJSON(testsetup.NewTestScope(), "", mm, rulesJSON(2))
+	g.Expect(err).ToNot(HaveOccurred())
+	firstRuleSet := first.(models.RuleSet)
+	firstRuleSet.AllianceRule.MaxNumber = 10
+
+	second, err := cache.RulesFromJSON(testsetup.NewTestScope(), "", mm, rulesJSON(2))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(second.(models.RuleSet).AllianceRule.MaxNumber).To(Equal(2))
+
+	g.Expect(testutil.ToFloat64(cache.hits)).To(Equal(float64(1)))
+	g.Expect(testutil.ToFloat64(cache.misses)).To(Equal(float64(1)))
+}
+
+func TestRulesCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	g := testsetup.ParallelWithGomega(t)
+	mm := defaultmatchmaker.New(&config.Config{})
+	cache := NewRulesCache(2)
+	scope := testsetup.NewTestScope()
+
+	for _, maxNumber := range []int{2, 3, 2, 4} {
+		_, err := cache.RulesFromJSON(scope, "", mm, rulesJSON(maxNumber))
+		g.Expect(err).ToNot(HaveOccurred())
+	}
+
+	g.Expect(cache.Len()).To(Equal(2))
+	_, ok := cache.rules.get(rulesCacheKey("", rulesJSON(3)))
+	g.Expect(ok).To(BeFalse())
+	_, ok = cache.rules.get(rulesCacheKey("", rulesJSON(2)))
+	g.Expect(ok).To(BeTrue())
+}
+
+func TestRulesCache_DoesNotCacheErrors(t *testing.T) {
+	g := testsetup.ParallelWithGomega(t)
+	mm := defaultmatchmaker.New(&config.Config{})
+	cache := NewRulesCache(2)
+
+	_, err := cache.RulesFromJSON(testsetup.NewTestScope(), "", mm, "{invalid")
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(cache.Len()).To(Equal(0))
+}
+
+func TestRulesCache_NilCacheParsesRules(t *testing.T) {
+	g := testsetup.ParallelWithGomega(t)
+	mm := defaultmatchmaker.New(&config.Config{})
+
+	var cache *RulesCache = NewRulesCache(0)
+	g.Expect(cache).To(BeNil())
+
+	rules, err := cache.RulesFromJSON(testsetup.NewTestScope(), "", mm, rulesJSON(2))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(rules.(models.RuleSet).AllianceRule.MaxNumber).To(Equal(2))
+}
+
+func TestRulesCache_MatchLogicName(t *testing.T) {
+	g := testsetup.ParallelWithGomega(t)
+	cache := NewRulesCache(2)
+	json := `{"match_logic":"custom"}`
+
+	g.Expect(cache.MatchLogicName(json)).To(Equal("custom"))
+	g.Expect(cache.MatchLogicName(json)).To(Equal("custom"))
+
+	// the names are cached apart from the rulesets, they don't count as ruleset hits nor evict rulesets
+	g.Expect(testutil.ToFloat64(cache.hits)).To(Equal(float64(0)))
+	g.Expect(testutil.ToFloat64(cache.misses)).To(Equal(float64(0)))
+	g.Expect(cache.Len()).To(Equal(0))
+
+	mm := defaultmatchmaker.New(&config.Config{})
+	for _, maxNumber := range []int{2, 3} {
+		_, err := cache.RulesFromJSON(testsetup.NewTestScope(), "", mm, rulesJSON(maxNumber))
+		g.Expect(err).ToNot(HaveOccurred())
+	}
+	for i := 0; i < 3; i++ {
+		cache.MatchLogicName(fmt.Sprintf(`{"match_logic":"custom-%d"}`, i))
+	}
+	g.Expect(cache.Len()).To(Equal(2))
+	g.Expect(cache.matchLogics.len()).To(Equal(2))
+
+	var disabled *RulesCache
+	g.Expect(disabled.MatchLogicName(json)).To(Equal("custom"))
+	g.Expect(disabled.MatchLogicName("{invalid")).To(BeEmpty())
+}
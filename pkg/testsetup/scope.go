@@ -9,6 +9,7 @@ import (
 
 	"github.com/AccelByte/extend-core-matchmaker/pkg/envelope"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // NewTestScope creates a new scope for test use
@@ -22,3 +23,8 @@ func NewTestScopeWithLogger(logger *logrus.Logger) *envelope.Scope {
 	scope.SetLogger(logger)
 	return scope
 }
+
+// NewTestScopeWithTracerProvider creates a new scope whose spans are started by the given tracer provider for test use
+func NewTestScopeWithTracerProvider(tracerProvider trace.TracerProvider) *envelope.Scope {
+	return envelope.NewRootScopeWithTracerProvider(context.Background(), tracerProvider, "test", "")
+}
@@ -0,0 +1,41 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package tickhistory
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Endpoint is the path the tick history is served under.
+const Endpoint = "/debug/matchmaker"
+
+// RegisterHandlers serves the tick history on the mux:
+//   - GET /debug/matchmaker lists the recent ticks per pool, filtered by the namespace and match_pool query parameters
+//   - GET /debug/matchmaker/tickets/{ticketID} shows the last tick that saw a ticket and what happened to it
+func (h *History) RegisterHandlers(mux *http.ServeMux) {
+	mux.HandleFunc("GET "+Endpoint, func(w http.ResponseWriter, req *http.Request) {
+		query := req.URL.Query()
+		writeJSON(w, http.StatusOK, h.Pools(query.Get("namespace"), query.Get("match_pool")))
+	})
+	mux.HandleFunc("GET "+Endpoint+"/tickets/{ticketID}", func(w http.ResponseWriter, req *http.Request) {
+		record, ok := h.Ticket(req.PathValue("ticketID"))
+		if !ok {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "ticket not found in the recent ticks"})
+			return
+		}
+		writeJSON(w, http.StatusOK, record)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		logrus.WithError(err).Error("failed to write debug response")
+	}
+}
@@ -0,0 +1,203 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package tickhistory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+type recorderKey struct{}
+
+// Recorder records one tick, all methods are safe to call on a nil recorder.
+// A stream can carry the tickets of several namespaces and match pools, the recorder keeps a tick per partition.
+type Recorder struct {
+	history *History
+
+	mu      sync.Mutex
+	start   Tick // the kind, ruleset and start time shared by the ticks of every partition
+	ticks   map[poolKey]*Tick
+	order   []poolKey
+	records map[string]*TicketRecord
+}
+
+// WithRecorder returns a context carrying the recorder, the match logic records the unmatched reasons with it.
+func WithRecorder(ctx context.Context, r *Recorder) context.Context {
+	if r == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, recorderKey{}, r)
+}
+
+// FromContext returns the recorder of the context, or nil.
+func FromContext(ctx context.Context) *Recorder {
+	r, _ := ctx.Value(recorderKey{}).(*Recorder)
+	return r
+}
+
+// RecordUnmatched records why a ticket was not matched on the recorder of the context, if any.
+func RecordUnmatched(ctx context.Context, ticketID, reason string) {
+	FromContext(ctx).Unmatched(ticketID, reason)
+}
+
+// Ticket records a ticket received in the tick.
+func (r *Recorder) Ticket(namespace, matchPool, ticketID string) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tick := r.partition(namespace, matchPool)
+	tick.Tickets++
+	if _, ok := r.records[ticketID]; ok {
+		return
+	}
+	if len(tick.tickets) >= maxTicketsPerTick {
+		tick.TicketsTruncated = true
+		return
+	}
+	tick.tickets = append(tick.tickets, ticketID)
+	r.records[ticketID] = &TicketRecord{
+		TicketID:  ticketID,
+		Namespace: tick.Namespace,
+		MatchPool: matchPool,
+		TickKind:  tick.Kind,
+		SeenAt:    time.Now(),
+		Outcome:   OutcomeUnmatched,
+	}
+}
+
+// BackfillTicket records a backfill ticket received in the tick.
+func (r *Recorder) BackfillTicket(namespace, matchPool string) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.partition(namespace, matchPool).BackfillTickets++
+}
+
+// Matched records a match made of the tickets around the pivot ticket in the namespace and match pool.
+func (r *Recorder) Matched(namespace, matchPool, pivotID string, ticketIDs ...string) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.partition(namespace, matchPool).Matches++
+	for _, ticketID := range ticketIDs {
+		if record, ok := r.records[ticketID]; ok {
+			record.Outcome = OutcomeMatched
+			record.Reason = ""
+			record.PivotID = pivotID
+		}
+	}
+}
+
+// Backfilled records a backfill proposal adding the tickets in the namespace and match pool.
+func (r *Recorder) Backfilled(namespace, matchPool, proposalID string, ticketIDs ...string) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.partition(namespace, matchPool).Matches++
+	for _, ticketID := range ticketIDs {
+		if record, ok := r.records[ticketID]; ok {
+			record.Outcome = OutcomeBackfilled
+			record.Reason = ""
+			record.ProposalID = proposalID
+		}
+	}
+}
+
+// Unmatched records why a ticket was not matched, the first reason recorded for a ticket is kept.
+func (r *Recorder) Unmatched(ticketID, reason string) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if record, ok := r.records[ticketID]; ok && record.Outcome == OutcomeUnmatched && record.Reason == "" {
+		record.Reason = reason
+	}
+}
+
+// Finish adds the tick of every partition to the history, a stream without tickets adds one empty tick.
+func (r *Recorder) Finish() {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	durationMs := time.Since(r.start.StartedAt).Milliseconds()
+	if len(r.order) == 0 {
+		tick := r.start
+		tick.DurationMs = durationMs
+		tick.Unmatched = make([]UnmatchedTicket, 0)
+		r.history.add(tick, nil)
+		return
+	}
+
+	for _, key := range r.order {
+		tick := *r.ticks[key]
+		tick.DurationMs = durationMs
+		tick.Unmatched = make([]UnmatchedTicket, 0)
+		records := make(map[string]*TicketRecord, len(tick.tickets))
+		for _, ticketID := range tick.tickets {
+			record := r.records[ticketID]
+			records[ticketID] = record
+			if record.Outcome != OutcomeUnmatched {
+				continue
+			}
+			if record.Reason == "" {
+				record.Reason = ReasonNotMatched
+			}
+			tick.Unmatched = append(tick.Unmatched, UnmatchedTicket{TicketID: ticketID, Reason: record.Reason})
+		}
+		sort.Slice(tick.Unmatched, func(i, j int) bool {
+			return tick.Unmatched[i].TicketID < tick.Unmatched[j].TicketID
+		})
+
+		r.history.add(tick, records)
+	}
+}
+
+// partition returns the tick of the namespace and match pool, a tick without a namespace
+// belongs to the namespace of the first partition of the stream.
+func (r *Recorder) partition(namespace, matchPool string) *Tick {
+	if namespace == "" && len(r.order) > 0 {
+		namespace = r.order[0].namespace
+	}
+
+	key := poolKey{namespace: namespace, matchPool: matchPool}
+	tick, ok := r.ticks[key]
+	if !ok {
+		tick = &Tick{
+			Kind:        r.start.Kind,
+			Namespace:   namespace,
+			MatchPool:   matchPool,
+			RuleSetHash: r.start.RuleSetHash,
+			StartedAt:   r.start.StartedAt,
+		}
+		r.ticks[key] = tick
+		r.order = append(r.order, key)
+	}
+	return tick
+}
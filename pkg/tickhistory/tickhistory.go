@@ -0,0 +1,252 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+// Package tickhistory keeps the last MakeMatches and BackfillMatches ticks per match pool for debugging.
+// The history is memory-bounded: it holds a fixed amount of ticks per pool, a fixed amount of pools,
+// and a fixed amount of ticket records per tick.
+package tickhistory
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Tick kinds
+const (
+	KindMakeMatches     = "make_matches"
+	KindBackfillMatches = "backfill_matches"
+)
+
+// Ticket outcomes
+const (
+	OutcomeMatched    = "matched"
+	OutcomeBackfilled = "backfilled"
+	OutcomeUnmatched  = "unmatched"
+)
+
+// ReasonNotMatched is the unmatched reason of a ticket when the match logic didn't record one.
+const ReasonNotMatched = "not_matched"
+
+const (
+	// maxPools is the amount of match pools kept, the pool with the oldest tick is dropped first
+	maxPools = 64
+	// maxTicketsPerTick is the amount of ticket records kept per tick, the rest is only counted
+	maxTicketsPerTick = 1000
+)
+
+// Tick is a summary of one MakeMatches or BackfillMatches call.
+type Tick struct {
+	ID               uint64            `json:"id"`
+	Kind             string            `json:"kind"`
+	Namespace        string            `json:"namespace"`
+	MatchPool        string            `json:"match_pool"`
+	RuleSetHash      string            `json:"ruleset_hash"`
+	StartedAt        time.Time         `json:"started_at"`
+	DurationMs       int64             `json:"duration_ms"`
+	Tickets          int               `json:"tickets"`
+	BackfillTickets  int               `json:"backfill_tickets,omitempty"`
+	Matches          int               `json:"matches"` // matches or backfill proposals made
+	Unmatched        []UnmatchedTicket `json:"unmatched"`
+	TicketsTruncated bool              `json:"tickets_truncated,omitempty"`
+
+	tickets []string
+}
+
+// UnmatchedTicket is a ticket left unmatched after a tick.
+type UnmatchedTicket struct {
+	TicketID string `json:"ticket_id"`
+	Reason   string `json:"reason"`
+}
+
+// TicketRecord is what happened to a ticket in the last tick that saw it.
+type TicketRecord struct {
+	TicketID  string    `json:"ticket_id"`
+	Namespace string    `json:"namespace"`
+	MatchPool string    `json:"match_pool"`
+	TickID    uint64    `json:"tick_id"`
+	TickKind  string    `json:"tick_kind"`
+	SeenAt    time.Time `json:"seen_at"`
+	Outcome   string    `json:"outcome"`
+	Reason    string    `json:"reason,omitempty"`
+
+	PivotID    string `json:"pivot_id,omitempty"`    // pivot ticket of the match the ticket was matched into
+	ProposalID string `json:"proposal_id,omitempty"` // backfill proposal the ticket was added by
+}
+
+// PoolTicks are the recent ticks of a match pool, newest first.
+type PoolTicks struct {
+	Namespace string `json:"namespace"`
+	MatchPool string `json:"match_pool"`
+	Ticks     []Tick `json:"ticks"`
+}
+
+type poolKey struct {
+	namespace string
+	matchPool string
+}
+
+// ring holds the last ticks of a pool
+type ring struct {
+	ticks []Tick
+	next  int
+	count int
+	last  time.Time
+}
+
+// History holds the last ticks per match pool and the last record of every ticket in them.
+type History struct {
+	mu      sync.RWMutex
+	size    int
+	lastID  uint64
+	pools   map[poolKey]*ring
+	tickets map[string]TicketRecord
+}
+
+// New creates a history holding the last size ticks per match pool.
+// It returns nil when size is 0 or lower, a nil history records nothing.
+func New(size int) *History {
+	if size <= 0 {
+		return nil
+	}
+
+	return &History{
+		size:    size,
+		pools:   make(map[poolKey]*ring),
+		tickets: make(map[string]TicketRecord),
+	}
+}
+
+// Start starts recording a tick, the tick of every partition is added to the history when the recorder finishes.
+func (h *History) Start(kind, rulesJSON string) *Recorder {
+	if h == nil {
+		return nil
+	}
+
+	hash := sha256.Sum256([]byte(rulesJSON))
+
+	return &Recorder{
+		history: h,
+		start: Tick{
+			Kind:        kind,
+			RuleSetHash: hex.EncodeToString(hash[:]),
+			StartedAt:   time.Now(),
+		},
+		ticks:   make(map[poolKey]*Tick),
+		records: make(map[string]*TicketRecord),
+	}
+}
+
+// Pools returns the recent ticks of every pool, filtered by namespace and match pool when they are not empty.
+func (h *History) Pools(namespace, matchPool string) []PoolTicks {
+	if h == nil {
+		return nil
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	pools := make([]PoolTicks, 0, len(h.pools))
+	for key, r := range h.pools {
+		if (namespace != "" && key.namespace != namespace) || (matchPool != "" && key.matchPool != matchPool) {
+			continue
+		}
+		pools = append(pools, PoolTicks{
+			Namespace: key.namespace,
+			MatchPool: key.matchPool,
+			Ticks:     r.newestFirst(),
+		})
+	}
+	sort.Slice(pools, func(i, j int) bool {
+		if pools[i].Namespace != pools[j].Namespace {
+			return pools[i].Namespace < pools[j].Namespace
+		}
+		return pools[i].MatchPool < pools[j].MatchPool
+	})
+
+	return pools
+}
+
+// Ticket returns the record of the last tick that saw the ticket.
+func (h *History) Ticket(ticketID string) (TicketRecord, bool) {
+	if h == nil {
+		return TicketRecord{}, false
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	record, ok := h.tickets[ticketID]
+	return record, ok
+}
+
+func (h *History) add(tick Tick, records map[string]*TicketRecord) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.lastID++
+	tick.ID = h.lastID
+
+	key := poolKey{namespace: tick.Namespace, matchPool: tick.MatchPool}
+	r, ok := h.pools[key]
+	if !ok {
+		if len(h.pools) >= maxPools {
+			h.dropOldestPool()
+		}
+		r = &ring{ticks: make([]Tick, h.size)}
+		h.pools[key] = r
+	}
+
+	if r.count == h.size {
+		h.forget(r.ticks[r.next])
+	} else {
+		r.count++
+	}
+	r.ticks[r.next] = tick
+	r.next = (r.next + 1) % h.size
+	r.last = tick.StartedAt
+
+	for _, record := range records {
+		record.TickID = tick.ID
+		h.tickets[record.TicketID] = *record
+	}
+}
+
+// forget removes the ticket records that still point to an evicted tick
+func (h *History) forget(tick Tick) {
+	for _, ticketID := range tick.tickets {
+		if record, ok := h.tickets[ticketID]; ok && record.TickID == tick.ID {
+			delete(h.tickets, ticketID)
+		}
+	}
+}
+
+func (h *History) dropOldestPool() {
+	var (
+		oldestKey poolKey
+		oldest    *ring
+	)
+	for key, r := range h.pools {
+		if oldest == nil || r.last.Before(oldest.last) {
+			oldestKey, oldest = key, r
+		}
+	}
+	if oldest == nil {
+		return
+	}
+	for i := 0; i < oldest.count; i++ {
+		h.forget(oldest.ticks[i])
+	}
+	delete(h.pools, oldestKey)
+}
+
+func (r *ring) newestFirst() []Tick {
+	ticks := make([]Tick, 0, r.count)
+	for i := 1; i <= r.count; i++ {
+		ticks = append(ticks, r.ticks[(r.next-i+len(r.ticks))%len(r.ticks)])
+	}
+	return ticks
+}
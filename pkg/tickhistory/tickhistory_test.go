@@ -0,0 +1,191 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package tickhistory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func recordTick(h *History, pool string, ticketIDs ...string) {
+	recorder := h.Start(KindMakeMatches, `{"alliance":{}}`)
+	for _, ticketID := range ticketIDs {
+		recorder.Ticket("ns", pool, ticketID)
+	}
+	recorder.Finish()
+}
+
+func TestHistory_Disabled(t *testing.T) {
+	t.Parallel()
+
+	h := New(0)
+	require.Nil(t, h)
+
+	// a nil history and recorder record nothing
+	recorder := h.Start(KindMakeMatches, "{}")
+	recorder.Ticket("ns", "pool", "ticket-1")
+	recorder.Matched("ns", "pool", "ticket-1", "ticket-1")
+	RecordUnmatched(WithRecorder(context.Background(), recorder), "ticket-1", "reason")
+	recorder.Finish()
+
+	assert.Empty(t, h.Pools("", ""))
+	_, ok := h.Ticket("ticket-1")
+	assert.False(t, ok)
+}
+
+func TestHistory_RecordsOutcomes(t *testing.T) {
+	t.Parallel()
+
+	h := New(2)
+	recorder := h.Start(KindMakeMatches, "{}")
+	ctx := WithRecorder(context.Background(), recorder)
+	recorder.Ticket("ns", "pool", "ticket-1")
+	recorder.Ticket("ns", "pool", "ticket-2")
+	recorder.Ticket("ns", "pool", "ticket-3")
+	recorder.Ticket("ns", "pool", "ticket-4")
+	recorder.Matched("ns", "pool", "ticket-1", "ticket-1", "ticket-2")
+	RecordUnmatched(ctx, "ticket-3", "no_allies")
+	RecordUnmatched(ctx, "ticket-3", "timeout")
+	recorder.Finish()
+
+	pools := h.Pools("ns", "pool")
+	require.Len(t, pools, 1)
+	require.Len(t, pools[0].Ticks, 1)
+	tick := pools[0].Ticks[0]
+	assert.Equal(t, 4, tick.Tickets)
+	assert.Equal(t, 1, tick.Matches)
+	assert.Len(t, tick.RuleSetHash, 64)
+	assert.Equal(t, []UnmatchedTicket{
+		{TicketID: "ticket-3", Reason: "no_allies"},
+		{TicketID: "ticket-4", Reason: ReasonNotMatched},
+	}, tick.Unmatched)
+
+	record, ok := h.Ticket("ticket-2")
+	require.True(t, ok)
+	assert.Equal(t, OutcomeMatched, record.Outcome)
+	assert.Equal(t, "ticket-1", record.PivotID)
+	assert.Equal(t, tick.ID, record.TickID)
+
+	assert.Empty(t, h.Pools("other-ns", ""))
+}
+
+func TestHistory_RecordsATickPerPartition(t *testing.T) {
+	t.Parallel()
+
+	h := New(2)
+	recorder := h.Start(KindBackfillMatches, "{}")
+	recorder.Ticket("ns-a", "pool", "ticket-a1")
+	recorder.Ticket("ns-b", "pool", "ticket-b1")
+	recorder.Ticket("ns-a", "pool", "ticket-a2")
+	recorder.Ticket("ns-a", "other-pool", "ticket-c1")
+	recorder.BackfillTicket("", "pool")
+	recorder.Backfilled("ns-b", "pool", "proposal-1", "ticket-b1")
+	recorder.Finish()
+
+	pools := h.Pools("", "")
+	require.Len(t, pools, 3)
+
+	assert.Equal(t, "ns-a", pools[0].Namespace)
+	assert.Equal(t, "other-pool", pools[0].MatchPool)
+	assert.Equal(t, 1, pools[0].Ticks[0].Tickets)
+
+	// a backfill ticket without a namespace belongs to the namespace of the stream
+	tick := pools[1].Ticks[0]
+	assert.Equal(t, "ns-a", pools[1].Namespace)
+	assert.Equal(t, "pool", pools[1].MatchPool)
+	assert.Equal(t, 2, tick.Tickets)
+	assert.Equal(t, 1, tick.BackfillTickets)
+	assert.Equal(t, 0, tick.Matches)
+	assert.Len(t, tick.Unmatched, 2)
+
+	tick = pools[2].Ticks[0]
+	assert.Equal(t, "ns-b", pools[2].Namespace)
+	assert.Equal(t, 1, tick.Tickets)
+	assert.Equal(t, 1, tick.Matches)
+	assert.Empty(t, tick.Unmatched)
+
+	record, ok := h.Ticket("ticket-b1")
+	require.True(t, ok)
+	assert.Equal(t, OutcomeBackfilled, record.Outcome)
+	assert.Equal(t, tick.ID, record.TickID)
+	record, ok = h.Ticket("ticket-a2")
+	require.True(t, ok)
+	assert.Equal(t, pools[1].Ticks[0].ID, record.TickID)
+}
+
+func TestHistory_IsBounded(t *testing.T) {
+	t.Parallel()
+
+	h := New(2)
+	recordTick(h, "pool", "ticket-1")
+	recordTick(h, "pool", "ticket-2")
+	recordTick(h, "pool", "ticket-2", "ticket-3")
+
+	pools := h.Pools("", "")
+	require.Len(t, pools, 1)
+	require.Len(t, pools[0].Ticks, 2)
+	assert.Equal(t, uint64(3), pools[0].Ticks[0].ID, "newest tick first")
+	assert.Equal(t, uint64(2), pools[0].Ticks[1].ID)
+
+	// ticket-1 was only in the evicted tick, ticket-2 points to the latest tick that saw it
+	_, ok := h.Ticket("ticket-1")
+	assert.False(t, ok)
+	record, ok := h.Ticket("ticket-2")
+	require.True(t, ok)
+	assert.Equal(t, uint64(3), record.TickID)
+
+	for i := 0; i < maxPools+1; i++ {
+		recordTick(h, fmt.Sprintf("pool-%d", i), fmt.Sprintf("pool-ticket-%d", i))
+	}
+	assert.Len(t, h.Pools("", ""), maxPools)
+	_, ok = h.Ticket("ticket-3")
+	assert.False(t, ok, "the oldest pool is dropped with its tickets")
+
+	recorder := h.Start(KindMakeMatches, "{}")
+	for i := 0; i < maxTicketsPerTick+10; i++ {
+		recorder.Ticket("ns", "large", fmt.Sprintf("large-%d", i))
+	}
+	recorder.Finish()
+	tick := h.Pools("ns", "large")[0].Ticks[0]
+	assert.Equal(t, maxTicketsPerTick+10, tick.Tickets)
+	assert.Len(t, tick.Unmatched, maxTicketsPerTick)
+	assert.True(t, tick.TicketsTruncated)
+}
+
+func TestHistory_Handlers(t *testing.T) {
+	t.Parallel()
+
+	h := New(5)
+	recordTick(h, "pool", "ticket-1")
+	mux := http.NewServeMux()
+	h.RegisterHandlers(mux)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, Endpoint+"?match_pool=pool", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var pools []PoolTicks
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &pools))
+	require.Len(t, pools, 1)
+	assert.Equal(t, "pool", pools[0].MatchPool)
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, Endpoint+"/tickets/ticket-1", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var record TicketRecord
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &record))
+	assert.Equal(t, OutcomeUnmatched, record.Outcome)
+	assert.Equal(t, ReasonNotMatched, record.Reason)
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, Endpoint+"/tickets/unknown", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -5,6 +5,8 @@
 package utils
 
 import (
+	"encoding/json"
+	"strconv"
 	"strings"
 
 	"github.com/google/uuid"
@@ -27,6 +29,76 @@ func GetMapValueAs[T any](m map[string]interface{}, key string) (t T, ok bool) {
 	}
 }
 
+// GetMapValueByPath get a value from a nested map using a dotted path, e.g. "ranked.mmr".
+// A key that literally contains the dots takes precedence over the nested lookup.
+func GetMapValueByPath(m map[string]interface{}, path string) (interface{}, bool) {
+	if m == nil {
+		return nil, false
+	}
+	if v, ok := m[path]; ok {
+		return v, true
+	}
+	if !strings.Contains(path, ".") {
+		return nil, false
+	}
+
+	var current interface{} = m
+	for _, key := range strings.Split(path, ".") {
+		node, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if current, ok = node[key]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// ToFloat64 converts numeric values, numeric strings and bools to float64.
+// It returns false if the value cannot be interpreted as a number.
+func ToFloat64(v interface{}) (float64, bool) {
+	switch val := v.(type) {
+	case float64:
+		return val, true
+	case float32:
+		return float64(val), true
+	case int:
+		return float64(val), true
+	case int8:
+		return float64(val), true
+	case int16:
+		return float64(val), true
+	case int32:
+		return float64(val), true
+	case int64:
+		return float64(val), true
+	case uint:
+		return float64(val), true
+	case uint8:
+		return float64(val), true
+	case uint16:
+		return float64(val), true
+	case uint32:
+		return float64(val), true
+	case uint64:
+		return float64(val), true
+	case json.Number:
+		f, err := val.Float64()
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(val), 64)
+		return f, err == nil
+	case bool:
+		if val {
+			return 1, true
+		}
+		return 0, true
+	default:
+		return 0, false
+	}
+}
+
 // Contains return true if val exist in list, else return false.
 func Contains[T comparable](list []T, val T) bool {
 	for _, v := range list {